@@ -2,28 +2,92 @@ package authentication
 
 import (
 	"errors"
+	"fmt"
 
 	"github.com/gin-gonic/gin"
 )
 
+// Authentication identifies the user making the request. It returns true and stores the user using
+// CtxSetUser if it authenticated the request, false if the request does not contain its credentials
+// (so the next Authentication in the chain can be tried), and an error wrapping ErrorInvalidCredentials if
+// the credentials are present, but invalid.
 type Authentication interface {
 	Authenticate(*gin.Context) (bool, error)
 }
 
+// Challenger is implemented by the Authentications that can tell the client how to authenticate, the
+// challenge is sent in the WWW-Authenticate header of 401 Unauthorized responses
+type Challenger interface {
+	Challenge() string
+}
+
+// ErrorInvalidCredentials is returned when the credentials are present but invalid, it's rendered as
+// 401 Unauthorized
+var ErrorInvalidCredentials = errors.New("invalid credentials")
+
+// ErrorNotAuthenticated is returned when an authenticated user is required, but no credentials were provided,
+// it's rendered as 401 Unauthorized
+var ErrorNotAuthenticated = errors.New("authentication credentials were not provided")
+
 type AnonymousUserAuthentication struct{}
 
 func (a *AnonymousUserAuthentication) Authenticate(c *gin.Context) (bool, error) {
-	c.Set("user", &User{
-		Name:  "Anonymous",
-		Email: "anonymous@localhost",
-	})
+	CtxSetUser(c, AnonymousUser{})
 	return true, nil
 }
 
-func CurrentUser(c *gin.Context) (*User, error) {
-	user, exists := c.Get("user")
+// Authenticate runs the chain of authentications until one of them authenticates the request. If none does,
+// the anonymous user is used, unless a user was already stored in the context by other middleware.
+func Authenticate(c *gin.Context, authentications []Authentication) error {
+	for _, authentication := range authentications {
+		if challenger, ok := authentication.(Challenger); ok {
+			if _, hasChallenge := c.Get("authentication:challenge"); !hasChallenge {
+				c.Set("authentication:challenge", challenger.Challenge())
+			}
+		}
+	}
+	for _, authentication := range authentications {
+		authenticated, authErr := authentication.Authenticate(c)
+		if authErr != nil {
+			return authErr
+		}
+		if authenticated {
+			return nil
+		}
+	}
+	if _, hasUser := c.Get("user"); !hasUser {
+		_, authErr := (&AnonymousUserAuthentication{}).Authenticate(c)
+		return authErr
+	}
+	return nil
+}
+
+// CtxChallenge returns the challenge of the first Authentication in the chain that provides one, or an empty
+// string
+func CtxChallenge(c *gin.Context) string {
+	return c.GetString("authentication:challenge")
+}
+
+// CtxSetUser stores the authenticated user in the context
+func CtxSetUser(c *gin.Context, user User) {
+	c.Set("user", user)
+}
+
+// CurrentUser returns the user stored in the context by the authentication
+func CurrentUser(c *gin.Context) (User, error) {
+	anyVal, exists := c.Get("user")
 	if !exists {
 		return nil, errors.New("No user was authenticated, please use the correct authentication middleware")
 	}
-	return user.(*User), nil
+	user, ok := anyVal.(User)
+	if !ok || IsNilUser(user) {
+		return nil, fmt.Errorf("The user stored in the context has type %T, which does not implement User", anyVal)
+	}
+	return user, nil
+}
+
+// IsAuthenticated returns true if a user other than the anonymous one was authenticated
+func IsAuthenticated(c *gin.Context) bool {
+	user, userErr := CurrentUser(c)
+	return userErr == nil && user.IsAuthenticated()
 }
@@ -0,0 +1,157 @@
+package authentication
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// BasicUserLookupFunc returns the user with the given username and the bcrypt hash of their password,
+// or a nil user if there's no such user. Typed nils, like (*SimpleUser)(nil), are treated as no user.
+type BasicUserLookupFunc func(ctx *gin.Context, username string) (user User, passwordHash []byte, err error)
+
+// TokenLookupFunc returns the user the token belongs to, or nil if the token is not valid. Typed nils, like
+// (*SimpleUser)(nil), are treated as no user.
+type TokenLookupFunc func(ctx *gin.Context, token string) (User, error)
+
+// missingUserHash is compared with the password when the user does not exist, so the response time does not
+// reveal which usernames are taken
+var missingUserHash = sync.OnceValue(func() []byte {
+	hash, _ := bcrypt.GenerateFromPassword([]byte("missing user"), bcrypt.DefaultCost)
+	return hash
+})
+
+// BasicAuthentication authenticates the requests using HTTP Basic authentication, checking the passwords
+// against bcrypt hashes
+type BasicAuthentication struct {
+	lookup BasicUserLookupFunc
+	realm  string
+}
+
+// WithRealm sets the realm sent in the WWW-Authenticate header, `api` by default
+func (a *BasicAuthentication) WithRealm(realm string) *BasicAuthentication {
+	a.realm = realm
+	return a
+}
+
+func (a *BasicAuthentication) Authenticate(c *gin.Context) (bool, error) {
+	if !hasAuthorizationScheme(c, "Basic") {
+		return false, nil
+	}
+	username, password, ok := c.Request.BasicAuth()
+	if !ok {
+		return false, fmt.Errorf("%w: malformed basic credentials", ErrorInvalidCredentials)
+	}
+	user, passwordHash, lookupErr := a.lookup(c, username)
+	if lookupErr != nil {
+		return false, lookupErr
+	}
+	if IsNilUser(user) {
+		_ = bcrypt.CompareHashAndPassword(missingUserHash(), []byte(password))
+		return false, ErrorInvalidCredentials
+	}
+	if bcrypt.CompareHashAndPassword(passwordHash, []byte(password)) != nil {
+		return false, ErrorInvalidCredentials
+	}
+	CtxSetUser(c, user)
+	return true, nil
+}
+
+func (a *BasicAuthentication) Challenge() string {
+	return fmt.Sprintf("Basic realm=%q", a.realm)
+}
+
+// NewBasicAuthentication creates a BasicAuthentication using the lookup to find the users
+func NewBasicAuthentication(lookup BasicUserLookupFunc) *BasicAuthentication {
+	return &BasicAuthentication{
+		lookup: lookup,
+		realm:  "api",
+	}
+}
+
+// TokenAuthentication authenticates the requests using tokens passed in the Authorization header, like
+// `Authorization: Bearer <token>`
+type TokenAuthentication struct {
+	lookup  TokenLookupFunc
+	keyword string
+}
+
+// WithKeyword changes the authorization scheme, `Bearer` by default
+func (a *TokenAuthentication) WithKeyword(keyword string) *TokenAuthentication {
+	a.keyword = keyword
+	return a
+}
+
+func (a *TokenAuthentication) Authenticate(c *gin.Context) (bool, error) {
+	if !hasAuthorizationScheme(c, a.keyword) {
+		return false, nil
+	}
+	token := strings.TrimSpace(c.GetHeader("Authorization")[len(a.keyword):])
+	if token == "" {
+		return false, fmt.Errorf("%w: no token provided", ErrorInvalidCredentials)
+	}
+	return authenticateWithToken(c, a.lookup, token)
+}
+
+func (a *TokenAuthentication) Challenge() string {
+	return a.keyword
+}
+
+// NewTokenAuthentication creates a TokenAuthentication using the lookup to find the owners of the tokens
+func NewTokenAuthentication(lookup TokenLookupFunc) *TokenAuthentication {
+	return &TokenAuthentication{
+		lookup:  lookup,
+		keyword: "Bearer",
+	}
+}
+
+// APIKeyAuthentication authenticates the requests using API keys passed in a header
+type APIKeyAuthentication struct {
+	lookup TokenLookupFunc
+	header string
+}
+
+func (a *APIKeyAuthentication) Authenticate(c *gin.Context) (bool, error) {
+	key := c.GetHeader(a.header)
+	if key == "" {
+		return false, nil
+	}
+	return authenticateWithToken(c, a.lookup, key)
+}
+
+// Challenge tells the client which header to send the key in. API keys don't have a registered scheme, so
+// `APIKey` is used, for example `APIKey header="X-API-Key"`.
+func (a *APIKeyAuthentication) Challenge() string {
+	return fmt.Sprintf("APIKey header=%q", a.header)
+}
+
+// NewAPIKeyAuthentication creates an APIKeyAuthentication reading the key from the header, for example
+// `X-API-Key`, and using the lookup to find the owners of the keys
+func NewAPIKeyAuthentication(header string, lookup TokenLookupFunc) *APIKeyAuthentication {
+	return &APIKeyAuthentication{
+		lookup: lookup,
+		header: header,
+	}
+}
+
+func authenticateWithToken(c *gin.Context, lookup TokenLookupFunc, token string) (bool, error) {
+	user, lookupErr := lookup(c, token)
+	if lookupErr != nil {
+		return false, lookupErr
+	}
+	if IsNilUser(user) {
+		return false, ErrorInvalidCredentials
+	}
+	CtxSetUser(c, user)
+	return true, nil
+}
+
+// hasAuthorizationScheme checks if the Authorization header uses the scheme, ignoring its case
+func hasAuthorizationScheme(c *gin.Context, scheme string) bool {
+	header := c.GetHeader("Authorization")
+	return len(header) >= len(scheme) && strings.EqualFold(header[:len(scheme)], scheme) &&
+		(len(header) == len(scheme) || header[len(scheme)] == ' ')
+}
@@ -0,0 +1,287 @@
+// Package jwt provides a JSON Web Token authentication, verifying the tokens without any network calls using keys
+// from the configuration or a local JWKS file, and views issuing access and refresh tokens. The tokens are signed
+// and verified using github.com/golang-jwt/jwt.
+package jwt
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/glothriel/grf/pkg/authentication"
+	gojwt "github.com/golang-jwt/jwt/v5"
+	"github.com/sirupsen/logrus"
+)
+
+// ErrorInvalidToken is returned for tokens that are malformed, have an invalid signature or fail the claim
+// checks. It wraps authentication.ErrorInvalidCredentials, so it's rendered as 401 Unauthorized.
+var ErrorInvalidToken = fmt.Errorf("%w: invalid token", authentication.ErrorInvalidCredentials)
+
+// Token types stored in the `token_type` claim, so a refresh token can't be used as an access token
+const (
+	AccessToken  = "access"
+	RefreshToken = "refresh"
+)
+
+// Claims are the claims of a token
+type Claims map[string]any
+
+// Subject returns the `sub` claim
+func (c Claims) Subject() string {
+	subject, _ := c["sub"].(string)
+	return subject
+}
+
+// Strings returns a claim containing a list of strings, like `groups`
+func (c Claims) Strings(name string) []string {
+	values := []string{}
+	rawValues, _ := c[name].([]any)
+	for _, rawValue := range rawValues {
+		if value, ok := rawValue.(string); ok {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// ClaimsToUserFunc creates the user from the claims of a verified token
+type ClaimsToUserFunc func(ctx *gin.Context, claims Claims) (authentication.User, error)
+
+// UserToClaimsFunc returns the claims describing the user, added to the issued tokens
+type UserToClaimsFunc func(user authentication.User) Claims
+
+// DefaultClaimsToUser maps the `sub`, `name`, `email`, `groups` and `permissions` claims to a SimpleUser
+func DefaultClaimsToUser(_ *gin.Context, claims Claims) (authentication.User, error) {
+	name, _ := claims["name"].(string)
+	email, _ := claims["email"].(string)
+	return &authentication.SimpleUser{
+		Identifier:      claims.Subject(),
+		Name:            name,
+		Email:           email,
+		GroupNames:      claims.Strings("groups"),
+		PermissionNames: claims.Strings("permissions"),
+	}, nil
+}
+
+// DefaultUserToClaims stores the ID, groups and permissions of the user in the `sub`, `groups` and
+// `permissions` claims
+func DefaultUserToClaims(user authentication.User) Claims {
+	claims := Claims{
+		"sub":         fmt.Sprint(user.ID()),
+		"groups":      user.Groups(),
+		"permissions": user.Permissions(),
+	}
+	if simpleUser, ok := user.(*authentication.SimpleUser); ok {
+		claims["name"] = simpleUser.Name
+		claims["email"] = simpleUser.Email
+	}
+	return claims
+}
+
+// JWT verifies and issues the tokens
+type JWT struct {
+	keys            []Key
+	signingKey      *Key
+	issuer          string
+	audience        string
+	clockSkew       time.Duration
+	accessTokenTTL  time.Duration
+	refreshTokenTTL time.Duration
+	claimsToUser    ClaimsToUserFunc
+	userToClaims    UserToClaimsFunc
+	now             func() time.Time
+}
+
+// WithIssuer sets the `iss` claim of the issued tokens and requires it in the verified ones
+func (j *JWT) WithIssuer(issuer string) *JWT {
+	j.issuer = issuer
+	return j
+}
+
+// WithAudience sets the `aud` claim of the issued tokens and requires it in the verified ones
+func (j *JWT) WithAudience(audience string) *JWT {
+	j.audience = audience
+	return j
+}
+
+// WithClockSkew sets how much the clocks of the issuer and this service can differ when checking
+// `exp` and `nbf`
+func (j *JWT) WithClockSkew(clockSkew time.Duration) *JWT {
+	j.clockSkew = clockSkew
+	return j
+}
+
+// WithTTL sets how long the issued access and refresh tokens are valid, 5 minutes and 1 day by default
+func (j *JWT) WithTTL(access, refresh time.Duration) *JWT {
+	j.accessTokenTTL = access
+	j.refreshTokenTTL = refresh
+	return j
+}
+
+// WithSigningKey selects the key used to sign the issued tokens by its ID, the first key that can sign is
+// used by default
+func (j *JWT) WithSigningKey(id string) *JWT {
+	for i := range j.keys {
+		if j.keys[i].ID == id && j.keys[i].canSign() {
+			j.signingKey = &j.keys[i]
+			return j
+		}
+	}
+	logrus.Panicf("Could not find a key with ID `%s` that can sign the tokens", id)
+	return j
+}
+
+// WithClaimsToUser sets how the users are created from the claims, DefaultClaimsToUser by default
+func (j *JWT) WithClaimsToUser(claimsToUser ClaimsToUserFunc) *JWT {
+	j.claimsToUser = claimsToUser
+	return j
+}
+
+// WithUserToClaims sets which claims are added to the issued tokens, DefaultUserToClaims by default
+func (j *JWT) WithUserToClaims(userToClaims UserToClaimsFunc) *JWT {
+	j.userToClaims = userToClaims
+	return j
+}
+
+// Authentication returns a bearer token authentication accepting the access tokens
+func (j *JWT) Authentication() *authentication.TokenAuthentication {
+	return authentication.NewTokenAuthentication(func(ctx *gin.Context, token string) (authentication.User, error) {
+		claims, verifyErr := j.Verify(token, AccessToken)
+		if verifyErr != nil {
+			return nil, verifyErr
+		}
+		return j.claimsToUser(ctx, claims)
+	})
+}
+
+// Verify checks the signature and the claims of the token, returning an error wrapping ErrorInvalidToken if
+// the token is not valid
+func (j *JWT) Verify(token, tokenType string) (Claims, error) {
+	options := []gojwt.ParserOption{
+		gojwt.WithValidMethods(j.algorithms()),
+		gojwt.WithLeeway(j.clockSkew),
+		gojwt.WithTimeFunc(j.now),
+		gojwt.WithExpirationRequired(),
+	}
+	if j.issuer != "" {
+		options = append(options, gojwt.WithIssuer(j.issuer))
+	}
+	if j.audience != "" {
+		options = append(options, gojwt.WithAudience(j.audience))
+	}
+	claims := gojwt.MapClaims{}
+	if _, parseErr := gojwt.NewParser(options...).ParseWithClaims(token, claims, j.verificationKeys); parseErr != nil {
+		return nil, fmt.Errorf("%w: %s", ErrorInvalidToken, parseErrorMessage(parseErr))
+	}
+	if tokenType != "" && claims["token_type"] != tokenType {
+		return nil, fmt.Errorf("%w: expected %s token", ErrorInvalidToken, tokenType)
+	}
+	return Claims(claims), nil
+}
+
+func (j *JWT) algorithms() []string {
+	algorithms := []string{}
+	for _, key := range j.keys {
+		if !slices.Contains(algorithms, key.Algorithm) {
+			algorithms = append(algorithms, key.Algorithm)
+		}
+	}
+	return algorithms
+}
+
+// verificationKeys only returns the keys of the algorithm from the header, so for example an RSA public key
+// can't be used as an HMAC secret
+func (j *JWT) verificationKeys(token *gojwt.Token) (any, error) {
+	keyID, _ := token.Header["kid"].(string)
+	keySet := gojwt.VerificationKeySet{}
+	for _, key := range j.keys {
+		if key.Algorithm != token.Method.Alg() || (keyID != "" && key.ID != keyID) {
+			continue
+		}
+		keySet.Keys = append(keySet.Keys, key.verificationKey())
+	}
+	return keySet, nil
+}
+
+// parseErrors are checked in order, as the library may join several errors for a single token
+var parseErrors = []struct {
+	err     error
+	message string
+}{
+	{gojwt.ErrTokenMalformed, "malformed token"},
+	{gojwt.ErrTokenSignatureInvalid, "invalid signature"},
+	{gojwt.ErrTokenUnverifiable, "invalid signature"},
+	{gojwt.ErrTokenExpired, "token expired"},
+	{gojwt.ErrTokenNotValidYet, "token not valid yet"},
+	{gojwt.ErrTokenInvalidIssuer, "invalid issuer"},
+	{gojwt.ErrTokenInvalidAudience, "invalid audience"},
+	{gojwt.ErrTokenRequiredClaimMissing, "missing required claim"},
+}
+
+func parseErrorMessage(parseErr error) string {
+	for _, parseError := range parseErrors {
+		if errors.Is(parseErr, parseError.err) {
+			return parseError.message
+		}
+	}
+	return "invalid claims"
+}
+
+// Sign signs the claims, adding `iat`, `exp`, `iss`, `aud` and `token_type` claims
+func (j *JWT) Sign(claims Claims, tokenType string) (string, error) {
+	if j.signingKey == nil {
+		return "", errors.New("none of the keys can sign the tokens")
+	}
+	method := gojwt.GetSigningMethod(j.signingKey.Algorithm)
+	if method == nil {
+		return "", fmt.Errorf("unsupported algorithm `%s`", j.signingKey.Algorithm)
+	}
+	now := j.now()
+	ttl := j.accessTokenTTL
+	if tokenType == RefreshToken {
+		ttl = j.refreshTokenTTL
+	}
+	payload := gojwt.MapClaims{}
+	for k, v := range claims {
+		payload[k] = v
+	}
+	payload["iat"] = now.Unix()
+	payload["exp"] = now.Add(ttl).Unix()
+	payload["token_type"] = tokenType
+	if j.issuer != "" {
+		payload["iss"] = j.issuer
+	}
+	if j.audience != "" {
+		payload["aud"] = j.audience
+	}
+	token := gojwt.NewWithClaims(method, payload)
+	if j.signingKey.ID != "" {
+		token.Header["kid"] = j.signingKey.ID
+	}
+	return token.SignedString(j.signingKey.signingKey())
+}
+
+// New creates a JWT using the keys to verify the tokens. The first key that has the private part is used
+// to sign the issued tokens.
+func New(keys ...Key) *JWT {
+	if len(keys) == 0 {
+		logrus.Panic("JWT requires at least one key")
+	}
+	j := &JWT{
+		keys:            keys,
+		accessTokenTTL:  5 * time.Minute,
+		refreshTokenTTL: 24 * time.Hour,
+		claimsToUser:    DefaultClaimsToUser,
+		userToClaims:    DefaultUserToClaims,
+		now:             time.Now,
+	}
+	for i := range j.keys {
+		if j.keys[i].canSign() {
+			j.signingKey = &j.keys[i]
+			break
+		}
+	}
+	return j
+}
@@ -0,0 +1,231 @@
+package jwt
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/glothriel/grf/pkg/authentication"
+	"github.com/stretchr/testify/assert"
+)
+
+var testNow = time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+func withClock(j *JWT, now time.Time) *JWT {
+	j.now = func() time.Time { return now }
+	return j
+}
+
+func testKeys(t *testing.T) (Key, Key, Key) {
+	rsaKey, rsaErr := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, rsaErr)
+	edPublic, edPrivate, edErr := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, edErr)
+	return HMACKey("hmac", []byte("secret")),
+		RSAKey("rsa", &rsaKey.PublicKey, rsaKey),
+		Ed25519Key("ed", edPublic, edPrivate)
+}
+
+func TestSignAndVerify(t *testing.T) {
+	hmacKey, rsaKey, edKey := testKeys(t)
+	for _, key := range []Key{hmacKey, rsaKey, edKey} {
+		t.Run(key.Algorithm, func(t *testing.T) {
+			// given
+			j := withClock(New(key), testNow)
+
+			// when
+			token, signErr := j.Sign(Claims{"sub": "42"}, AccessToken)
+			claims, verifyErr := j.Verify(token, AccessToken)
+
+			// then
+			assert.NoError(t, signErr)
+			assert.NoError(t, verifyErr)
+			assert.Equal(t, "42", claims.Subject())
+			assert.Equal(t, float64(testNow.Add(5*time.Minute).Unix()), claims["exp"])
+		})
+	}
+}
+
+func TestVerifyWithPublicKeyOnly(t *testing.T) {
+	// given
+	_, rsaKey, edKey := testKeys(t)
+	issuer := withClock(New(rsaKey, edKey).WithSigningKey("ed"), testNow)
+	verifier := withClock(New(
+		RSAKey("rsa", rsaKey.PublicKey.(*rsa.PublicKey), nil),
+		Ed25519Key("ed", edKey.PublicKey.(ed25519.PublicKey), nil),
+	), testNow)
+
+	// when
+	token, signErr := issuer.Sign(Claims{"sub": "42"}, AccessToken)
+	_, verifyErr := verifier.Verify(token, AccessToken)
+	_, verifierSignErr := verifier.Sign(Claims{"sub": "42"}, AccessToken)
+
+	// then
+	assert.NoError(t, signErr)
+	assert.NoError(t, verifyErr)
+	assert.Error(t, verifierSignErr)
+}
+
+func TestVerifyRejectsInvalidTokens(t *testing.T) {
+	hmacKey, rsaKey, _ := testKeys(t)
+	issuer := withClock(New(hmacKey).WithIssuer("grf").WithAudience("api"), testNow)
+	sign := func(claims Claims, tokenType string) string {
+		token, signErr := issuer.Sign(claims, tokenType)
+		assert.NoError(t, signErr)
+		return token
+	}
+	valid := sign(Claims{"sub": "42"}, AccessToken)
+	segments := strings.Split(valid, ".")
+	unsignedHeader := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"none"}`))
+	tamperedPayload := base64.RawURLEncoding.EncodeToString([]byte(`{"sub":"1","exp":9999999999}`))
+
+	tests := []struct {
+		name     string
+		verifier *JWT
+		token    string
+		wantErr  string
+	}{
+		{"malformed", issuer, "abc", "invalid credentials: invalid token: malformed token"},
+		{"tampered payload", issuer, segments[0] + "." + tamperedPayload + "." + segments[2], "invalid credentials: invalid token: invalid signature"},
+		{"alg none", issuer, unsignedHeader + "." + segments[1] + ".", "invalid credentials: invalid token: invalid signature"},
+		{"wrong key", withClock(New(HMACKey("hmac", []byte("other"))), testNow), valid, "invalid credentials: invalid token: invalid signature"},
+		{"wrong algorithm", withClock(New(rsaKey), testNow), valid, "invalid credentials: invalid token: invalid signature"},
+		{"expired", withClock(New(hmacKey), testNow.Add(6*time.Minute)), valid, "invalid credentials: invalid token: token expired"},
+		{"wrong issuer", withClock(New(hmacKey).WithIssuer("other"), testNow), valid, "invalid credentials: invalid token: invalid issuer"},
+		{"wrong audience", withClock(New(hmacKey).WithAudience("other"), testNow), valid, "invalid credentials: invalid token: invalid audience"},
+		{"refresh used as access", issuer, sign(Claims{"sub": "42"}, RefreshToken), "invalid credentials: invalid token: expected access token"},
+		{"not valid yet", issuer, sign(Claims{"nbf": testNow.Add(time.Minute).Unix()}, AccessToken), "invalid credentials: invalid token: token not valid yet"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// when
+			_, verifyErr := tt.verifier.Verify(tt.token, AccessToken)
+
+			// then
+			assert.EqualError(t, verifyErr, tt.wantErr)
+			assert.ErrorIs(t, verifyErr, authentication.ErrorInvalidCredentials)
+		})
+	}
+}
+
+func TestVerifyAllowsClockSkew(t *testing.T) {
+	// given
+	hmacKey, _, _ := testKeys(t)
+	token, _ := withClock(New(hmacKey), testNow).Sign(Claims{"nbf": testNow.Add(time.Minute).Unix()}, AccessToken)
+
+	// when
+	_, beforeNbfErr := withClock(New(hmacKey).WithClockSkew(2*time.Minute), testNow).Verify(token, AccessToken)
+	_, afterExpErr := withClock(New(hmacKey).WithClockSkew(2*time.Minute), testNow.Add(6*time.Minute)).Verify(token, AccessToken)
+	_, tooLateErr := withClock(New(hmacKey).WithClockSkew(2*time.Minute), testNow.Add(8*time.Minute)).Verify(token, AccessToken)
+
+	// then
+	assert.NoError(t, beforeNbfErr)
+	assert.NoError(t, afterExpErr)
+	assert.Error(t, tooLateErr)
+}
+
+func TestVerifyAcceptsAudienceList(t *testing.T) {
+	// given
+	hmacKey, _, _ := testKeys(t)
+	token, _ := withClock(New(hmacKey), testNow).Sign(Claims{"aud": []string{"web", "api"}}, AccessToken)
+
+	// when
+	_, verifyErr := withClock(New(hmacKey).WithAudience("api"), testNow).Verify(token, AccessToken)
+
+	// then
+	assert.NoError(t, verifyErr)
+}
+
+func encodeBigInt(i *big.Int) string {
+	return base64.RawURLEncoding.EncodeToString(i.Bytes())
+}
+
+func TestLoadJWKS(t *testing.T) {
+	// given
+	hmacKey, rsaKey, edKey := testKeys(t)
+	rsaPrivate := rsaKey.PrivateKey.(*rsa.PrivateKey)
+	edPrivate := edKey.PrivateKey.(ed25519.PrivateKey)
+	jwks, _ := json.Marshal(map[string]any{"keys": []map[string]string{
+		{"kty": "oct", "kid": "hmac", "k": base64.RawURLEncoding.EncodeToString(hmacKey.Secret)},
+		{
+			"kty": "RSA", "kid": "rsa", "n": encodeBigInt(rsaPrivate.N), "e": encodeBigInt(big.NewInt(int64(rsaPrivate.E))),
+		},
+		{
+			"kty": "RSA", "kid": "rsa-private", "n": encodeBigInt(rsaPrivate.N), "e": encodeBigInt(big.NewInt(int64(rsaPrivate.E))),
+			"d": encodeBigInt(rsaPrivate.D), "p": encodeBigInt(rsaPrivate.Primes[0]), "q": encodeBigInt(rsaPrivate.Primes[1]),
+		},
+		{
+			"kty": "OKP", "crv": "Ed25519", "kid": "ed",
+			"x": base64.RawURLEncoding.EncodeToString(edKey.PublicKey.(ed25519.PublicKey)),
+			"d": base64.RawURLEncoding.EncodeToString(edPrivate.Seed()),
+		},
+	}})
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	assert.NoError(t, os.WriteFile(path, jwks, 0o600))
+
+	// when
+	keys, loadErr := LoadJWKS(path)
+
+	// then
+	assert.NoError(t, loadErr)
+	assert.Len(t, keys, 4)
+	for _, keyID := range []string{"hmac", "rsa-private", "ed"} {
+		signed, signErr := withClock(New(keys...).WithSigningKey(keyID), testNow).Sign(Claims{"sub": "42"}, AccessToken)
+		assert.NoError(t, signErr)
+		_, verifyErr := withClock(New(keys...), testNow).Verify(signed, AccessToken)
+		assert.NoError(t, verifyErr, keyID)
+	}
+	rsaSigned, _ := withClock(New(rsaKey), testNow).Sign(Claims{"sub": "42"}, AccessToken)
+	_, publicVerifyErr := withClock(New(keys[1]), testNow).Verify(rsaSigned, AccessToken)
+	assert.NoError(t, publicVerifyErr)
+}
+
+func TestParseJWKSRejectsUnsupportedKeys(t *testing.T) {
+	for _, jwks := range []string{
+		`not json`,
+		`{"keys":[{"kty":"EC","crv":"P-256"}]}`,
+		`{"keys":[{"kty":"OKP","crv":"X25519","x":"AA"}]}`,
+		`{"keys":[{"kty":"oct","k":""}]}`,
+		`{"keys":[{"kty":"RSA","n":"!!","e":"AQAB"}]}`,
+	} {
+		_, parseErr := ParseJWKS([]byte(jwks))
+		assert.Error(t, parseErr, jwks)
+	}
+}
+
+func TestAuthenticationMapsClaimsToUser(t *testing.T) {
+	// given
+	hmacKey, _, _ := testKeys(t)
+	j := withClock(New(hmacKey), testNow)
+	token, _ := j.Sign(DefaultUserToClaims(&authentication.SimpleUser{
+		Identifier: 42, Name: "alice", GroupNames: []string{"editors"}, PermissionNames: []string{"view_product"},
+	}), AccessToken)
+	ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+	ctx.Request = httptest.NewRequest("GET", "/", nil)
+	ctx.Request.Header.Set("Authorization", "Bearer "+token)
+
+	// when
+	authErr := authentication.Authenticate(ctx, []authentication.Authentication{j.Authentication()})
+	user, userErr := authentication.CurrentUser(ctx)
+
+	// then
+	assert.NoError(t, authErr)
+	assert.NoError(t, userErr)
+	assert.Equal(t, &authentication.SimpleUser{
+		Identifier:      "42",
+		Name:            "alice",
+		Email:           "",
+		GroupNames:      []string{"editors"},
+		PermissionNames: []string{"view_product"},
+	}, user)
+}
@@ -0,0 +1,185 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+
+	gojwt "github.com/golang-jwt/jwt/v5"
+)
+
+// Supported signing algorithms
+const (
+	HS256 = "HS256"
+	RS256 = "RS256"
+	EdDSA = "EdDSA"
+)
+
+// Key is a key used to sign or verify the tokens. Keys without the private part (Secret or PrivateKey) can only
+// verify the tokens.
+type Key struct {
+	// ID is matched with the `kid` header of the tokens, it can be empty if there's only one key
+	ID         string
+	Algorithm  string
+	Secret     []byte
+	PublicKey  crypto.PublicKey
+	PrivateKey crypto.Signer
+}
+
+func (k Key) canSign() bool {
+	return len(k.Secret) > 0 || k.PrivateKey != nil
+}
+
+func (k Key) signingKey() any {
+	if k.Algorithm == HS256 {
+		return k.Secret
+	}
+	return k.PrivateKey
+}
+
+func (k Key) verificationKey() gojwt.VerificationKey {
+	if k.Algorithm == HS256 {
+		return k.Secret
+	}
+	return k.PublicKey
+}
+
+// HMACKey creates a HS256 key, which both signs and verifies the tokens
+func HMACKey(id string, secret []byte) Key {
+	return Key{ID: id, Algorithm: HS256, Secret: secret}
+}
+
+// RSAKey creates a RS256 key, privateKey can be nil if the key is used only to verify the tokens
+func RSAKey(id string, publicKey *rsa.PublicKey, privateKey *rsa.PrivateKey) Key {
+	key := Key{ID: id, Algorithm: RS256, PublicKey: publicKey}
+	if privateKey != nil {
+		key.PrivateKey = privateKey
+	}
+	return key
+}
+
+// Ed25519Key creates a EdDSA key, privateKey can be nil if the key is used only to verify the tokens
+func Ed25519Key(id string, publicKey ed25519.PublicKey, privateKey ed25519.PrivateKey) Key {
+	key := Key{ID: id, Algorithm: EdDSA, PublicKey: publicKey}
+	if privateKey != nil {
+		key.PrivateKey = privateKey
+	}
+	return key
+}
+
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Alg string `json:"alg"`
+	Crv string `json:"crv"`
+	// oct
+	K string `json:"k"`
+	// RSA
+	N string `json:"n"`
+	E string `json:"e"`
+	D string `json:"d"`
+	P string `json:"p"`
+	Q string `json:"q"`
+	// OKP
+	X string `json:"x"`
+}
+
+// LoadJWKS reads the keys from a local JSON Web Key Set file
+func LoadJWKS(path string) ([]Key, error) {
+	data, readErr := os.ReadFile(path)
+	if readErr != nil {
+		return nil, readErr
+	}
+	return ParseJWKS(data)
+}
+
+// ParseJWKS parses a JSON Web Key Set. Supported are `oct` (HS256), `RSA` (RS256) and `OKP` with the Ed25519
+// curve (EdDSA) keys, the private parts are loaded if present.
+func ParseJWKS(data []byte) ([]Key, error) {
+	var set struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if unmarshalErr := json.Unmarshal(data, &set); unmarshalErr != nil {
+		return nil, fmt.Errorf("invalid JWKS: %w", unmarshalErr)
+	}
+	keys := []Key{}
+	for i, jwk := range set.Keys {
+		key, keyErr := jwk.toKey()
+		if keyErr != nil {
+			return nil, fmt.Errorf("invalid key %d in JWKS: %w", i, keyErr)
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+func (jwk jsonWebKey) toKey() (Key, error) {
+	switch jwk.Kty {
+	case "oct":
+		secret, decodeErr := decodeSegment(jwk.K)
+		if decodeErr != nil || len(secret) == 0 {
+			return Key{}, fmt.Errorf("invalid `k`")
+		}
+		return HMACKey(jwk.Kid, secret), nil
+	case "RSA":
+		return jwk.toRSAKey()
+	case "OKP":
+		if jwk.Crv != "Ed25519" {
+			return Key{}, fmt.Errorf("unsupported curve `%s`", jwk.Crv)
+		}
+		publicKey, decodeErr := decodeSegment(jwk.X)
+		if decodeErr != nil || len(publicKey) != ed25519.PublicKeySize {
+			return Key{}, fmt.Errorf("invalid `x`")
+		}
+		if jwk.D == "" {
+			return Ed25519Key(jwk.Kid, publicKey, nil), nil
+		}
+		seed, seedErr := decodeSegment(jwk.D)
+		if seedErr != nil || len(seed) != ed25519.SeedSize {
+			return Key{}, fmt.Errorf("invalid `d`")
+		}
+		return Ed25519Key(jwk.Kid, publicKey, ed25519.NewKeyFromSeed(seed)), nil
+	}
+	return Key{}, fmt.Errorf("unsupported key type `%s`", jwk.Kty)
+}
+
+func (jwk jsonWebKey) toRSAKey() (Key, error) {
+	n, nErr := decodeBigInt(jwk.N)
+	e, eErr := decodeBigInt(jwk.E)
+	if nErr != nil || eErr != nil || !e.IsInt64() {
+		return Key{}, fmt.Errorf("invalid `n` or `e`")
+	}
+	publicKey := &rsa.PublicKey{N: n, E: int(e.Int64())}
+	if jwk.D == "" {
+		return RSAKey(jwk.Kid, publicKey, nil), nil
+	}
+	d, dErr := decodeBigInt(jwk.D)
+	p, pErr := decodeBigInt(jwk.P)
+	q, qErr := decodeBigInt(jwk.Q)
+	if dErr != nil || pErr != nil || qErr != nil {
+		return Key{}, fmt.Errorf("invalid private key")
+	}
+	privateKey := &rsa.PrivateKey{PublicKey: *publicKey, D: d, Primes: []*big.Int{p, q}}
+	if validateErr := privateKey.Validate(); validateErr != nil {
+		return Key{}, fmt.Errorf("invalid private key: %w", validateErr)
+	}
+	privateKey.Precompute()
+	return RSAKey(jwk.Kid, publicKey, privateKey), nil
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	data, decodeErr := decodeSegment(s)
+	if decodeErr != nil || len(data) == 0 {
+		return nil, fmt.Errorf("invalid number")
+	}
+	return new(big.Int).SetBytes(data), nil
+}
+
+func decodeSegment(s string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(s)
+}
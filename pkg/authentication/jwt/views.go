@@ -0,0 +1,133 @@
+package jwt
+
+import (
+	"fmt"
+	"net/http"
+	"path"
+
+	"github.com/gin-gonic/gin"
+	"github.com/glothriel/grf/pkg/authentication"
+	"github.com/glothriel/grf/pkg/serializers"
+	"github.com/glothriel/grf/pkg/views"
+	"github.com/sirupsen/logrus"
+)
+
+// CredentialCheckerFunc returns the user with the given credentials, or nil if the credentials are invalid
+type CredentialCheckerFunc func(ctx *gin.Context, username, password string) (authentication.User, error)
+
+// UserLoaderFunc returns the current user identified by the claims of a refresh token, or nil if the user can't
+// get new tokens anymore, for example because it was deleted or deactivated
+type UserLoaderFunc func(ctx *gin.Context, claims Claims) (authentication.User, error)
+
+// TokenPair is the response of the token views, the refresh view only returns the access token
+type TokenPair struct {
+	Access  string `json:"access"`
+	Refresh string `json:"refresh,omitempty"`
+}
+
+// TokenHandler issues a token pair in exchange for `{"username": "...", "password": "..."}`
+func (j *JWT) TokenHandler(checkCredentials CredentialCheckerFunc) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var body struct {
+			Username string `json:"username"`
+			Password string `json:"password"`
+		}
+		if parseErr := ctx.ShouldBindJSON(&body); parseErr != nil {
+			views.WriteError(ctx, parseErr)
+			return
+		}
+		if missingErr := requireFields(map[string]string{"username": body.Username, "password": body.Password}); missingErr != nil {
+			views.WriteError(ctx, missingErr)
+			return
+		}
+		user, checkErr := checkCredentials(ctx, body.Username, body.Password)
+		if checkErr != nil {
+			views.WriteError(ctx, checkErr)
+			return
+		}
+		if authentication.IsNilUser(user) {
+			views.WriteError(ctx, authentication.ErrorInvalidCredentials)
+			return
+		}
+		j.writeTokenPair(ctx, user)
+	}
+}
+
+// RefreshHandler issues a new access token in exchange for `{"refresh": "..."}`. The user is loaded again using
+// loadUser, so the changes of its groups or permissions are applied and removed users can't refresh the tokens.
+// The refresh token itself isn't renewed, so the user has to log in again after it expires.
+func (j *JWT) RefreshHandler(loadUser UserLoaderFunc) gin.HandlerFunc {
+	if loadUser == nil {
+		logrus.Panic("RefreshHandler requires a user loader")
+	}
+	return func(ctx *gin.Context) {
+		var body struct {
+			Refresh string `json:"refresh"`
+		}
+		if parseErr := ctx.ShouldBindJSON(&body); parseErr != nil {
+			views.WriteError(ctx, parseErr)
+			return
+		}
+		if missingErr := requireFields(map[string]string{"refresh": body.Refresh}); missingErr != nil {
+			views.WriteError(ctx, missingErr)
+			return
+		}
+		claims, verifyErr := j.Verify(body.Refresh, RefreshToken)
+		if verifyErr != nil {
+			views.WriteError(ctx, verifyErr)
+			return
+		}
+		user, loadErr := loadUser(ctx, claims)
+		if loadErr != nil {
+			views.WriteError(ctx, loadErr)
+			return
+		}
+		if authentication.IsNilUser(user) {
+			views.WriteError(ctx, ErrorInvalidToken)
+			return
+		}
+		access, accessErr := j.Sign(j.userToClaims(user), AccessToken)
+		if accessErr != nil {
+			views.WriteError(ctx, accessErr)
+			return
+		}
+		ctx.JSON(http.StatusOK, TokenPair{Access: access})
+	}
+}
+
+// RegisterTokenViews registers the TokenHandler under the path and the RefreshHandler under `<path>/refresh`,
+// for example `/token` and `/token/refresh`
+func (j *JWT) RegisterTokenViews(
+	r gin.IRouter, tokenPath string, checkCredentials CredentialCheckerFunc, loadUser UserLoaderFunc,
+) {
+	r.POST(tokenPath, j.TokenHandler(checkCredentials))
+	r.POST(path.Join(tokenPath, "refresh"), j.RefreshHandler(loadUser))
+}
+
+func (j *JWT) writeTokenPair(ctx *gin.Context, user authentication.User) {
+	claims := j.userToClaims(user)
+	access, accessErr := j.Sign(claims, AccessToken)
+	if accessErr != nil {
+		views.WriteError(ctx, accessErr)
+		return
+	}
+	refresh, refreshErr := j.Sign(claims, RefreshToken)
+	if refreshErr != nil {
+		views.WriteError(ctx, refreshErr)
+		return
+	}
+	ctx.JSON(http.StatusOK, TokenPair{Access: access, Refresh: refresh})
+}
+
+func requireFields(fields map[string]string) error {
+	fieldErrors := map[string][]string{}
+	for name, value := range fields {
+		if value == "" {
+			fieldErrors[name] = []string{fmt.Sprintf("Field `%s` is required", name)}
+		}
+	}
+	if len(fieldErrors) > 0 {
+		return &serializers.ValidationError{FieldErrors: fieldErrors}
+	}
+	return nil
+}
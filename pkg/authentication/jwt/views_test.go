@@ -0,0 +1,151 @@
+package jwt
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/glothriel/grf/pkg/authentication"
+	"github.com/glothriel/grf/pkg/queries"
+	"github.com/glothriel/grf/pkg/views"
+	"github.com/stretchr/testify/assert"
+)
+
+func tokenRouter() (*gin.Engine, *JWT, map[string]*authentication.SimpleUser) {
+	j := withClock(New(HMACKey("", []byte("secret"))), testNow)
+	users := map[string]*authentication.SimpleUser{"1": {Identifier: "1", Name: "alice"}}
+	router := gin.New()
+	j.RegisterTokenViews(router, "/token", func(_ *gin.Context, username, password string) (authentication.User, error) {
+		if username == "alice" && password == "secret" {
+			return users["1"], nil
+		}
+		return nil, nil
+	}, func(_ *gin.Context, claims Claims) (authentication.User, error) {
+		if user, ok := users[claims.Subject()]; ok {
+			return user, nil
+		}
+		return nil, nil
+	})
+	return router, j, users
+}
+
+func postJSON(router *gin.Engine, path, body string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("POST", path, bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestTokenViews(t *testing.T) {
+	// given
+	router, j, _ := tokenRouter()
+
+	// when
+	tokenResponse := postJSON(router, "/token", `{"username": "alice", "password": "secret"}`)
+	var pair TokenPair
+	assert.NoError(t, json.Unmarshal(tokenResponse.Body.Bytes(), &pair))
+	refreshResponse := postJSON(router, "/token/refresh", `{"refresh": "`+pair.Refresh+`"}`)
+	accessAsRefreshResponse := postJSON(router, "/token/refresh", `{"refresh": "`+pair.Access+`"}`)
+
+	// then
+	assert.Equal(t, http.StatusOK, tokenResponse.Code)
+	claims, verifyErr := j.Verify(pair.Access, AccessToken)
+	assert.NoError(t, verifyErr)
+	assert.Equal(t, "1", claims.Subject())
+	assert.Equal(t, "alice", claims["name"])
+	assert.Equal(t, http.StatusOK, refreshResponse.Code)
+	assert.Contains(t, refreshResponse.Body.String(), `"access"`)
+	assert.NotContains(t, refreshResponse.Body.String(), `"refresh"`)
+	assert.Equal(t, http.StatusUnauthorized, accessAsRefreshResponse.Code)
+}
+
+func TestRefreshViewLoadsTheUserAgain(t *testing.T) {
+	// given
+	router, j, users := tokenRouter()
+	tokenResponse := postJSON(router, "/token", `{"username": "alice", "password": "secret"}`)
+	var pair TokenPair
+	assert.NoError(t, json.Unmarshal(tokenResponse.Body.Bytes(), &pair))
+
+	// when
+	users["1"].GroupNames = []string{"editors"}
+	refreshResponse := postJSON(router, "/token/refresh", `{"refresh": "`+pair.Refresh+`"}`)
+	delete(users, "1")
+	removedUserResponse := postJSON(router, "/token/refresh", `{"refresh": "`+pair.Refresh+`"}`)
+
+	// then
+	assert.Equal(t, http.StatusOK, refreshResponse.Code)
+	var refreshed TokenPair
+	assert.NoError(t, json.Unmarshal(refreshResponse.Body.Bytes(), &refreshed))
+	claims, verifyErr := j.Verify(refreshed.Access, AccessToken)
+	assert.NoError(t, verifyErr)
+	assert.Equal(t, []string{"editors"}, claims.Strings("groups"))
+	assert.Equal(t, http.StatusUnauthorized, removedUserResponse.Code)
+}
+
+func TestTokenViewErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		wantCode int
+		wantBody string
+	}{
+		{"invalid credentials", `{"username": "alice", "password": "wrong"}`, http.StatusUnauthorized, `{"message":"invalid credentials"}`},
+		{"missing password", `{"username": "alice"}`, http.StatusBadRequest, `{"errors":{"password":["Field ` + "`password`" + ` is required"]}}`},
+		{"malformed body", `{`, http.StatusBadRequest, ``},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// given
+			router, _, _ := tokenRouter()
+
+			// when
+			w := postJSON(router, "/token", tt.body)
+
+			// then
+			assert.Equal(t, tt.wantCode, w.Code)
+			if tt.wantBody != "" {
+				assert.JSONEq(t, tt.wantBody, w.Body.String())
+			}
+		})
+	}
+}
+
+type ownedOrder struct {
+	ID      uint   `json:"id"`
+	Name    string `json:"name"`
+	OwnerID uint   `json:"owner_id"`
+}
+
+func TestJWTUsersOwnInstancesWithIntegerOwnerField(t *testing.T) {
+	// given
+	j := withClock(New(HMACKey("", []byte("secret"))), testNow)
+	router := gin.New()
+	views.NewModelViewSet[ownedOrder]("/orders", queries.InMemory(
+		ownedOrder{Name: "first", OwnerID: 7},
+		ownedOrder{Name: "second", OwnerID: 8},
+	).WithOwnerField("owner_id")).WithAuthentication(j.Authentication()).Register(router)
+	token, signErr := j.Sign(DefaultUserToClaims(&authentication.SimpleUser{Identifier: uint(7)}), AccessToken)
+	assert.NoError(t, signErr)
+	request := func(method, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(method, "/orders", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	// when
+	listResponse := request("GET", "")
+	createResponse := request("POST", `{"name": "third"}`)
+
+	// then
+	assert.Equal(t, http.StatusOK, listResponse.Code)
+	assert.JSONEq(t, `[{"id": 1, "name": "first", "owner_id": 7}]`, listResponse.Body.String())
+	assert.Equal(t, http.StatusCreated, createResponse.Code)
+	assert.JSONEq(t, `{"id": 3, "name": "third", "owner_id": 7}`, createResponse.Body.String())
+}
@@ -1,6 +1,99 @@
 package authentication
 
-type User struct {
-	Name  string
-	Email string
+import (
+	"reflect"
+	"slices"
+)
+
+// User is the user making the request. Applications can use their own user types, as long as they
+// implement this interface, SimpleUser is provided for convenience.
+type User interface {
+	// ID identifies the user, it's used for example to check the ownership of the model instances
+	ID() any
+	// IsAuthenticated is false for the anonymous user
+	IsAuthenticated() bool
+	// Groups returns the names of the groups the user belongs to
+	Groups() []string
+	// Permissions returns the codenames of the permissions the user has, not including the ones
+	// granted through the groups, as resolving them is up to the application
+	Permissions() []string
+}
+
+// SimpleUser is a User with the ID, groups and permissions known upfront, for example loaded from
+// the database by the authentication backend or taken from the claims of a token
+type SimpleUser struct {
+	Identifier      any
+	Name            string
+	Email           string
+	GroupNames      []string
+	PermissionNames []string
+}
+
+func (u *SimpleUser) ID() any {
+	if u == nil {
+		return nil
+	}
+	return u.Identifier
+}
+
+// IsAuthenticated is false for a nil *SimpleUser, so a lookup returning one never authenticates the request
+func (u *SimpleUser) IsAuthenticated() bool {
+	return u != nil
+}
+
+func (u *SimpleUser) Groups() []string {
+	if u == nil {
+		return []string{}
+	}
+	return u.GroupNames
+}
+
+func (u *SimpleUser) Permissions() []string {
+	if u == nil {
+		return []string{}
+	}
+	return u.PermissionNames
+}
+
+// AnonymousUser is used when no authentication recognized the request
+type AnonymousUser struct{}
+
+func (u AnonymousUser) ID() any {
+	return nil
+}
+
+func (u AnonymousUser) IsAuthenticated() bool {
+	return false
+}
+
+func (u AnonymousUser) Groups() []string {
+	return []string{}
+}
+
+func (u AnonymousUser) Permissions() []string {
+	return []string{}
+}
+
+// IsNilUser checks if there's no user, also when the lookup returned a typed nil, like (*SimpleUser)(nil),
+// which is not equal to nil when stored in the User interface
+func IsNilUser(user User) bool {
+	if user == nil {
+		return true
+	}
+	v := reflect.ValueOf(user)
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Map, reflect.Slice, reflect.Interface, reflect.Func, reflect.Chan:
+		return v.IsNil()
+	}
+	return false
+}
+
+// InGroup checks if the user belongs to the group
+func InGroup(user User, group string) bool {
+	return !IsNilUser(user) && slices.Contains(user.Groups(), group)
+}
+
+// HasPermission checks if the user has the permission with the given codename
+func HasPermission(user User, codename string) bool {
+	return !IsNilUser(user) && slices.Contains(user.Permissions(), codename)
 }
@@ -0,0 +1,78 @@
+package authentication
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// staffMember is an application defined user type
+type staffMember struct {
+	email string
+	admin bool
+}
+
+func (s staffMember) ID() any               { return s.email }
+func (s staffMember) IsAuthenticated() bool { return true }
+func (s staffMember) Permissions() []string { return []string{} }
+func (s staffMember) Groups() []string {
+	if s.admin {
+		return []string{"admins"}
+	}
+	return []string{}
+}
+
+func TestCurrentUser(t *testing.T) {
+	tests := []struct {
+		name              string
+		user              any
+		wantErr           bool
+		wantAuthenticated bool
+	}{
+		{"no user", nil, true, false},
+		{"not a user", "alice", true, false},
+		{"anonymous", AnonymousUser{}, false, false},
+		{"simple user", &SimpleUser{Identifier: 1}, false, true},
+		{"custom user", staffMember{email: "alice@example.com"}, false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// given
+			ctx := ctxWithHeaders(map[string]string{})
+			if tt.user != nil {
+				ctx.Set("user", tt.user)
+			}
+
+			// when
+			user, userErr := CurrentUser(ctx)
+
+			// then
+			if tt.wantErr {
+				assert.Error(t, userErr)
+			} else {
+				assert.NoError(t, userErr)
+				assert.Equal(t, tt.user, user)
+			}
+			assert.Equal(t, tt.wantAuthenticated, IsAuthenticated(ctx))
+		})
+	}
+}
+
+func TestGroupsAndPermissions(t *testing.T) {
+	// given
+	user := &SimpleUser{
+		Identifier:      1,
+		GroupNames:      []string{"editors"},
+		PermissionNames: []string{"change_article"},
+	}
+
+	// then
+	assert.True(t, InGroup(user, "editors"))
+	assert.False(t, InGroup(user, "admins"))
+	assert.True(t, InGroup(staffMember{admin: true}, "admins"))
+	assert.False(t, InGroup(AnonymousUser{}, "editors"))
+	assert.False(t, InGroup(nil, "editors"))
+	assert.True(t, HasPermission(user, "change_article"))
+	assert.False(t, HasPermission(user, "delete_article"))
+	assert.False(t, HasPermission(AnonymousUser{}, "change_article"))
+}
@@ -23,6 +23,19 @@ func FieldTypes[Model any]() map[string]string {
 	return ret
 }
 
+// FieldTags returns the parsed `grf` tags of the model fields, keyed by their JSON names
+func FieldTags[Model any]() map[string]map[string]string {
+	ret := make(map[string]map[string]string)
+
+	var m Model
+	for _, field := range reflect.VisibleFields(reflect.TypeOf(m)) {
+		if !field.Anonymous {
+			ret[field.Tag.Get("json")] = models.ParseTag(field)
+		}
+	}
+	return ret
+}
+
 // Prints a summary with the fields of the model obtained using reflection
 func FieldNames[Model any]() map[string]string {
 	ret := make(map[string]string)
@@ -50,7 +63,9 @@ func Fields[Model any]() []string {
 }
 
 type fieldSettings struct {
+	// itsType is the type of the field, or the type it points to for pointer fields
 	itsType                   reflect.Type
+	isPointer                 bool
 	isEncodingTextMarshaler   bool
 	isEncodingTextUnmarshaler bool
 
@@ -68,7 +83,12 @@ func getFieldSettings[Model any](fieldName string) *fieldSettings {
 		jsonTag := field.Tag.Get("json")
 		if jsonTag == fieldName {
 			var theTypeAsAny any
-			reflectedInstance := reflect.New(reflect.TypeOf(reflect.ValueOf(entity).FieldByName(field.Name).Interface())).Elem()
+			fieldType := field.Type
+			isPointer := fieldType.Kind() == reflect.Pointer
+			if isPointer {
+				fieldType = fieldType.Elem()
+			}
+			reflectedInstance := reflect.New(fieldType).Elem()
 
 			settingsFromTag := models.ParseTag(field)
 			_, fieldMarkedAsRelation := settingsFromTag[models.TagIsRelation]
@@ -86,9 +106,8 @@ func getFieldSettings[Model any](fieldName string) *fieldSettings {
 			_, isSQLNull32 := theTypeAsAny.(*sql.NullInt32)
 
 			settings = &fieldSettings{
-				itsType: reflect.TypeOf(
-					reflectedInstance.Interface(),
-				),
+				itsType:                   fieldType,
+				isPointer:                 isPointer,
 				isEncodingTextMarshaler:   isEncodingTextMarshaler,
 				isEncodingTextUnmarshaler: isEncodingTextUnmarshaler,
 				isGRFRepresentable:        isGRFRepresentable,
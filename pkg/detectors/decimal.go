@@ -0,0 +1,153 @@
+package detectors
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+
+	"github.com/glothriel/grf/pkg/fields"
+	"github.com/glothriel/grf/pkg/models"
+	"github.com/glothriel/grf/pkg/types"
+	"github.com/shopspring/decimal"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// DecimalFormatString represents decimal.Decimal fields as JSON strings, eg. "12.30"
+	DecimalFormatString = "string"
+	// DecimalFormatNumber represents decimal.Decimal fields as JSON numbers, eg. 12.30
+	DecimalFormatNumber = "number"
+)
+
+var (
+	decimalType = reflect.TypeOf(decimal.Decimal{})
+	moneyType   = reflect.TypeOf(models.Money{})
+)
+
+// decimalOptions are the settings of a decimal.Decimal field, parsed from the `grf` tag. Negative limits
+// are not checked.
+type decimalOptions struct {
+	format        string
+	maxDigits     int
+	decimalPlaces int
+}
+
+func getDecimalOptions[Model any](fieldName string) decimalOptions {
+	options := decimalOptions{format: DecimalFormatString, maxDigits: -1, decimalPlaces: -1}
+	var m Model
+	for _, field := range reflect.VisibleFields(reflect.TypeOf(m)) {
+		if field.Tag.Get("json") != fieldName {
+			continue
+		}
+		settings := models.ParseTag(field)
+		if format, ok := settings[models.TagDecimalFormat]; ok {
+			if format != DecimalFormatString && format != DecimalFormatNumber {
+				logrus.Panicf(
+					"Invalid `%s` value `%s` of field `%T`.`%s`, use `%s` or `%s`",
+					models.TagDecimalFormat, format, m, fieldName, DecimalFormatString, DecimalFormatNumber,
+				)
+			}
+			options.format = format
+		}
+		options.maxDigits = parseIntTagSetting[Model](settings, models.TagMaxDigits, fieldName, options.maxDigits)
+		options.decimalPlaces = parseIntTagSetting[Model](
+			settings, models.TagDecimalPlaces, fieldName, options.decimalPlaces,
+		)
+	}
+	return options
+}
+
+func parseIntTagSetting[Model any](settings map[string]string, key, fieldName string, defaultValue int) int {
+	raw, ok := settings[key]
+	if !ok {
+		return defaultValue
+	}
+	value, parseErr := strconv.Atoi(raw)
+	if parseErr != nil || value < 0 {
+		var m Model
+		logrus.Panicf("Invalid `%s` value `%s` of field `%T`.`%s`, expected a non-negative integer", key, raw, m, fieldName)
+	}
+	return value
+}
+
+type decimalToInternalValueDetector[Model any] struct {
+	mapper *types.FieldTypeMapper
+}
+
+func (p *decimalToInternalValueDetector[Model]) ToInternalValue(fieldName string) (fields.InternalValueFunc, error) {
+	fieldSettings := getFieldSettings[Model](fieldName)
+	if fieldSettings == nil || fieldSettings.itsType != decimalType {
+		return nil, fmt.Errorf("Field `%s` is not a decimal.Decimal", fieldName)
+	}
+	convert, noConverterErr := p.mapper.ToInternalValue(decimalType.String())
+	if noConverterErr != nil {
+		return nil, noConverterErr
+	}
+	options := getDecimalOptions[Model](fieldName)
+	return ConvertFuncToInternalValueFuncAdapter(
+		func(v any) (any, error) {
+			switch v.(type) {
+			case string:
+				if options.format != DecimalFormatString {
+					return nil, fmt.Errorf("Expected a number, got a string")
+				}
+			case json.Number:
+				if options.format != DecimalFormatNumber {
+					return nil, fmt.Errorf("Expected a string, eg. \"12.30\", got a number")
+				}
+			case float64:
+				if options.format != DecimalFormatNumber {
+					return nil, fmt.Errorf("Expected a string, eg. \"12.30\", got a number")
+				}
+				return nil, fmt.Errorf("Could not read the exact value of the number")
+			default:
+				return nil, fmt.Errorf("Expected a decimal %s, got `%T`", options.format, v)
+			}
+			d, convertErr := convert(v)
+			if convertErr != nil {
+				return nil, convertErr
+			}
+			if validateErr := types.ValidateDecimal(
+				d.(decimal.Decimal), options.maxDigits, options.decimalPlaces,
+			); validateErr != nil {
+				return nil, validateErr
+			}
+			return d, nil
+		},
+	), nil
+}
+
+type decimalToRepresentationProvider[Model any] struct {
+	mapper *types.FieldTypeMapper
+}
+
+func (p decimalToRepresentationProvider[Model]) ToRepresentation(fieldName string) (fields.RepresentationFunc, error) {
+	fieldSettings := getFieldSettings[Model](fieldName)
+	if fieldSettings == nil || fieldSettings.itsType != decimalType {
+		return nil, fmt.Errorf("Field `%s` is not a decimal.Decimal", fieldName)
+	}
+	convert, noConverterErr := p.mapper.ToRepresentation(decimalType.String())
+	if noConverterErr != nil {
+		return nil, noConverterErr
+	}
+	options := getDecimalOptions[Model](fieldName)
+	return ConvertFuncToRepresentationFuncAdapter(
+		func(v any) (any, error) {
+			if d, ok := v.(*decimal.Decimal); ok && d != nil {
+				v = *d
+			}
+			representation, convertErr := convert(v)
+			if convertErr != nil {
+				return nil, convertErr
+			}
+			if options.decimalPlaces >= 0 {
+				representation = v.(decimal.Decimal).StringFixed(int32(options.decimalPlaces))
+			}
+			if options.format == DecimalFormatNumber {
+				return json.Number(representation.(string)), nil
+			}
+			return representation, nil
+		},
+	), nil
+}
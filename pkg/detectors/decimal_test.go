@@ -0,0 +1,127 @@
+package detectors
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/glothriel/grf/pkg/models"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+type decimalModel struct {
+	Amount   decimal.Decimal  `json:"amount"`
+	Price    decimal.Decimal  `json:"price" grf:"max_digits:5;decimal_places:2"`
+	Weight   decimal.Decimal  `json:"weight" grf:"decimal_format:number"`
+	Discount *decimal.Decimal `json:"discount" grf:"decimal_format:number;decimal_places:1"`
+	Total    models.Money     `json:"total"`
+}
+
+func TestDecimalToInternalValue(t *testing.T) {
+	tests := []struct {
+		name       string
+		field      string
+		raw        any
+		wantIntVal any
+		wantErr    string
+	}{
+		{"string", "amount", "12.345678901234567890", decimal.RequireFromString("12.345678901234567890"), ""},
+		{"number in string format", "amount", json.Number("12.3"), nil, "Expected a string, eg. \"12.30\", got a number"},
+		{"float in string format", "amount", 12.3, nil, "Expected a string, eg. \"12.30\", got a number"},
+		{
+			"invalid string", "amount", "12,3", nil,
+			"Error converting request value to internal value for type `decimal.Decimal`: `12,3` is not a valid decimal number",
+		},
+		{"within limits", "price", "999.9", decimal.RequireFromString("999.9"), ""},
+		{"trailing zeros are not counted", "price", "1.2000", decimal.RequireFromString("1.2000"), ""},
+		{"too many digits", "price", "1000.01", nil, "Ensure that there are no more than 5 digits in total"},
+		{"too many decimal places", "price", "1.001", nil, "Ensure that there are no more than 2 decimal places"},
+		{"too many whole digits", "price", "1000", nil, "Ensure that there are no more than 3 digits before the decimal point"},
+		{"number", "weight", json.Number("0.1"), decimal.RequireFromString("0.1"), ""},
+		{"string in number format", "weight", "0.1", nil, "Expected a number, got a string"},
+		{"float in number format", "weight", 0.1, nil, "Could not read the exact value of the number"},
+		{"pointer", "discount", json.Number("0.5"), ptr(decimal.RequireFromString("0.5")), ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// given
+			toInternalValue, detectorErr := DefaultToInternalValueDetector[decimalModel]().ToInternalValue(tt.field)
+			assert.NoError(t, detectorErr)
+
+			// when
+			intVal, intValErr := toInternalValue(map[string]any{tt.field: tt.raw}, tt.field, nil)
+
+			// then
+			if tt.wantErr != "" {
+				assert.EqualError(t, intValErr, tt.wantErr)
+				return
+			}
+			assert.NoError(t, intValErr)
+			assert.Equal(t, tt.wantIntVal, intVal)
+		})
+	}
+}
+
+func TestDecimalToInternalValueReadsExactNumbers(t *testing.T) {
+	// given
+	decoder := json.NewDecoder(strings.NewReader(
+		`{"weight": 0.30000000000000000001, "total": {"amount": 12.3, "currency": "USD"}}`,
+	))
+	decoder.UseNumber()
+	var raw map[string]any
+	assert.NoError(t, decoder.Decode(&raw))
+	detector := DefaultToInternalValueDetector[decimalModel]()
+	weightToInternalValue, _ := detector.ToInternalValue("weight")
+	totalToInternalValue, _ := detector.ToInternalValue("total")
+
+	// when
+	weight, weightErr := weightToInternalValue(raw, "weight", nil)
+	total, totalErr := totalToInternalValue(raw, "total", nil)
+
+	// then
+	assert.NoError(t, weightErr)
+	assert.Equal(t, "0.30000000000000000001", weight.(decimal.Decimal).String())
+	assert.NoError(t, totalErr)
+	assert.Equal(t, "12.30 USD", total.(*models.Money).String())
+}
+
+func TestDecimalToRepresentation(t *testing.T) {
+	tests := []struct {
+		name     string
+		field    string
+		intVal   any
+		wantRepr any
+	}{
+		{"string", "amount", decimal.RequireFromString("12.345678901234567890"), "12.34567890123456789"},
+		{"pointer", "amount", ptr(decimal.RequireFromString("1.5")), "1.5"},
+		{"decimal places", "price", decimal.RequireFromString("12.3"), "12.30"},
+		{"number", "weight", decimal.RequireFromString("0.1"), json.Number("0.1")},
+		{"number with decimal places", "discount", ptr(decimal.RequireFromString("2")), json.Number("2.0")},
+		{"nil pointer", "discount", (*decimal.Decimal)(nil), nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// given
+			toRepresentation, detectorErr := DefaultToRepresentationDetector[decimalModel]().ToRepresentation(tt.field)
+			assert.NoError(t, detectorErr)
+
+			// when
+			repr, reprErr := toRepresentation(models.InternalValue{tt.field: tt.intVal}, tt.field, nil)
+
+			// then
+			assert.NoError(t, reprErr)
+			assert.Equal(t, tt.wantRepr, repr)
+		})
+	}
+}
+
+type invalidDecimalModel struct {
+	Price decimal.Decimal `json:"price" grf:"decimal_places:two"`
+}
+
+func TestDecimalInvalidTagPanics(t *testing.T) {
+	assert.Panics(t, func() {
+		_, _ = DefaultToInternalValueDetector[invalidDecimalModel]().ToInternalValue("price")
+	})
+}
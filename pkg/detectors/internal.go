@@ -5,10 +5,12 @@ import (
 	"encoding"
 	"fmt"
 	"reflect"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/glothriel/grf/pkg/fields"
+	"github.com/glothriel/grf/pkg/models"
 	"github.com/glothriel/grf/pkg/types"
 )
 
@@ -33,7 +35,9 @@ func (p *missingFieldSkippingToInternalValueDetector[Model]) ToInternalValue(fie
 	}, nil
 }
 
-type usingGRFParsableToInternalValueDetector[Model any] struct{}
+type usingGRFParsableToInternalValueDetector[Model any] struct {
+	mapper *types.FieldTypeMapper
+}
 
 func (p *usingGRFParsableToInternalValueDetector[Model]) ToInternalValue(fieldName string) (fields.InternalValueFunc, error) {
 	fieldSettings := getFieldSettings[Model](fieldName)
@@ -41,6 +45,9 @@ func (p *usingGRFParsableToInternalValueDetector[Model]) ToInternalValue(fieldNa
 		return ConvertFuncToInternalValueFuncAdapter(
 			func(v any) (any, error) {
 				typedV := reflect.New(fieldSettings.itsType).Interface()
+				if mapperParsable, ok := typedV.(models.MapperParsable); ok {
+					return typedV, mapperParsable.FromRepresentationWithMapper(v, p.mapper)
+				}
 				theErr := typedV.(fields.GRFParsable).FromRepresentation(v)
 				return typedV, theErr
 			},
@@ -74,10 +81,17 @@ func (p *isoTimeTimeToInternalValueDetector[Model]) ToInternalValue(fieldName st
 type fromTypeMapperToInternalValueDetector[Model any] struct {
 	mapper         *types.FieldTypeMapper
 	modelTypeNames map[string]string
+	// overridesOnly limits the detector to the types overridden in the mapper, see FieldTypeMapper.Overrides
+	overridesOnly bool
 }
 
 func (p *fromTypeMapperToInternalValueDetector[Model]) ToInternalValue(fieldName string) (fields.InternalValueFunc, error) {
-	ftmToInternalValue, toInternalValueErr := p.mapper.ToInternalValue(p.modelTypeNames[fieldName])
+	// Pointers are handled by pointerToInternalValueDetector, the mapper is asked about the underlying type
+	typeName := strings.TrimPrefix(p.modelTypeNames[fieldName], "*")
+	if p.overridesOnly && !p.mapper.Overrides(typeName) {
+		return nil, fmt.Errorf("Type `%s` of field `%s` is not overridden", typeName, fieldName)
+	}
+	ftmToInternalValue, toInternalValueErr := p.mapper.ToInternalValue(typeName)
 	if toInternalValueErr != nil {
 		return nil, toInternalValueErr
 	}
@@ -161,16 +175,30 @@ func (p *chainingToInternalValueDetector[Model]) ToInternalValue(fieldName strin
 	return nil, fmt.Errorf("No internal value function could be found for field `%T`.`%s`", m, fieldName)
 }
 
+// DefaultToInternalValueDetector returns the detector using the default types.FieldTypeMapper
 func DefaultToInternalValueDetector[Model any]() ToInternalValueDetector {
+	return NewToInternalValueDetector[Model](types.Mapper())
+}
+
+// NewToInternalValueDetector returns the detector converting the types known to the mapper using it. The
+// types overridden in the mapper take precedence over any other way of parsing them.
+func NewToInternalValueDetector[Model any](mapper *types.FieldTypeMapper) ToInternalValueDetector {
+	modelTypeNames := FieldTypes[Model]()
 	return &missingFieldSkippingToInternalValueDetector[Model]{
 		child: &relationshipDetector[Model]{
-			internalChild: &chainingToInternalValueDetector[Model]{
+			internalChild: &pointerToInternalValueDetector[Model]{child: &chainingToInternalValueDetector[Model]{
 				children: []ToInternalValueDetector{
-					&usingGRFParsableToInternalValueDetector[Model]{},
+					&fromTypeMapperToInternalValueDetector[Model]{
+						mapper:         mapper,
+						modelTypeNames: modelTypeNames,
+						overridesOnly:  true,
+					},
+					&usingGRFParsableToInternalValueDetector[Model]{mapper: mapper},
+					&decimalToInternalValueDetector[Model]{mapper: mapper},
 					&isoTimeTimeToInternalValueDetector[Model]{},
 					&fromTypeMapperToInternalValueDetector[Model]{
-						mapper:         types.Mapper(),
-						modelTypeNames: FieldTypes[Model](),
+						mapper:         mapper,
+						modelTypeNames: modelTypeNames,
 					},
 					&encodingTextUnmarshalerToInternalValueDetector[Model]{},
 					&usingSqlNullFieldToInternalValueDetector[Model, sql.NullBool]{
@@ -187,7 +215,7 @@ func DefaultToInternalValueDetector[Model any]() ToInternalValueDetector {
 					},
 					&usingSqlNullFieldToInternalValueDetector[Model, sql.NullInt16]{
 						valueFunc: func(v any) (any, error) {
-							vAsFloat64, ok := v.(float64)
+							vAsFloat64, ok := types.AsFloat64(v)
 							if !ok {
 								return nil, fmt.Errorf("`%s` is not a float64, it is a %T", v, v)
 							}
@@ -199,7 +227,7 @@ func DefaultToInternalValueDetector[Model any]() ToInternalValueDetector {
 					},
 					&usingSqlNullFieldToInternalValueDetector[Model, sql.NullInt32]{
 						valueFunc: func(v any) (any, error) {
-							vAsFloat64, ok := v.(float64)
+							vAsFloat64, ok := types.AsFloat64(v)
 							if !ok {
 								return nil, fmt.Errorf("`%s` is not a float64, it is a %T", v, v)
 							}
@@ -211,7 +239,7 @@ func DefaultToInternalValueDetector[Model any]() ToInternalValueDetector {
 					},
 					&usingSqlNullFieldToInternalValueDetector[Model, sql.NullInt64]{
 						valueFunc: func(v any) (any, error) {
-							vAsFloat64, ok := v.(float64)
+							vAsFloat64, ok := types.AsFloat64(v)
 							if !ok {
 								return nil, fmt.Errorf("`%s` is not a float64, it is a %T", v, v)
 							}
@@ -223,7 +251,7 @@ func DefaultToInternalValueDetector[Model any]() ToInternalValueDetector {
 					},
 					&usingSqlNullFieldToInternalValueDetector[Model, sql.NullFloat64]{
 						valueFunc: func(v any) (any, error) {
-							vAsFloat64, ok := v.(float64)
+							vAsFloat64, ok := types.AsFloat64(v)
 							if !ok {
 								return nil, fmt.Errorf("`%s` is not a float64, it is a %T", v, v)
 							}
@@ -261,7 +289,7 @@ func DefaultToInternalValueDetector[Model any]() ToInternalValueDetector {
 						},
 					},
 				},
-			},
+			}},
 		},
 	}
 }
@@ -2,6 +2,7 @@ package detectors
 
 import (
 	"database/sql"
+	"encoding/json"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -73,6 +74,10 @@ func TestToInternalValue_SQLNullInt64(t *testing.T) {
 		Int64: 42,
 		Valid: true,
 	})
+	testSqlNullModelsToInternalValue[int64Model](t, json.Number("42"), sql.NullInt64{
+		Int64: 42,
+		Valid: true,
+	})
 	testSqlNullModelsToInternalValue[int64Model](t, nil, sql.NullInt64{
 		Int64: 0,
 		Valid: false,
@@ -201,6 +206,7 @@ func TestToInternalValue_Int64(t *testing.T) {
 	}
 
 	testSqlNullModelsToInternalValue[int64Model](t, float64(42), int64(42))
+	testSqlNullModelsToInternalValue[int64Model](t, json.Number("42"), int64(42))
 }
 
 func TestToInternalValue_Int(t *testing.T) {
@@ -241,4 +247,5 @@ func TestToInternalValue_Uint64(t *testing.T) {
 	}
 
 	testSqlNullModelsToInternalValue[uint64Model](t, float64(42), uint64(42))
+	testSqlNullModelsToInternalValue[uint64Model](t, json.Number("42"), uint64(42))
 }
@@ -0,0 +1,82 @@
+package detectors
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/gin-gonic/gin"
+	"github.com/glothriel/grf/pkg/fields"
+	"github.com/glothriel/grf/pkg/models"
+)
+
+// pointerToInternalValueDetector reuses the detector of the underlying type for pointer fields. `null` is
+// converted to a nil pointer, other values are converted using the child and wrapped in a pointer.
+type pointerToInternalValueDetector[Model any] struct {
+	child ToInternalValueDetector
+}
+
+func (p *pointerToInternalValueDetector[Model]) ToInternalValue(fieldName string) (fields.InternalValueFunc, error) {
+	childDetector, childErr := p.child.ToInternalValue(fieldName)
+	if childErr != nil {
+		return nil, childErr
+	}
+	fieldSettings := getFieldSettings[Model](fieldName)
+	if fieldSettings == nil || !fieldSettings.isPointer {
+		return childDetector, nil
+	}
+	return func(m map[string]any, s string, c *gin.Context) (any, error) {
+		if m[s] == nil {
+			return reflect.Zero(reflect.PointerTo(fieldSettings.itsType)).Interface(), nil
+		}
+		v, err := childDetector(m, s, c)
+		if err != nil {
+			return nil, err
+		}
+		return asPointerTo(v, fieldSettings.itsType)
+	}, nil
+}
+
+// asPointerTo returns a pointer to the value. Some detectors, like the ones using encoding.TextUnmarshaler,
+// already return pointers, those are returned as is.
+func asPointerTo(v any, elemType reflect.Type) (any, error) {
+	value := reflect.ValueOf(v)
+	if !value.IsValid() {
+		return reflect.Zero(reflect.PointerTo(elemType)).Interface(), nil
+	}
+	if value.Type() == reflect.PointerTo(elemType) {
+		return v, nil
+	}
+	if !value.Type().ConvertibleTo(elemType) {
+		return nil, fmt.Errorf("`%v` can't be converted to %s", v, elemType)
+	}
+	ptr := reflect.New(elemType)
+	ptr.Elem().Set(value.Convert(elemType))
+	return ptr.Interface(), nil
+}
+
+// pointerToRepresentationDetector reuses the detector of the underlying type for pointer fields, nil pointers
+// are represented as `null`
+type pointerToRepresentationDetector[Model any] struct {
+	child ToRepresentationDetector[Model]
+}
+
+func (p *pointerToRepresentationDetector[Model]) ToRepresentation(fieldName string) (fields.RepresentationFunc, error) {
+	childDetector, childErr := p.child.ToRepresentation(fieldName)
+	if childErr != nil {
+		return nil, childErr
+	}
+	fieldSettings := getFieldSettings[Model](fieldName)
+	if fieldSettings == nil || !fieldSettings.isPointer {
+		return childDetector, nil
+	}
+	return func(intVal models.InternalValue, s string, c *gin.Context) (any, error) {
+		value := reflect.ValueOf(intVal[s])
+		if !value.IsValid() || (value.Kind() == reflect.Pointer && value.IsNil()) {
+			return nil, nil
+		}
+		if value.Kind() == reflect.Pointer {
+			value = value.Elem()
+		}
+		return childDetector(models.InternalValue{s: value.Interface()}, s, c)
+	}, nil
+}
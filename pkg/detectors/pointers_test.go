@@ -0,0 +1,68 @@
+package detectors
+
+import (
+	"testing"
+	"time"
+
+	"github.com/glothriel/grf/pkg/models"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+type pointerModel struct {
+	Name      *string    `json:"name"`
+	Count     *int       `json:"count"`
+	Active    *bool      `json:"active"`
+	CreatedAt *time.Time `json:"created_at"`
+	Token     *uuid.UUID `json:"token"`
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+func TestPointerFields(t *testing.T) {
+	createdAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	token := uuid.MustParse("6ba7b810-9dad-11d1-80b4-00c04fd430c8")
+	tests := []struct {
+		field       string
+		raw         any
+		wantIntVal  any
+		wantRepr    any
+		wantInvalid any
+	}{
+		{"name", "foo", ptr("foo"), "foo", float64(1)},
+		{"count", float64(42), ptr(42), 42, "42"},
+		{"active", true, ptr(true), true, "true"},
+		{"created_at", "2024-01-02T03:04:05Z", &createdAt, "2024-01-02T03:04:05Z", "yesterday"},
+		{"token", token.String(), &token, token.String(), "not-a-uuid"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.field, func(t *testing.T) {
+			// given
+			toInternalValue, internalErr := DefaultToInternalValueDetector[pointerModel]().ToInternalValue(tt.field)
+			toRepresentation, representationErr := DefaultToRepresentationDetector[pointerModel]().ToRepresentation(tt.field)
+			assert.NoError(t, internalErr)
+			assert.NoError(t, representationErr)
+
+			// when
+			intVal, intValErr := toInternalValue(map[string]any{tt.field: tt.raw}, tt.field, nil)
+			nullIntVal, nullIntValErr := toInternalValue(map[string]any{tt.field: nil}, tt.field, nil)
+			_, invalidErr := toInternalValue(map[string]any{tt.field: tt.wantInvalid}, tt.field, nil)
+			repr, reprErr := toRepresentation(models.InternalValue{tt.field: intVal}, tt.field, nil)
+			nullRepr, nullReprErr := toRepresentation(models.InternalValue{tt.field: nullIntVal}, tt.field, nil)
+
+			// then
+			assert.NoError(t, intValErr)
+			assert.Equal(t, tt.wantIntVal, intVal)
+			assert.NoError(t, nullIntValErr)
+			assert.Nil(t, nullIntVal)
+			assert.IsType(t, tt.wantIntVal, nullIntVal)
+			assert.Error(t, invalidErr)
+			assert.NoError(t, reprErr)
+			assert.Equal(t, tt.wantRepr, repr)
+			assert.NoError(t, nullReprErr)
+			assert.Nil(t, nullRepr)
+		})
+	}
+}
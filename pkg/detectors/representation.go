@@ -5,6 +5,7 @@ import (
 	"encoding"
 	"fmt"
 	"reflect"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -19,16 +20,30 @@ type ToRepresentationDetector[Model any] interface {
 	ToRepresentation(fieldName string) (fields.RepresentationFunc, error)
 }
 
+// DefaultToRepresentationDetector returns the detector using the default types.FieldTypeMapper
 func DefaultToRepresentationDetector[Model any]() ToRepresentationDetector[Model] {
+	return NewToRepresentationDetector[Model](types.Mapper())
+}
+
+// NewToRepresentationDetector returns the detector converting the types known to the mapper using it. The
+// types overridden in the mapper take precedence over any other way of representing them.
+func NewToRepresentationDetector[Model any](mapper *types.FieldTypeMapper) ToRepresentationDetector[Model] {
+	modelTypeNames := FieldTypes[Model]()
 	return &missingFieldSkippingToRepresentationDetector[Model]{
 		child: &relationshipDetector[Model]{
-			representationChild: &chainingToRepresentationDetector[Model]{
+			representationChild: &pointerToRepresentationDetector[Model]{child: &chainingToRepresentationDetector[Model]{
 				children: []ToRepresentationDetector[Model]{
-					&usingGRFRepresentableToRepresentationProvider[Model]{},
+					&fromTypeMapperToRepresentationProvider[Model]{
+						mapper:         mapper,
+						modelTypeNames: modelTypeNames,
+						overridesOnly:  true,
+					},
+					&usingGRFRepresentableToRepresentationProvider[Model]{mapper: mapper},
 					&timeTimeToRepresentationProvider[Model]{},
+					&decimalToRepresentationProvider[Model]{mapper: mapper},
 					&fromTypeMapperToRepresentationProvider[Model]{
-						mapper:         types.Mapper(),
-						modelTypeNames: FieldTypes[Model](),
+						mapper:         mapper,
+						modelTypeNames: modelTypeNames,
 					},
 					&encodingTextMarshalerToRepresentationProvider[Model]{},
 					&usingSqlNullFieldToRepresentationProvider[Model, sql.NullBool]{
@@ -88,7 +103,7 @@ func DefaultToRepresentationDetector[Model any]() ToRepresentationDetector[Model
 						},
 					},
 				},
-			},
+			}},
 		},
 	}
 }
@@ -110,13 +125,18 @@ func (p missingFieldSkippingToRepresentationDetector[Model]) ToRepresentation(fi
 	}, nil
 }
 
-type usingGRFRepresentableToRepresentationProvider[Model any] struct{}
+type usingGRFRepresentableToRepresentationProvider[Model any] struct {
+	mapper *types.FieldTypeMapper
+}
 
 func (p usingGRFRepresentableToRepresentationProvider[Model]) ToRepresentation(fieldName string) (fields.RepresentationFunc, error) {
 	fieldSettings := getFieldSettings[Model](fieldName)
 	if fieldSettings != nil && fieldSettings.isGRFRepresentable {
 		return ConvertFuncToRepresentationFuncAdapter(
 			func(v any) (any, error) {
+				if mapperRepresentable, ok := v.(models.MapperRepresentable); ok {
+					return mapperRepresentable.ToRepresentationWithMapper(p.mapper)
+				}
 				return v.(fields.GRFRepresentable).ToRepresentation()
 			},
 		), nil
@@ -185,10 +205,17 @@ func (p usingSqlNullFieldToRepresentationProvider[Model, sqlNullType]) ToReprese
 type fromTypeMapperToRepresentationProvider[Model any] struct {
 	mapper         *types.FieldTypeMapper
 	modelTypeNames map[string]string
+	// overridesOnly limits the provider to the types overridden in the mapper, see FieldTypeMapper.Overrides
+	overridesOnly bool
 }
 
 func (p fromTypeMapperToRepresentationProvider[Model]) ToRepresentation(fieldName string) (fields.RepresentationFunc, error) {
-	ftmToRepresentation, toRepresentationErr := p.mapper.ToRepresentation(p.modelTypeNames[fieldName])
+	// Pointers are handled by pointerToRepresentationDetector, the mapper is asked about the underlying type
+	typeName := strings.TrimPrefix(p.modelTypeNames[fieldName], "*")
+	if p.overridesOnly && !p.mapper.Overrides(typeName) {
+		return nil, fmt.Errorf("Type `%s` of field `%s` is not overridden", typeName, fieldName)
+	}
+	ftmToRepresentation, toRepresentationErr := p.mapper.ToRepresentation(typeName)
 	if toRepresentationErr != nil {
 		return nil, toRepresentationErr
 	}
@@ -8,6 +8,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/glothriel/grf/pkg/fields"
 	"github.com/glothriel/grf/pkg/models"
+	"github.com/glothriel/grf/pkg/openapi"
 	"github.com/glothriel/grf/pkg/queries"
 	"github.com/glothriel/grf/pkg/queries/common"
 	"github.com/glothriel/grf/pkg/queries/gormq"
@@ -32,7 +33,7 @@ type Product struct {
 
 	Name        string          `json:"name" gorm:"size:191;column:name"`
 	Description string          `json:"description" gorm:"type:text;column:description"`
-	Price       decimal.Decimal `json:"price" gorm:"type:decimal(8,2)"`
+	Price       decimal.Decimal `json:"price" gorm:"type:decimal(8,2)" grf:"max_digits:8;decimal_places:2"`
 
 	CategoryID string   `json:"category_id" gorm:"size:191;column:category_id"`
 	Category   Category `gorm:"constraint:OnUpdate:CASCADE,OnDelete:CASCADE;not null;"`
@@ -71,6 +72,8 @@ func main() {
 		logrus.Fatalf("Error migrating database: %s", migrateErr)
 	}
 
+	registry := views.NewRegistry()
+
 	views.NewModelViewSet[Product](
 		"/products",
 		queries.GORM[Product](gormDB).WithFilter(
@@ -95,7 +98,7 @@ func main() {
 	).WithListSerializer(
 		serializers.NewModelSerializer[Product]().
 			WithModelFields([]string{"id", "name"}),
-	).Register(router)
+	).WithRegistry(registry).Register(router)
 
 	views.NewViewSet[Category](
 		"/categories",
@@ -115,7 +118,7 @@ func main() {
 	).WithListSerializer(
 		serializers.NewModelSerializer[Category]().
 			WithModelFields([]string{"id", "name"}),
-	).Register(router)
+	).WithRegistry(registry).Register(router)
 
 	views.NewModelViewSet[Photo](
 		"/products/:product_id/photos",
@@ -144,7 +147,7 @@ func main() {
 				},
 			),
 		),
-	).Register(router)
+	).WithRegistry(registry).Register(router)
 
 	meQD := queries.GORM[CustomerProfile](gormDB)
 	converter := gormq.FromDBConverter[CustomerProfile]()
@@ -215,7 +218,12 @@ func main() {
 			},
 		),
 		false,
-	).Register(router)
+	).WithRegistry(registry).Register(router)
+
+	router.GET("/openapi.json", openapi.Handler(registry, openapi.Info{
+		Title:   "Products",
+		Version: "1.0.0",
+	}))
 
 	logrus.Fatal(router.Run(fmt.Sprintf(":%d", *serverPort)))
 }
@@ -8,6 +8,9 @@ import (
 type RepresentationFunc func(models.InternalValue, string, *gin.Context) (any, error)
 type InternalValueFunc func(map[string]any, string, *gin.Context) (any, error)
 
+// DefaultFunc returns the internal value used when the field is missing from the payload
+type DefaultFunc func(*gin.Context) (any, error)
+
 type ErrorFieldIsNotPresentInPayload struct {
 	name string
 }
@@ -36,13 +39,74 @@ type Field interface {
 	WithInternalValueFunc(InternalValueFunc) Field
 }
 
+// OptionsField is a Field supporting the required, default, null and blank options, like ConcreteField. It's
+// optional, so custom Field implementations keep working without it: IsRequired, AllowsNull, AllowsBlank,
+// HasDefault and Default treat them like a new ConcreteField, that is not required, accepts `null` and blank
+// strings and has no default.
+type OptionsField interface {
+	Field
+
+	// IsRequired tells if the field has to be present in the payload when creating an entity
+	IsRequired() bool
+	AllowsNull() bool
+	AllowsBlank() bool
+	HasDefault() bool
+	// Default returns the internal value used when the field is missing from the payload
+	Default(*gin.Context) (any, error)
+
+	WithRequired(bool) OptionsField
+	WithAllowNull(bool) OptionsField
+	WithAllowBlank(bool) OptionsField
+	WithDefault(any) OptionsField
+	WithDefaultFunc(DefaultFunc) OptionsField
+}
+
+// IsRequired tells if the field has to be present in the payload when creating an entity
+func IsRequired(field Field) bool {
+	options, ok := field.(OptionsField)
+	return ok && options.IsRequired()
+}
+
+// AllowsNull tells if `null` is accepted in the payload
+func AllowsNull(field Field) bool {
+	options, ok := field.(OptionsField)
+	return !ok || options.AllowsNull()
+}
+
+// AllowsBlank tells if an empty string is accepted in the payload
+func AllowsBlank(field Field) bool {
+	options, ok := field.(OptionsField)
+	return !ok || options.AllowsBlank()
+}
+
+// HasDefault tells if the field has a value used when it's missing from the payload
+func HasDefault(field Field) bool {
+	options, ok := field.(OptionsField)
+	return ok && options.HasDefault()
+}
+
+// Default returns the internal value used when the field is missing from the payload, nil if it has none
+func Default(field Field, ctx *gin.Context) (any, error) {
+	options, ok := field.(OptionsField)
+	if !ok {
+		return nil, nil
+	}
+	return options.Default(ctx)
+}
+
 type ConcreteField[Model any] struct {
 	name               string
 	representationFunc RepresentationFunc
 	internalValueFunc  InternalValueFunc
+	defaultFunc        DefaultFunc
+	staticDefault      any
+	hasStaticDefault   bool
 
-	Readable bool
-	Writable bool
+	Readable   bool
+	Writable   bool
+	Required   bool
+	AllowNull  bool
+	AllowBlank bool
 }
 
 func (s *ConcreteField[Model]) Name() string {
@@ -93,6 +157,72 @@ func (s *ConcreteField[Model]) WithInternalValueFunc(f InternalValueFunc) Field
 	return s
 }
 
+func (s *ConcreteField[Model]) IsRequired() bool {
+	return s.Required
+}
+
+func (s *ConcreteField[Model]) AllowsNull() bool {
+	return s.AllowNull
+}
+
+func (s *ConcreteField[Model]) AllowsBlank() bool {
+	return s.AllowBlank
+}
+
+func (s *ConcreteField[Model]) HasDefault() bool {
+	return s.defaultFunc != nil
+}
+
+func (s *ConcreteField[Model]) Default(ctx *gin.Context) (any, error) {
+	if s.defaultFunc == nil {
+		return nil, nil
+	}
+	return s.defaultFunc(ctx)
+}
+
+// StaticDefault returns the value set using WithDefault, it's used to describe the field in the API schema
+func (s *ConcreteField[Model]) StaticDefault() (any, bool) {
+	return s.staticDefault, s.hasStaticDefault
+}
+
+// WithRequired makes the field mandatory when creating an entity, unless it has a default
+func (s *ConcreteField[Model]) WithRequired(required bool) OptionsField {
+	s.Required = required
+	return s
+}
+
+// WithAllowNull controls if `null` is accepted in the payload
+func (s *ConcreteField[Model]) WithAllowNull(allowNull bool) OptionsField {
+	s.AllowNull = allowNull
+	return s
+}
+
+// WithAllowBlank controls if an empty string is accepted in the payload
+func (s *ConcreteField[Model]) WithAllowBlank(allowBlank bool) OptionsField {
+	s.AllowBlank = allowBlank
+	return s
+}
+
+// WithDefault sets the internal value used when the field is missing from the payload on create and full
+// update
+func (s *ConcreteField[Model]) WithDefault(value any) OptionsField {
+	s.defaultFunc = func(*gin.Context) (any, error) {
+		return value, nil
+	}
+	s.staticDefault = value
+	s.hasStaticDefault = true
+	return s
+}
+
+// WithDefaultFunc is like WithDefault, but the value is computed for every request, for example a timestamp
+// or a value based on the current user
+func (s *ConcreteField[Model]) WithDefaultFunc(f DefaultFunc) OptionsField {
+	s.defaultFunc = f
+	s.staticDefault = nil
+	s.hasStaticDefault = false
+	return s
+}
+
 func NewField[Model any](name string) Field {
 	return &ConcreteField[Model]{
 		name: name,
@@ -102,8 +232,10 @@ func NewField[Model any](name string) Field {
 		internalValueFunc: func(reprModel map[string]any, name string, ctx *gin.Context) (any, error) {
 			return reprModel[name], nil
 		},
-		Readable: true,
-		Writable: true,
+		Readable:   true,
+		Writable:   true,
+		AllowNull:  true,
+		AllowBlank: true,
 	}
 }
 
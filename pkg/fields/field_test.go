@@ -173,3 +173,55 @@ func TestFieldDefaultFuncs(t *testing.T) {
 	assert.Equal(t, "foo", reprVal)
 	assert.Nil(t, reprValErr)
 }
+
+func TestFieldDefaults(t *testing.T) {
+	// given
+	field := NewField[struct{}]("foo")
+	staticField := NewField[struct{}]("foo").(OptionsField).WithDefault("bar")
+	funcField := NewField[struct{}]("foo").(OptionsField).WithDefaultFunc(func(ctx *gin.Context) (any, error) {
+		return ctx.GetString("user"), nil
+	})
+	ctx := &gin.Context{}
+	ctx.Set("user", "alice")
+
+	// when
+	staticValue, staticErr := staticField.Default(ctx)
+	funcValue, funcErr := funcField.Default(ctx)
+	staticDefault, hasStaticDefault := staticField.(*ConcreteField[struct{}]).StaticDefault()
+	_, funcHasStaticDefault := funcField.(*ConcreteField[struct{}]).StaticDefault()
+
+	// then
+	assert.False(t, HasDefault(field))
+	assert.True(t, staticField.HasDefault())
+	assert.True(t, funcField.HasDefault())
+	assert.NoError(t, staticErr)
+	assert.NoError(t, funcErr)
+	assert.Equal(t, "bar", staticValue)
+	assert.Equal(t, "alice", funcValue)
+	assert.Equal(t, "bar", staticDefault)
+	assert.True(t, hasStaticDefault)
+	assert.False(t, funcHasStaticDefault)
+}
+
+// customField implements only the methods required by Field, without the options
+type customField struct {
+	Field
+}
+
+func TestFieldOptionsOfCustomFields(t *testing.T) {
+	// given
+	var field Field = customField{Field: NewField[struct{}]("foo")}
+
+	// when
+	defaultValue, defaultErr := Default(field, &gin.Context{})
+
+	// then
+	_, isOptionsField := field.(OptionsField)
+	assert.False(t, isOptionsField)
+	assert.False(t, IsRequired(field))
+	assert.True(t, AllowsNull(field))
+	assert.True(t, AllowsBlank(field))
+	assert.False(t, HasDefault(field))
+	assert.NoError(t, defaultErr)
+	assert.Nil(t, defaultValue)
+}
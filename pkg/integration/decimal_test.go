@@ -0,0 +1,78 @@
+package integration
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/glothriel/grf/pkg/models"
+	"github.com/glothriel/grf/pkg/queries"
+	"github.com/glothriel/grf/pkg/views"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+type Invoice struct {
+	ID       uint            `json:"id" gorm:"primaryKey"`
+	Rate     decimal.Decimal `json:"rate" gorm:"type:text" grf:"decimal_format:number"`
+	Discount decimal.Decimal `json:"discount" gorm:"type:text" grf:"max_digits:4;decimal_places:2"`
+	Total    models.Money    `json:"total"`
+}
+
+func TestDecimalAndMoneyKeepExactValues(t *testing.T) {
+	for name, driver := range map[string]queries.Driver[Invoice]{
+		"gorm":     queries.GORM[Invoice](openSQLite(t, &Invoice{})),
+		"inmemory": queries.InMemory[Invoice](),
+	} {
+		t.Run(name, func(t *testing.T) {
+			// given
+			router := gin.New()
+			views.NewModelViewSet[Invoice]("/invoices", driver).Register(router)
+
+			for _, step := range []struct {
+				method   string
+				path     string
+				body     string
+				wantCode int
+				wantBody string
+			}{
+				{
+					"POST", "/invoices",
+					`{"rate": 0.30000000000000000001, "discount": "1.5", "total": {"amount": 12.3, "currency": "USD"}}`,
+					201,
+					`{"discount":"1.50","id":1,"rate":0.30000000000000000001,"total":{"amount":"12.30","currency":"USD"}}`,
+				},
+				{
+					"POST", "/invoices",
+					`{"rate": 1, "discount": "100.5", "total": {"amount": "12.3", "currency": "USD"}}`,
+					400,
+					`{"errors":{"discount":["Ensure that there are no more than 2 digits before the decimal point"]}}`,
+				},
+				{
+					"POST", "/invoices",
+					`{"rate": 1, "discount": "1", "total": {"amount": "12.345", "currency": "USD"}}`,
+					400,
+					"",
+				},
+				{
+					"GET", "/invoices/1", "", 200,
+					`{"discount":"1.50","id":1,"rate":0.30000000000000000001,"total":{"amount":"12.30","currency":"USD"}}`,
+				},
+			} {
+				// when
+				w := httptest.NewRecorder()
+				req := httptest.NewRequest(step.method, step.path, strings.NewReader(step.body))
+				req.Header.Set("Content-Type", "application/json")
+				router.ServeHTTP(w, req)
+
+				// then
+				assert.Equal(t, step.wantCode, w.Code, "%s %s %s: %s", step.method, step.path, step.body, w.Body.String())
+				if step.wantBody != "" {
+					// compared as strings, as decoding the body to float64 would hide the lost precision
+					assert.Equal(t, step.wantBody, w.Body.String())
+				}
+			}
+		})
+	}
+}
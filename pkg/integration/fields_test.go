@@ -2,6 +2,7 @@ package integration
 
 import (
 	"database/sql"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"strings"
@@ -11,6 +12,7 @@ import (
 	embeddedpostgres "github.com/fergusstrange/embedded-postgres"
 	"github.com/gin-gonic/gin"
 	"github.com/glothriel/grf/pkg/models"
+	"github.com/google/uuid"
 	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
@@ -63,6 +65,26 @@ type TimeModel struct {
 	Value time.Time `json:"value" gorm:"column:value;type:timestamp"`
 }
 
+type IntSliceModel struct {
+	models.BaseModel
+	Value models.SliceField[int] `json:"value" gorm:"column:value;type:json"`
+}
+
+type Uint8SliceModel struct {
+	models.BaseModel
+	Value models.SliceField[uint8] `json:"value" gorm:"column:value;type:json"`
+}
+
+type UUIDSliceModel struct {
+	models.BaseModel
+	Value models.SliceField[uuid.UUID] `json:"value" gorm:"column:value;type:json"`
+}
+
+type TimeSliceModel struct {
+	models.BaseModel
+	Value models.SliceField[time.Time] `json:"value" gorm:"column:value;type:json"`
+}
+
 type BoolSliceModel struct {
 	models.BaseModel
 	Value models.SliceField[bool] `json:"value" gorm:"column:value;type:json"`
@@ -88,11 +110,36 @@ type DecimalModel struct {
 	Value decimal.Decimal `json:"value" gorm:"column:value"`
 }
 
+type NumberDecimalModel struct {
+	models.BaseModel
+	Value decimal.Decimal `json:"value" gorm:"column:value" grf:"decimal_format:number;max_digits:30;decimal_places:20"`
+}
+
 type NullFloat64Model struct {
 	models.BaseModel
 	Value sql.NullFloat64 `json:"value" gorm:"column:value"`
 }
 
+type PointerStringModel struct {
+	models.BaseModel
+	Value *string `json:"value" gorm:"column:value"`
+}
+
+type PointerIntModel struct {
+	models.BaseModel
+	Value *int `json:"value" gorm:"column:value"`
+}
+
+type JSONModel struct {
+	models.BaseModel
+	Value models.JSONField[map[string]any] `json:"value" gorm:"column:value"`
+}
+
+type MoneyModel struct {
+	models.BaseModel
+	Value models.Money `json:"value" gorm:"column:value"`
+}
+
 func DoTestTypes(t *testing.T, dialector gorm.Dialector) { // nolint: funlen
 	tests := []struct {
 		name        string
@@ -272,6 +319,79 @@ func DoTestTypes(t *testing.T, dialector gorm.Dialector) { // nolint: funlen
 				return registerModel[FloatSliceModel]("/float_slice_field", dialector)
 			},
 		},
+		{
+			name:    "Int Slice type",
+			baseURL: "/int_slice_field",
+			okBodies: []map[string]any{
+				{"value": []int{1, -2, 3}},
+				{"value": []int{}},
+			},
+			okResponses: []map[string]any{
+				{"value": []any{1.0, -2.0, 3.0}},
+				{"value": []any{}},
+			},
+			errorBodies: []map[string]any{
+				{"value": 1},
+				{"value": []any{1, 2.5}},
+				{"value": []any{"1", 2}},
+				{"value": []bool{true}},
+			},
+			router: func() *gin.Engine {
+				return registerModel[IntSliceModel]("/int_slice_field", dialector)
+			},
+		},
+		{
+			name:    "Uint8 Slice type",
+			baseURL: "/uint8_slice_field",
+			okBodies: []map[string]any{
+				{"value": []int{0, 255}},
+			},
+			okResponses: []map[string]any{
+				{"value": []any{0.0, 255.0}},
+			},
+			errorBodies: []map[string]any{
+				{"value": []int{-1}},
+				{"value": []int{256}},
+				{"value": []any{1.5}},
+			},
+			router: func() *gin.Engine {
+				return registerModel[Uint8SliceModel]("/uint8_slice_field", dialector)
+			},
+		},
+		{
+			name:    "UUID Slice type",
+			baseURL: "/uuid_slice_field",
+			okBodies: []map[string]any{
+				{"value": []string{"4c2b9e4e-7a3c-4a3e-9f3b-0b6a6c2e1b7d"}},
+			},
+			okResponses: []map[string]any{
+				{"value": []any{"4c2b9e4e-7a3c-4a3e-9f3b-0b6a6c2e1b7d"}},
+			},
+			errorBodies: []map[string]any{
+				{"value": []string{"not-a-uuid"}},
+				{"value": []int{1}},
+			},
+			router: func() *gin.Engine {
+				return registerModel[UUIDSliceModel]("/uuid_slice_field", dialector)
+			},
+		},
+		{
+			name:    "time.Time Slice type",
+			baseURL: "/time_slice_field",
+			okBodies: []map[string]any{
+				{"value": []string{"2021-01-01T00:00:00Z", "2021-01-01T02:00:00+02:00"}},
+			},
+			okResponses: []map[string]any{
+				{"value": []any{"2021-01-01T00:00:00Z", "2021-01-01T02:00:00+02:00"}},
+			},
+			errorBodies: []map[string]any{
+				{"value": []string{"2021-01-01"}},
+				{"value": []int{2021}},
+			},
+			router: func() *gin.Engine {
+				return registerModel[TimeSliceModel]("/time_slice_field", dialector)
+			},
+		},
 		{
 			name:    "time.Time type",
 			baseURL: "/time_field",
@@ -423,6 +543,26 @@ func DoTestTypes(t *testing.T, dialector gorm.Dialector) { // nolint: funlen
 				return registerModel[DecimalModel]("/decimal_field", dialector)
 			},
 		},
+		{
+			name:    "Decimal type in number format",
+			baseURL: "/number_decimal_field",
+			okBodies: []map[string]any{
+				{"value": json.Number("0.30000000000000000001")},
+				{"value": 1337},
+			},
+			okResponses: []map[string]any{
+				{"value": 0.30000000000000000001},
+				{"value": 1337.0},
+			},
+			errorBodies: []map[string]any{
+				{"value": "1.337"},
+				{"value": json.Number("0.300000000000000000001")},
+				{"value": true},
+			},
+			router: func() *gin.Engine {
+				return registerModel[NumberDecimalModel]("/number_decimal_field", dialector)
+			},
+		},
 		{
 			name:    "Nullable Float type",
 			baseURL: "/null_float64_field",
@@ -438,6 +578,91 @@ func DoTestTypes(t *testing.T, dialector gorm.Dialector) { // nolint: funlen
 				return registerModel[NullFloat64Model]("/null_float64_field", dialector)
 			},
 		},
+		{
+			name:    "Pointer String type",
+			baseURL: "/pointer_string_field",
+			okBodies: []map[string]any{
+				{"value": "hello world"},
+				{"value": ""},
+				{"value": nil},
+			},
+			okResponses: []map[string]any{
+				{"value": "hello world"},
+				{"value": ""},
+				{"value": nil},
+			},
+			errorBodies: []map[string]any{
+				{"value": map[string]string{"foo": "bar"}},
+				{"value": []int{1, 2, 3}},
+			},
+			router: func() *gin.Engine {
+				return registerModel[PointerStringModel]("/pointer_string_field", dialector)
+			},
+		},
+		{
+			name:    "Pointer Int type",
+			baseURL: "/pointer_int_field",
+			okBodies: []map[string]any{
+				{"value": 1337},
+				{"value": 0},
+				{"value": nil},
+			},
+			okResponses: []map[string]any{
+				{"value": 1337.0},
+				{"value": 0.0},
+				{"value": nil},
+			},
+			errorBodies: []map[string]any{
+				{"value": "hello world"},
+				{"value": map[string]string{"foo": "bar"}},
+			},
+			router: func() *gin.Engine {
+				return registerModel[PointerIntModel]("/pointer_int_field", dialector)
+			},
+		},
+		{
+			name:    "JSON type",
+			baseURL: "/json_field",
+			okBodies: []map[string]any{
+				{"value": map[string]any{"foo": "bar", "nested": map[string]any{"list": []any{1, true}}}},
+				{"value": map[string]any{}},
+			},
+			okResponses: []map[string]any{
+				{"value": map[string]any{"foo": "bar", "nested": map[string]any{"list": []any{1.0, true}}}},
+				{"value": map[string]any{}},
+			},
+			errorBodies: []map[string]any{
+				{"value": "hello world"},
+				{"value": 1},
+				{"value": []int{1, 2, 3}},
+				{"value": nil},
+			},
+			router: func() *gin.Engine {
+				return registerModel[JSONModel]("/json_field", dialector)
+			},
+		},
+		{
+			name:    "Money type",
+			baseURL: "/money_field",
+			okBodies: []map[string]any{
+				{"value": map[string]any{"amount": "12.3", "currency": "USD"}},
+				{"value": map[string]any{"amount": json.Number("1000"), "currency": "JPY"}},
+			},
+			okResponses: []map[string]any{
+				{"value": map[string]any{"amount": "12.30", "currency": "USD"}},
+				{"value": map[string]any{"amount": "1000", "currency": "JPY"}},
+			},
+			errorBodies: []map[string]any{
+				{"value": map[string]any{"amount": "12.345", "currency": "USD"}},
+				{"value": map[string]any{"amount": "12.3", "currency": "XYZ"}},
+				{"value": map[string]any{"amount": "12.3"}},
+				{"value": "12.30 USD"},
+				{"value": nil},
+			},
+			router: func() *gin.Engine {
+				return registerModel[MoneyModel]("/money_field", dialector)
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -0,0 +1,118 @@
+package integration
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/glothriel/grf/pkg/models"
+	"github.com/glothriel/grf/pkg/queries"
+	"github.com/glothriel/grf/pkg/queries/crud"
+	"github.com/glothriel/grf/pkg/queries/gormq"
+	"github.com/glothriel/grf/pkg/views"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+type HookedModel struct {
+	ID   uint   `json:"id" gorm:"primaryKey"`
+	Name string `json:"name"`
+}
+
+type HookLog struct {
+	ID      uint   `json:"id" gorm:"primaryKey"`
+	Content string `json:"content"`
+}
+
+func openSQLite(t *testing.T, registeredModels ...any) *gorm.DB {
+	db, openErr := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{})
+	require.NoError(t, openErr)
+	sqlDB, sqlDBErr := db.DB()
+	require.NoError(t, sqlDBErr)
+	sqlDB.SetMaxOpenConns(1)
+	require.NoError(t, db.AutoMigrate(registeredModels...))
+	return db
+}
+
+func hookDrivers(t *testing.T) map[string]queries.Driver[HookedModel] {
+	return map[string]queries.Driver[HookedModel]{
+		"gorm":     queries.GORM[HookedModel](openSQLite(t, &HookedModel{})),
+		"inmemory": queries.InMemory[HookedModel](),
+	}
+}
+
+func TestCRUDCustomizationsTakeEffectAtRequestTime(t *testing.T) {
+	for name, driver := range hookDrivers(t) {
+		t.Run(name, func(t *testing.T) {
+			// given
+			calls := []string{}
+			driver.CRUD().WrapRetrieve(func(retrieve crud.RetrieveQueryFunc) crud.RetrieveQueryFunc {
+				return func(ctx *gin.Context, id any) (models.InternalValue, error) {
+					calls = append(calls, "retrieve")
+					return retrieve(ctx, id)
+				}
+			})
+			router := gin.New()
+			views.NewModelViewSet[HookedModel]("/hooked", driver).OnCreate(
+				func(create crud.CreateQueryFunc) crud.CreateQueryFunc {
+					return func(ctx *gin.Context, new models.InternalValue) (models.InternalValue, error) {
+						calls = append(calls, "create")
+						new["name"] = new["name"].(string) + " (created)"
+						return create(ctx, new)
+					}
+				},
+			).OnUpdate(func(update crud.UpdateQueryFunc) crud.UpdateQueryFunc {
+				return func(ctx *gin.Context, old, new models.InternalValue, id any) (models.InternalValue, error) {
+					calls = append(calls, "update")
+					return update(ctx, old, new, id)
+				}
+			}).OnDestroy(func(destroy crud.DestroyQueryFunc) crud.DestroyQueryFunc {
+				return func(ctx *gin.Context, id any) error {
+					calls = append(calls, "destroy")
+					return destroy(ctx, id)
+				}
+			}).Register(router)
+
+			// when
+			created := httptest.NewRecorder()
+			router.ServeHTTP(created, newRequest("POST", "/hooked", map[string]any{"name": "first"}))
+			updated := httptest.NewRecorder()
+			router.ServeHTTP(updated, newRequest("PUT", "/hooked/1", map[string]any{"name": "second"}))
+			destroyed := httptest.NewRecorder()
+			router.ServeHTTP(destroyed, newRequest("DELETE", "/hooked/1", nil))
+
+			// then
+			assert.Equal(t, http.StatusCreated, created.Code)
+			assert.JSONEq(t, `{"id": 1, "name": "first (created)"}`, created.Body.String())
+			assert.Equal(t, http.StatusOK, updated.Code)
+			assert.Equal(t, http.StatusNoContent, destroyed.Code)
+			assert.Equal(t, []string{"create", "retrieve", "update", "retrieve", "destroy"}, calls)
+		})
+	}
+}
+
+func TestCreateTxHooksRunInViewSet(t *testing.T) {
+	// given
+	db := openSQLite(t, &HookedModel{}, &HookLog{})
+	router := gin.New()
+	views.NewModelViewSet[HookedModel]("/hooked", queries.GORM[HookedModel](db)).OnCreate(
+		gormq.CreateTx(gormq.AfterCreate(
+			func(ctx *gin.Context, iv models.InternalValue, tx *gorm.DB) (models.InternalValue, error) {
+				return iv, tx.Create(&HookLog{Content: "created " + iv["name"].(string)}).Error
+			},
+		)),
+	).Register(router)
+
+	// when
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, newRequest("POST", "/hooked", map[string]any{"name": "first"}))
+
+	// then
+	assert.Equal(t, http.StatusCreated, w.Code)
+	logs := []HookLog{}
+	assert.NoError(t, db.Find(&logs).Error)
+	assert.Equal(t, []HookLog{{ID: 1, Content: "created first"}}, logs)
+}
@@ -0,0 +1,91 @@
+package integration
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/glothriel/grf/pkg/models"
+	"github.com/glothriel/grf/pkg/queries"
+	"github.com/glothriel/grf/pkg/queries/filters"
+	"github.com/glothriel/grf/pkg/serializers"
+	"github.com/glothriel/grf/pkg/views"
+	"github.com/stretchr/testify/assert"
+)
+
+type ProductAttributes struct {
+	Color string   `json:"color"`
+	Sizes []string `json:"sizes"`
+}
+
+type AttributedProduct struct {
+	ID         uint                                `json:"id" gorm:"primaryKey"`
+	Attributes models.JSONField[ProductAttributes] `json:"attributes"`
+	Extra      *models.JSONField[map[string]any]   `json:"extra"`
+}
+
+func TestJSONField(t *testing.T) {
+	productFilters := func() *filters.FilterSet[AttributedProduct] {
+		return filters.NewFilterSet[AttributedProduct]().WithField("attributes").WithField("extra", filters.Gte)
+	}
+	for name, driver := range map[string]queries.Driver[AttributedProduct]{
+		"gorm": queries.GORM[AttributedProduct](
+			openSQLite(t, &AttributedProduct{}),
+		).WithFilterSet(productFilters()),
+		"inmemory": queries.InMemory[AttributedProduct]().WithFilterSet(productFilters()),
+	} {
+		t.Run(name, func(t *testing.T) {
+			// given
+			router := gin.New()
+			views.NewModelViewSet[AttributedProduct]("/products", driver).WithSerializer(
+				serializers.NewValidatingSerializer[AttributedProduct](
+					serializers.NewModelSerializer[AttributedProduct](),
+				).AddValidator(serializers.NewJSONSchemaFieldValidator("attributes", map[string]any{
+					"type": "object",
+					"properties": map[string]any{
+						"color": map[string]any{"enum": []string{"red", "blue"}},
+					},
+				})),
+			).Register(router)
+
+			for _, step := range []struct {
+				method   string
+				path     string
+				body     map[string]any
+				wantCode int
+				wantJSON string
+			}{
+				{"POST", "/products", map[string]any{
+					"attributes": map[string]any{"color": "red", "sizes": []string{"S", "M"}},
+					"extra":      map[string]any{"weight": 10},
+				}, 201, `{"id": 1, "attributes": {"color": "red", "sizes": ["S", "M"]}, "extra": {"weight": 10}}`},
+				{"POST", "/products", map[string]any{
+					"attributes": map[string]any{"color": "blue", "sizes": []string{}},
+					"extra":      nil,
+				}, 201, `{"id": 2, "attributes": {"color": "blue", "sizes": []}, "extra": null}`},
+				{"POST", "/products", map[string]any{"attributes": map[string]any{"color": "green"}, "extra": nil}, 400,
+					`{"errors": {"attributes.color": ["value must be one of \"red\", \"blue\""]}}`},
+				{"POST", "/products", map[string]any{"attributes": map[string]any{"colour": "red"}, "extra": nil}, 400, ""},
+				{"POST", "/products", map[string]any{"attributes": nil, "extra": nil}, 400,
+					`{"errors": {"attributes": ["Field ` + "`attributes`" + ` may not be null"]}}`},
+				{"GET", "/products/1", nil, 200,
+					`{"id": 1, "attributes": {"color": "red", "sizes": ["S", "M"]}, "extra": {"weight": 10}}`},
+				{"GET", "/products?attributes__color=blue", nil, 200,
+					`[{"id": 2, "attributes": {"color": "blue", "sizes": []}, "extra": null}]`},
+				{"GET", "/products?extra__weight__gte=5", nil, 200,
+					`[{"id": 1, "attributes": {"color": "red", "sizes": ["S", "M"]}, "extra": {"weight": 10}}]`},
+				{"GET", "/products?attributes=blue", nil, 400, ""},
+			} {
+				// when
+				w := httptest.NewRecorder()
+				router.ServeHTTP(w, newRequest(step.method, step.path, step.body))
+
+				// then
+				assert.Equal(t, step.wantCode, w.Code, "%s %s %v: %s", step.method, step.path, step.body, w.Body.String())
+				if step.wantJSON != "" {
+					assert.JSONEq(t, step.wantJSON, w.Body.String())
+				}
+			}
+		})
+	}
+}
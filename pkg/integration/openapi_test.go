@@ -0,0 +1,72 @@
+package integration
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/glothriel/grf/pkg/openapi"
+	"github.com/glothriel/grf/pkg/queries"
+	"github.com/glothriel/grf/pkg/queries/gormq"
+	"github.com/glothriel/grf/pkg/views"
+	"github.com/stretchr/testify/assert"
+)
+
+type Post struct {
+	ID    uint   `json:"id" gorm:"primaryKey"`
+	Title string `json:"title"`
+}
+
+func TestOpenAPIDescribesPaginatedListResponses(t *testing.T) {
+	results := map[string]any{"type": "array", "items": map[string]any{"$ref": "#/components/schemas/Post"}}
+	link := map[string]any{"type": []any{"string", "null"}, "format": "uri"}
+	tests := []struct {
+		name       string
+		pagination gormq.Pagination
+		want       map[string]any
+	}{
+		{"no pagination", &gormq.NoPagination{}, results},
+		{
+			"limit offset",
+			&gormq.LimitOffsetPagination{DefaultLimit: 10},
+			map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"count":    map[string]any{"type": "integer", "minimum": 0},
+					"next":     link,
+					"previous": link,
+					"results":  results,
+				},
+				"required": []string{"count", "next", "previous", "results"},
+			},
+		},
+		{
+			"cursor",
+			gormq.NewCursorPagination[Post]([]byte("secret"), "id"),
+			map[string]any{
+				"type":       "object",
+				"properties": map[string]any{"next": link, "previous": link, "results": results},
+				"required":   []string{"next", "previous", "results"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// given
+			registry := views.NewRegistry()
+			_, r := gin.CreateTestContext(httptest.NewRecorder())
+			views.NewModelViewSet[Post](
+				"/posts", queries.GORM[Post](openSQLite(t, &Post{})).WithPagination(tt.pagination),
+			).WithRegistry(registry).Register(r)
+
+			// when
+			document := openapi.Generate(registry, openapi.Info{Title: "Blog", Version: "1.0.0"})
+
+			// then
+			list := document["paths"].(map[string]any)["/posts"].(map[string]any)["get"].(map[string]any)
+			response := list["responses"].(map[string]any)["200"].(map[string]any)
+			schema := response["content"].(map[string]any)["application/json"].(map[string]any)["schema"]
+			assert.Equal(t, tt.want, schema)
+		})
+	}
+}
@@ -139,4 +139,25 @@ func TestRelations(t *testing.T) {
 			},
 		},
 	).Run(router)
+
+	newRequestTestCase(t, "relations on detail").Req(
+		newRequest("GET", fmt.Sprintf("/profiles/%s", profileIDs[1]), nil),
+	).ExCode(
+		http.StatusOK,
+	).ExJson(
+		map[string]any{
+			"name": "Roksana",
+			"photos": []any{
+				map[string]any{
+					"profile_id": profileIDs[1],
+				},
+				map[string]any{
+					"profile_id": profileIDs[1],
+				},
+				map[string]any{
+					"profile_id": profileIDs[1],
+				},
+			},
+		},
+	).Run(router)
 }
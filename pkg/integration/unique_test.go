@@ -0,0 +1,71 @@
+package integration
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/glothriel/grf/pkg/queries"
+	"github.com/glothriel/grf/pkg/serializers"
+	"github.com/glothriel/grf/pkg/views"
+	"github.com/stretchr/testify/assert"
+)
+
+type Article struct {
+	ID     uint   `json:"id" gorm:"primaryKey"`
+	Email  string `json:"email"`
+	Tenant string `json:"tenant"`
+	Slug   string `json:"slug"`
+}
+
+func TestUniqueValidators(t *testing.T) {
+	for name, driver := range map[string]queries.Driver[Article]{
+		"gorm":     queries.GORM[Article](openSQLite(t, &Article{})),
+		"inmemory": queries.InMemory[Article](),
+	} {
+		t.Run(name, func(t *testing.T) {
+			// given
+			router := gin.New()
+			views.NewModelViewSet[Article]("/articles", driver).WithSerializer(
+				serializers.NewValidatingSerializer[Article](serializers.NewModelSerializer[Article]()).AddContextValidator(
+					serializers.UniqueValidator("email"),
+				).AddContextValidator(
+					serializers.UniqueTogetherValidator("tenant", "slug"),
+				),
+			).Register(router)
+			uniqueSet := "The fields `tenant`, `slug` must make a unique set"
+
+			for _, step := range []struct {
+				method   string
+				path     string
+				body     map[string]any
+				wantCode int
+				wantJSON string
+			}{
+				{"POST", "/articles", map[string]any{"email": "a", "tenant": "t1", "slug": "s"}, 201, ""},
+				{"POST", "/articles", map[string]any{"email": "a", "tenant": "t2", "slug": "s"}, 400,
+					`{"errors": {"email": ["already exists"]}}`},
+				{"POST", "/articles", map[string]any{"email": "b", "tenant": "t1", "slug": "s"}, 400,
+					`{"errors": {"tenant": ["` + uniqueSet + `"], "slug": ["` + uniqueSet + `"]}}`},
+				{"POST", "/articles", map[string]any{"email": "a", "tenant": "t1", "slug": "s"}, 400,
+					`{"errors": {"email": ["already exists"], "tenant": ["` + uniqueSet + `"], "slug": ["` + uniqueSet + `"]}}`},
+				{"POST", "/articles", map[string]any{"email": "b", "tenant": "t2", "slug": "s"}, 201, ""},
+				{"PUT", "/articles/1", map[string]any{"email": "a", "tenant": "t1", "slug": "s"}, 200, ""},
+				{"PATCH", "/articles/2", map[string]any{"tenant": "t1"}, 400,
+					`{"errors": {"tenant": ["` + uniqueSet + `"], "slug": ["` + uniqueSet + `"]}}`},
+				{"PATCH", "/articles/2", map[string]any{"email": "a"}, 400, `{"errors": {"email": ["already exists"]}}`},
+				{"PATCH", "/articles/2", map[string]any{"email": "c", "tenant": "t3"}, 200, ""},
+			} {
+				// when
+				w := httptest.NewRecorder()
+				router.ServeHTTP(w, newRequest(step.method, step.path, step.body))
+
+				// then
+				assert.Equal(t, step.wantCode, w.Code, "%s %s %v: %s", step.method, step.path, step.body, w.Body.String())
+				if step.wantJSON != "" {
+					assert.JSONEq(t, step.wantJSON, w.Body.String())
+				}
+			}
+		})
+	}
+}
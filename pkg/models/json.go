@@ -0,0 +1,109 @@
+package models
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// JSONDocument is implemented by the fields stored as JSON documents, the filters use it to allow
+// lookups on the keys of the document.
+type JSONDocument interface {
+	// JSONData returns the stored document
+	JSONData() any
+}
+
+// JSONField is a field that stores any JSON serializable value, usually a struct or a map, in a JSON
+// column: `jsonb` on Postgres and `JSON` on other databases. In request and response JSON payloads the
+// value is represented as nested JSON.
+type JSONField[T any] struct {
+	Data T
+}
+
+// NewJSONField wraps the value in a JSONField
+func NewJSONField[T any](data T) JSONField[T] {
+	return JSONField[T]{Data: data}
+}
+
+// JSONData returns the wrapped value, implements JSONDocument interface
+func (j JSONField[T]) JSONData() any {
+	return j.Data
+}
+
+func (j *JSONField[T]) FromRepresentation(rawValue any) error {
+	encoded, marshalErr := json.Marshal(rawValue)
+	if marshalErr != nil {
+		return fmt.Errorf("Is not a valid JSON value: %s", marshalErr)
+	}
+	var data T
+	decoder := json.NewDecoder(bytes.NewReader(encoded))
+	decoder.DisallowUnknownFields()
+	if decodeErr := decoder.Decode(&data); decodeErr != nil {
+		return fmt.Errorf("Is not a valid %T: %s", data, decodeErr)
+	}
+	j.Data = data
+	return nil
+}
+
+func (j JSONField[T]) ToRepresentation() (any, error) {
+	return j.Data, nil
+}
+
+// MarshalJSON encodes the wrapped value, implements json.Marshaler interface
+func (j JSONField[T]) MarshalJSON() ([]byte, error) {
+	return json.Marshal(j.Data)
+}
+
+// UnmarshalJSON decodes the wrapped value, implements json.Unmarshaler interface
+func (j *JSONField[T]) UnmarshalJSON(data []byte) error {
+	return json.Unmarshal(data, &j.Data)
+}
+
+// Scan scan value into the field, implements sql.Scanner interface
+func (j *JSONField[T]) Scan(value any) error {
+	var encoded []byte
+	switch v := value.(type) {
+	case nil:
+		var zero T
+		j.Data = zero
+		return nil
+	case []byte:
+		encoded = v
+	case string:
+		encoded = []byte(v)
+	default:
+		return fmt.Errorf("Failed to parse the value from database: is not bytes or string: %v", value)
+	}
+	var data T
+	if unmarshalErr := json.Unmarshal(encoded, &data); unmarshalErr != nil {
+		return unmarshalErr
+	}
+	j.Data = data
+	return nil
+}
+
+// Value return json value, implement driver.Valuer interface
+func (j JSONField[T]) Value() (driver.Value, error) {
+	encoded, marshalErr := json.Marshal(j.Data)
+	if marshalErr != nil {
+		return nil, marshalErr
+	}
+	return string(encoded), nil
+}
+
+// GormDataType returns the generic data type of the column, implements schema.GormDataTypeInterface
+func (JSONField[T]) GormDataType() string {
+	return "json"
+}
+
+// GormDBDataType returns the column type for the database, implements migrator.GormDataTypeInterface
+func (JSONField[T]) GormDBDataType(db *gorm.DB, _ *schema.Field) string {
+	if db.Dialector.Name() == "postgres" {
+		return "jsonb"
+	}
+	return "JSON"
+}
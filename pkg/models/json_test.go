@@ -0,0 +1,121 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type mockAttributes struct {
+	Color string   `json:"color"`
+	Tags  []string `json:"tags"`
+}
+
+func TestJSONFieldFromRepresentation(t *testing.T) {
+	// given
+	var j JSONField[mockAttributes]
+
+	// when
+	err := j.FromRepresentation(map[string]any{"color": "red", "tags": []any{"a", "b"}})
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, mockAttributes{Color: "red", Tags: []string{"a", "b"}}, j.Data)
+}
+
+func TestJSONFieldFromRepresentationMap(t *testing.T) {
+	// given
+	var j JSONField[map[string]any]
+
+	// when
+	err := j.FromRepresentation(map[string]any{"nested": map[string]any{"foo": 1.0}})
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]any{"nested": map[string]any{"foo": 1.0}}, j.Data)
+}
+
+func TestJSONFieldFromRepresentationError(t *testing.T) {
+	tests := []struct {
+		name  string
+		value any
+	}{
+		{"not an object", "red"},
+		{"wrong type of a key", map[string]any{"color": 1}},
+		{"unknown key", map[string]any{"colour": "red"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var j JSONField[mockAttributes]
+			assert.Error(t, j.FromRepresentation(tt.value))
+		})
+	}
+}
+
+func TestJSONFieldToRepresentation(t *testing.T) {
+	// given
+	j := NewJSONField(mockAttributes{Color: "red"})
+
+	// when
+	value, err := j.ToRepresentation()
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, mockAttributes{Color: "red"}, value)
+}
+
+func TestJSONFieldMarshalsAsNestedJSON(t *testing.T) {
+	// given
+	j := NewJSONField(mockAttributes{Color: "red", Tags: []string{"a"}})
+
+	// when
+	encoded, err := json.Marshal(map[string]any{"attributes": j})
+
+	// then
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"attributes": {"color": "red", "tags": ["a"]}}`, string(encoded))
+}
+
+func TestJSONFieldScan(t *testing.T) {
+	tests := []struct {
+		name  string
+		value any
+		want  mockAttributes
+	}{
+		{"bytes", []byte(`{"color": "red"}`), mockAttributes{Color: "red"}},
+		{"string", `{"color": "red", "tags": ["a"]}`, mockAttributes{Color: "red", Tags: []string{"a"}}},
+		{"NULL", nil, mockAttributes{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// given
+			j := NewJSONField(mockAttributes{Color: "blue"})
+
+			// when
+			err := j.Scan(tt.value)
+
+			// then
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, j.Data)
+		})
+	}
+}
+
+func TestJSONFieldScanError(t *testing.T) {
+	var j JSONField[mockAttributes]
+	assert.Error(t, j.Scan(1))
+	assert.Error(t, j.Scan([]byte(`{"color": `)))
+}
+
+func TestJSONFieldValue(t *testing.T) {
+	// given
+	j := NewJSONField(map[string]any{"color": "red"})
+
+	// when
+	value, err := j.Value()
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, `{"color":"red"}`, value)
+}
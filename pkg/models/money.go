@@ -0,0 +1,165 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// Money is an amount in a given currency. In request and response JSON payloads it's represented as
+// `{"amount": "12.30", "currency": "USD"}`, the amount is always formatted using the minor units of the
+// currency. It's stored as a JSON document, like JSONField.
+type Money struct {
+	Amount   decimal.Decimal `json:"amount"`
+	Currency string          `json:"currency"`
+}
+
+// NewMoney creates Money with the amount in the currency, eg. NewMoney(decimal.RequireFromString("12.3"), "USD")
+func NewMoney(amount decimal.Decimal, currency string) Money {
+	return Money{Amount: amount, Currency: currency}
+}
+
+// String formats the money, eg. `12.30 USD`
+func (m Money) String() string {
+	return fmt.Sprintf("%s %s", m.formattedAmount(), m.Currency)
+}
+
+func (m Money) formattedAmount() string {
+	if minorUnits, ok := currencyMinorUnits[m.Currency]; ok {
+		return m.Amount.StringFixed(minorUnits)
+	}
+	return m.Amount.String()
+}
+
+// FromRepresentation parses the money from a JSON object. The amount has to be a string or a json.Number,
+// float64 is rejected, as it might have already lost the precision.
+func (m *Money) FromRepresentation(rawValue any) error {
+	rawMap, ok := rawValue.(map[string]any)
+	if !ok {
+		return errors.New("Is not an object with `amount` and `currency`")
+	}
+	for key := range rawMap {
+		if key != "amount" && key != "currency" {
+			return fmt.Errorf("Unknown key `%s`, only `amount` and `currency` are accepted", key)
+		}
+	}
+	currency, ok := rawMap["currency"].(string)
+	if !ok {
+		return errors.New("`currency` has to be a string")
+	}
+	minorUnits, ok := currencyMinorUnits[currency]
+	if !ok {
+		return fmt.Errorf("`%s` is not a valid ISO 4217 currency code", currency)
+	}
+	var literal string
+	switch v := rawMap["amount"].(type) {
+	case string:
+		literal = v
+	case json.Number:
+		literal = v.String()
+	default:
+		return fmt.Errorf("`amount` has to be a string, eg. \"12.30\", got `%T`", rawMap["amount"])
+	}
+	amount, parseErr := decimal.NewFromString(literal)
+	if parseErr != nil {
+		return fmt.Errorf("`%s` is not a valid amount", literal)
+	}
+	if !amount.Equal(amount.Round(minorUnits)) {
+		return fmt.Errorf("`%s` has more than %d decimal places allowed for %s", literal, minorUnits, currency)
+	}
+	m.Amount = amount
+	m.Currency = currency
+	return nil
+}
+
+func (m Money) ToRepresentation() (any, error) {
+	return map[string]any{"amount": m.formattedAmount(), "currency": m.Currency}, nil
+}
+
+// Scan scan value into the money, implements sql.Scanner interface
+func (m *Money) Scan(value any) error {
+	var encoded []byte
+	switch v := value.(type) {
+	case nil:
+		*m = Money{}
+		return nil
+	case []byte:
+		encoded = v
+	case string:
+		encoded = []byte(v)
+	default:
+		return fmt.Errorf("Failed to parse the value from database: is not bytes or string: %v", value)
+	}
+	var stored Money
+	if unmarshalErr := json.Unmarshal(encoded, &stored); unmarshalErr != nil {
+		return unmarshalErr
+	}
+	*m = stored
+	return nil
+}
+
+// Value return json value, implement driver.Valuer interface
+func (m Money) Value() (driver.Value, error) {
+	encoded, marshalErr := json.Marshal(m)
+	if marshalErr != nil {
+		return nil, marshalErr
+	}
+	return string(encoded), nil
+}
+
+// GormDataType returns the generic data type of the column, implements schema.GormDataTypeInterface
+func (Money) GormDataType() string {
+	return "json"
+}
+
+// GormDBDataType returns the column type for the database, implements migrator.GormDataTypeInterface
+func (Money) GormDBDataType(db *gorm.DB, _ *schema.Field) string {
+	if db.Dialector.Name() == "postgres" {
+		return "jsonb"
+	}
+	return "JSON"
+}
+
+// RegisterCurrency adds a currency accepted by Money, or changes the minor units of a known one. It's meant
+// to be called during the initialization, for example to support currencies added to ISO 4217 later.
+func RegisterCurrency(code string, minorUnits int32) {
+	currencyMinorUnits[code] = minorUnits
+}
+
+// Currencies returns the sorted codes of the currencies accepted by Money
+func Currencies() []string {
+	codes := make([]string, 0, len(currencyMinorUnits))
+	for code := range currencyMinorUnits {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	return codes
+}
+
+// currencyMinorUnits maps active ISO 4217 currency codes to the number of their decimal places
+var currencyMinorUnits = func() map[string]int32 {
+	units := map[string]int32{
+		"BHD": 3, "IQD": 3, "JOD": 3, "KWD": 3, "LYD": 3, "OMR": 3, "TND": 3,
+		"BIF": 0, "CLP": 0, "DJF": 0, "GNF": 0, "ISK": 0, "JPY": 0, "KMF": 0, "KRW": 0, "PYG": 0, "RWF": 0,
+		"UGX": 0, "UYI": 0, "VND": 0, "VUV": 0, "XAF": 0, "XOF": 0, "XPF": 0,
+		"CLF": 4, "UYW": 4,
+	}
+	for _, code := range strings.Fields(`
+		AED AFN ALL AMD ANG AOA ARS AUD AWG AZN BAM BBD BDT BGN BMD BND BOB BOV BRL BSD BTN BWP BYN BZD
+		CAD CDF CHE CHF CHW CNY COP COU CRC CUP CVE CZK DKK DOP DZD EGP ERN ETB EUR FJD FKP GBP GEL GHS
+		GIP GMD GTQ GYD HKD HNL HTG HUF IDR ILS INR IRR JMD KES KGS KHR KPW KYD KZT LAK LBP LKR LRD LSL
+		MAD MDL MGA MKD MMK MNT MOP MRU MUR MVR MWK MXN MXV MYR MZN NAD NGN NIO NOK NPR NZD PAB PEN PGK
+		PHP PKR PLN QAR RON RSD RUB SAR SBD SCR SDG SEK SGD SHP SLE SOS SRD SSP STN SVC SYP SZL THB TJS
+		TMT TOP TRY TTD TWD TZS UAH USD USN UYU UZS VED VES WST XCD XCG YER ZAR ZMW ZWG
+	`) {
+		units[code] = 2
+	}
+	return units
+}()
@@ -0,0 +1,103 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMoneyFromRepresentation(t *testing.T) {
+	tests := []struct {
+		name      string
+		value     any
+		wantMoney Money
+		wantErr   string
+	}{
+		{"string amount", map[string]any{"amount": "12.3", "currency": "USD"}, NewMoney(decimal.RequireFromString("12.3"), "USD"), ""},
+		{"number amount", map[string]any{"amount": json.Number("1000"), "currency": "JPY"}, NewMoney(decimal.RequireFromString("1000"), "JPY"), ""},
+		{"trailing zeros", map[string]any{"amount": "1.5000", "currency": "EUR"}, NewMoney(decimal.RequireFromString("1.5"), "EUR"), ""},
+		{"not an object", "12.30 USD", Money{}, "Is not an object with `amount` and `currency`"},
+		{"unknown key", map[string]any{"amount": "1", "currency": "USD", "rate": "1"}, Money{}, "Unknown key `rate`, only `amount` and `currency` are accepted"},
+		{"missing currency", map[string]any{"amount": "1"}, Money{}, "`currency` has to be a string"},
+		{"unknown currency", map[string]any{"amount": "1", "currency": "XYZ"}, Money{}, "`XYZ` is not a valid ISO 4217 currency code"},
+		{"float amount", map[string]any{"amount": 12.3, "currency": "USD"}, Money{}, "`amount` has to be a string, eg. \"12.30\", got `float64`"},
+		{"invalid amount", map[string]any{"amount": "12,3", "currency": "USD"}, Money{}, "`12,3` is not a valid amount"},
+		{"too many decimal places", map[string]any{"amount": "1.5", "currency": "JPY"}, Money{}, "`1.5` has more than 0 decimal places allowed for JPY"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// given
+			var m Money
+
+			// when
+			err := m.FromRepresentation(tt.value)
+
+			// then
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.True(t, tt.wantMoney.Amount.Equal(m.Amount))
+			assert.Equal(t, tt.wantMoney.Currency, m.Currency)
+		})
+	}
+}
+
+func TestMoneyToRepresentation(t *testing.T) {
+	// given
+	m := NewMoney(decimal.RequireFromString("12.3"), "BHD")
+
+	// when
+	repr, err := m.ToRepresentation()
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]any{"amount": "12.300", "currency": "BHD"}, repr)
+	assert.Equal(t, "12.300 BHD", m.String())
+}
+
+func TestMoneyScanAndValue(t *testing.T) {
+	// given
+	m := NewMoney(decimal.RequireFromString("0.30000000000000000001"), "USD")
+
+	// when
+	value, valueErr := m.Value()
+	var scanned Money
+	scanErr := scanned.Scan([]byte(value.(string)))
+
+	// then
+	assert.NoError(t, valueErr)
+	assert.NoError(t, scanErr)
+	assert.True(t, m.Amount.Equal(scanned.Amount))
+	assert.Equal(t, "USD", scanned.Currency)
+}
+
+func TestMoneyScanNil(t *testing.T) {
+	// given
+	m := NewMoney(decimal.RequireFromString("1"), "USD")
+
+	// when
+	err := m.Scan(nil)
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, Money{}, m)
+}
+
+func TestRegisterCurrency(t *testing.T) {
+	// given
+	RegisterCurrency("XTS", 3)
+	defer delete(currencyMinorUnits, "XTS")
+	var m Money
+
+	// when
+	err := m.FromRepresentation(map[string]any{"amount": "1.125", "currency": "XTS"})
+
+	// then
+	assert.NoError(t, err)
+	assert.Contains(t, Currencies(), "XTS")
+	assert.Equal(t, "1.125 XTS", m.String())
+}
@@ -2,36 +2,150 @@ package models
 
 import (
 	"database/sql/driver"
+	"encoding"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/glothriel/grf/pkg/types"
 )
 
-// SliceField is a field that represents a slice of any type
+// SliceField is a field that represents a slice of any type. The elements are converted from their JSON
+// representation using the types.FieldTypeMapper of the serializer, or encoding.TextUnmarshaler, so for
+// example SliceField[int], SliceField[uuid.UUID] or SliceField[time.Time] can be used.
 type SliceField[T any] []T
 
+// MapperParsable is implemented by the fields converting their elements using types.FieldTypeMapper, the
+// serializers pass their mapper to them instead of calling FromRepresentation
+type MapperParsable interface {
+	FromRepresentationWithMapper(rawValue any, mapper *types.FieldTypeMapper) error
+}
+
+// MapperRepresentable is the counterpart of MapperParsable, used instead of ToRepresentation
+type MapperRepresentable interface {
+	ToRepresentationWithMapper(mapper *types.FieldTypeMapper) (any, error)
+}
+
+// FromRepresentation converts the elements using the default types.FieldTypeMapper
 func (s *SliceField[T]) FromRepresentation(rawValue any) error {
+	return s.FromRepresentationWithMapper(rawValue, types.Mapper())
+}
+
+// FromRepresentationWithMapper converts the elements using the mapper, implements MapperParsable
+func (s *SliceField[T]) FromRepresentationWithMapper(rawValue any, mapper *types.FieldTypeMapper) error {
 	rawValueSlice, ok := rawValue.([]any)
 	if !ok {
 		return errors.New("Is not a collection")
 	}
-	var correctTypeSlice []T
+	correctTypeSlice := make([]T, 0, len(rawValueSlice))
+	elementErrs := ElementErrors{}
 	for i, v := range rawValueSlice {
-		typedValue, ok := v.(T)
-		if !ok {
-			var t T
-			return fmt.Errorf("[%d] is not a valid %T", i, t)
+		typedValue, convertErr := convertElement[T](v, mapper)
+		if convertErr != nil {
+			elementErrs[i] = convertErr.Error()
+			continue
 		}
 		correctTypeSlice = append(correctTypeSlice, typedValue)
 	}
+	if len(elementErrs) > 0 {
+		return elementErrs
+	}
 	*s = correctTypeSlice
 	return nil
 }
 
+// convertElement converts the element decoded from JSON to the type of the slice. The types overridden in the
+// mapper are always converted using it.
+func convertElement[T any](v any, mapper *types.FieldTypeMapper) (T, error) {
+	var t T
+	typeName := reflect.TypeOf(&t).Elem().String()
+	if typedValue, ok := v.(T); ok && !mapper.Overrides(typeName) {
+		return typedValue, nil
+	}
+	var mapperErr error
+	if convert, noConverterErr := mapper.ToInternalValue(typeName); noConverterErr == nil {
+		converted, convertErr := convert(v)
+		if typedValue, ok := converted.(T); convertErr == nil && ok {
+			return typedValue, nil
+		}
+		mapperErr = convertErr
+	}
+	if unmarshaler, ok := any(&t).(encoding.TextUnmarshaler); ok {
+		vStr, isString := v.(string)
+		if !isString {
+			return t, fmt.Errorf("Is not a valid %s: expected a string, got `%T`", typeName, v)
+		}
+		if unmarshalErr := unmarshaler.UnmarshalText([]byte(vStr)); unmarshalErr != nil {
+			return t, fmt.Errorf("Is not a valid %s: %s", typeName, unmarshalErr)
+		}
+		return t, nil
+	}
+	if mapperErr != nil {
+		return t, mapperErr
+	}
+	return t, fmt.Errorf("Is not a valid %s", typeName)
+}
+
+// ElementErrors is returned by the collection fields, like SliceField, and maps the indexes of the invalid
+// elements to the error messages. Serializers report them using the paths of the elements, eg. `tags.2`.
+type ElementErrors map[int]string
+
+func (e ElementErrors) Error() string {
+	indexes := make([]int, 0, len(e))
+	for i := range e {
+		indexes = append(indexes, i)
+	}
+	sort.Ints(indexes)
+	messages := make([]string, 0, len(indexes))
+	for _, i := range indexes {
+		messages = append(messages, fmt.Sprintf("[%d] %s", i, e[i]))
+	}
+	return strings.Join(messages, "; ")
+}
+
 func (s SliceField[T]) ToRepresentation() (any, error) {
 	return s, nil
 }
 
+// ToRepresentationWithMapper represents the elements of the types overridden in the mapper using it, the other
+// elements are left as they are, implements MapperRepresentable
+func (s SliceField[T]) ToRepresentationWithMapper(mapper *types.FieldTypeMapper) (any, error) {
+	var t T
+	typeName := reflect.TypeOf(&t).Elem().String()
+	if s == nil || !mapper.Overrides(typeName) {
+		return s, nil
+	}
+	convert, noConverterErr := mapper.ToRepresentation(typeName)
+	if noConverterErr != nil {
+		return nil, noConverterErr
+	}
+	elements := make([]any, len(s))
+	for i, element := range s {
+		converted, convertErr := convert(element)
+		if convertErr != nil {
+			return nil, convertErr
+		}
+		elements[i] = converted
+	}
+	return elements, nil
+}
+
+// MarshalJSON encodes the slice as a JSON array, also for SliceField[uint8], which encoding/json would encode
+// as a base64 string, implements json.Marshaler interface
+func (s SliceField[T]) MarshalJSON() ([]byte, error) {
+	if s == nil {
+		return []byte("null"), nil
+	}
+	elements := make([]any, len(s))
+	for i, element := range s {
+		elements[i] = element
+	}
+	return json.Marshal(elements)
+}
+
 // Scan scan value into Jsonb, implements sql.Scanner interface
 func (s *SliceField[T]) Scan(value any) error {
 	bytes, ok := value.([]byte)
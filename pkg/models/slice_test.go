@@ -1,8 +1,11 @@
 package models
 
 import (
+	"encoding/json"
 	"testing"
+	"time"
 
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -30,6 +33,77 @@ func TestSliceModelFieldFromRepresentationNoError(t *testing.T) {
 
 }
 
+func TestSliceModelFieldFromRepresentationConvertsElements(t *testing.T) {
+	ref := uuid.MustParse("4c2b9e4e-7a3c-4a3e-9f3b-0b6a6c2e1b7d")
+	FromRepresentationSuccessTestCase(t, []any{1.0, -2.0, 3.0}, []int{1, -2, 3})
+	FromRepresentationSuccessTestCase(t, []any{0.0, 255.0}, []uint8{0, 255})
+	FromRepresentationSuccessTestCase(t, []any{float64(1 << 40)}, []int64{1 << 40})
+	FromRepresentationSuccessTestCase(t, []any{ref.String()}, []uuid.UUID{ref})
+	FromRepresentationSuccessTestCase(
+		t, []any{"2021-01-01T00:00:00Z"}, []time.Time{time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)},
+	)
+}
+
+func TestSliceModelFieldFromRepresentationElementErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		convert func() error
+		want    ElementErrors
+	}{
+		{
+			name: "not an integer",
+			convert: func() error {
+				var s SliceField[int]
+				return s.FromRepresentation([]any{1.0, 2.5, "3"})
+			},
+			want: ElementErrors{
+				1: "Error converting request value to internal value for type `int`: Value 2.500000 is not an integer",
+				2: "Error converting request value to internal value for type `int`: Expected type `float64`, got `string`",
+			},
+		},
+		{
+			name: "negative unsigned",
+			convert: func() error {
+				var s SliceField[uint]
+				return s.FromRepresentation([]any{-1.0})
+			},
+			want: ElementErrors{
+				0: "Error converting request value to internal value for type `uint`: Value -1.000000 is not an integer",
+			},
+		},
+		{
+			name: "out of range",
+			convert: func() error {
+				var s SliceField[uint8]
+				return s.FromRepresentation([]any{256.0})
+			},
+			want: ElementErrors{
+				0: "Error converting request value to internal value for type `uint8`: Value 256.000000 is out of range for type `uint8`",
+			},
+		},
+		{
+			name: "invalid text",
+			convert: func() error {
+				var s SliceField[uuid.UUID]
+				return s.FromRepresentation([]any{"nope", 1.0})
+			},
+			want: ElementErrors{
+				0: "Is not a valid uuid.UUID: invalid UUID length: 4",
+				1: "Is not a valid uuid.UUID: expected a string, got `float64`",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.convert())
+		})
+	}
+}
+
+func TestElementErrorsError(t *testing.T) {
+	assert.EqualError(t, ElementErrors{2: "invalid", 0: "too long"}, "[0] too long; [2] invalid")
+}
+
 func TestSliceModelFieldFromRepresentationError(t *testing.T) {
 	FromRepresentationErrorTestCase[int](t, []any{1, 2, "3"})
 	FromRepresentationErrorTestCase[string](t, []any{1, 2, 3})
@@ -96,6 +170,22 @@ func TestSliceModelFieldValue(t *testing.T) {
 	assert.Equal(t, []byte(`[1,2,3]`), value)
 }
 
+func TestSliceModelFieldMarshalsBytesAsArray(t *testing.T) {
+	// given
+	s := SliceField[uint8]{0, 255}
+
+	// when
+	encoded, marshalErr := json.Marshal(s)
+	var decoded SliceField[uint8]
+	unmarshalErr := json.Unmarshal(encoded, &decoded)
+
+	// then
+	assert.NoError(t, marshalErr)
+	assert.NoError(t, unmarshalErr)
+	assert.Equal(t, `[0,255]`, string(encoded))
+	assert.Equal(t, s, decoded)
+}
+
 func TestSliceModelFieldValueEmpty(t *testing.T) {
 	// given
 	s := SliceField[int]{}
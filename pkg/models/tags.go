@@ -10,6 +10,26 @@ const tagID = "grf"
 // TagIsRelation is a tag that indicates that the field is a relation.
 const TagIsRelation = "relation"
 
+// TagRequired marks the field as required when creating an entity, eg. `grf:"required"`
+const TagRequired = "required"
+
+// TagAllowNull overrides whether `null` is accepted for the field, eg. `grf:"allow_null:false"`
+const TagAllowNull = "allow_null"
+
+// TagAllowBlank overrides whether an empty string is accepted for the field, eg. `grf:"allow_blank:false"`
+const TagAllowBlank = "allow_blank"
+
+// TagDecimalFormat sets the wire format of decimal.Decimal fields, `string` (default) or `number`,
+// eg. `grf:"decimal_format:number"`
+const TagDecimalFormat = "decimal_format"
+
+// TagMaxDigits limits the total number of digits of decimal.Decimal fields, eg. `grf:"max_digits:8"`
+const TagMaxDigits = "max_digits"
+
+// TagDecimalPlaces limits the number of decimal places of decimal.Decimal fields, which are also always
+// represented with this number of decimal places, eg. `grf:"decimal_places:2"`
+const TagDecimalPlaces = "decimal_places"
+
 // ParseTag parses the tag and returns a map of key-value pairs.
 // Forma: `grf:"key1:value1;key2:value2"`
 func ParseTag(f reflect.StructField) map[string]string {
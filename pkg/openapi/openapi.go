@@ -0,0 +1,430 @@
+package openapi
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/glothriel/grf/pkg/fields"
+	"github.com/glothriel/grf/pkg/queries/common"
+	"github.com/glothriel/grf/pkg/serializers"
+	"github.com/glothriel/grf/pkg/views"
+)
+
+// Version is the OpenAPI specification version of the generated documents
+const Version = "3.1.0"
+
+// Info is the metadata of the API, rendered in the `info` section of the document
+type Info struct {
+	Title       string
+	Version     string
+	Description string
+}
+
+// Generate builds an OpenAPI document describing all the ViewSets added to the registry
+func Generate(registry *views.Registry, info Info) map[string]any {
+	g := &generator{
+		paths:        map[string]any{},
+		schemas:      map[string]any{},
+		schemaNames:  map[any]string{},
+		operationIDs: map[string]bool{},
+	}
+	for _, viewSet := range registry.ViewSets() {
+		g.addViewSet(viewSet)
+	}
+	g.schemas["ValidationError"] = map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"errors": map[string]any{
+				"type": "object",
+				"additionalProperties": map[string]any{
+					"type":  "array",
+					"items": map[string]any{"type": "string"},
+				},
+			},
+		},
+	}
+	g.schemas["Error"] = map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"message": map[string]any{"type": "string"},
+		},
+	}
+
+	infoSection := map[string]any{"title": info.Title, "version": info.Version}
+	if info.Description != "" {
+		infoSection["description"] = info.Description
+	}
+	return map[string]any{
+		"openapi":    Version,
+		"info":       infoSection,
+		"paths":      g.paths,
+		"components": map[string]any{"schemas": g.schemas},
+	}
+}
+
+// Handler returns a gin handler serving the OpenAPI document. The document is generated on every
+// request, so it includes ViewSets registered after the handler was created.
+func Handler(registry *views.Registry, info Info) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		ctx.JSON(http.StatusOK, Generate(registry, info))
+	}
+}
+
+type generator struct {
+	paths   map[string]any
+	schemas map[string]any
+	// schemaNames holds component names of already described serializers, so the serializers
+	// shared between actions are described only once
+	schemaNames  map[any]string
+	operationIDs map[string]bool
+}
+
+func (g *generator) addViewSet(viewSet *views.ViewSetDescription) {
+	// The default serializer is described first, so it gets the name of the model
+	if viewSet.DefaultSerializer != nil {
+		g.schemaRef(viewSet, viewSet.DefaultSerializer, "")
+	}
+	for _, action := range viewSet.Actions {
+		openAPIPath, params := convertPath(action.Path)
+		pathItem, ok := g.paths[openAPIPath].(map[string]any)
+		if !ok {
+			pathItem = map[string]any{}
+			g.paths[openAPIPath] = pathItem
+		}
+		if len(params) > 0 {
+			parameters := []any{}
+			for _, param := range params {
+				parameters = append(parameters, map[string]any{
+					"name":     param,
+					"in":       "path",
+					"required": true,
+					"schema":   pathParamSchema(viewSet, param),
+				})
+			}
+			pathItem["parameters"] = parameters
+		}
+		pathItem[strings.ToLower(action.Method)] = g.operation(viewSet, action)
+	}
+}
+
+// pathParamSchema describes the ID param using the type of the `id` field of the model. The types of other
+// params, like the IDs of parent entities on nested routes, are unknown, so they are described as strings.
+func pathParamSchema(viewSet *views.ViewSetDescription, param string) map[string]any {
+	if param == viewSet.IDParam {
+		schema := SchemaForGoType(strings.TrimPrefix(viewSet.FieldTypes["id"], "*"))
+		if _, ok := schema["type"].(string); ok {
+			return schema
+		}
+	}
+	return map[string]any{"type": "string"}
+}
+
+func (g *generator) operation(viewSet *views.ViewSetDescription, action views.ActionDescription) map[string]any {
+	operation := map[string]any{
+		"operationId": g.operationID(viewSet, action),
+		"tags":        []any{viewSet.ModelName},
+		"responses":   map[string]any{},
+	}
+	responses := operation["responses"].(map[string]any)
+	schemaRef := g.schemaRef(viewSet, action.Serializer, action.Name)
+
+	switch action.ID {
+	case views.ActionList:
+		responses["200"] = jsonResponse("OK", listSchema(viewSet.PaginationEnvelope, schemaRef))
+	case views.ActionCreate:
+		operation["requestBody"] = jsonRequestBody(schemaRef)
+		responses["201"] = jsonResponse("Created", schemaRef)
+		responses["400"] = jsonResponse("Bad Request", ref("ValidationError"))
+	case views.ActionRetrieve:
+		responses["200"] = jsonResponse("OK", schemaRef)
+		responses["404"] = jsonResponse("Not Found", ref("Error"))
+	case views.ActionUpdate, views.ActionPartialUpdate:
+		if action.ID == views.ActionPartialUpdate {
+			operation["requestBody"] = jsonRequestBody(g.partialSchemaRef(schemaRef))
+		} else {
+			operation["requestBody"] = jsonRequestBody(g.updateSchemaRef(action.Serializer, schemaRef))
+		}
+		responses["200"] = jsonResponse("OK", schemaRef)
+		responses["400"] = jsonResponse("Bad Request", ref("ValidationError"))
+		responses["404"] = jsonResponse("Not Found", ref("Error"))
+	case views.ActionDestroy:
+		responses["204"] = map[string]any{"description": "No Content"}
+		responses["404"] = jsonResponse("Not Found", ref("Error"))
+	default:
+		if action.Method == http.MethodPost || action.Method == http.MethodPut || action.Method == http.MethodPatch {
+			operation["requestBody"] = jsonRequestBody(schemaRef)
+		}
+		responses["default"] = jsonResponse("Response", schemaRef)
+	}
+	restricted := !isAllowAny(action.Permission)
+	if action.Authenticated || restricted {
+		responses["401"] = jsonResponse("Unauthorized", ref("Error"))
+	}
+	if restricted {
+		responses["403"] = jsonResponse("Forbidden", ref("Error"))
+	}
+	return operation
+}
+
+func isAllowAny(permission views.Permission) bool {
+	switch permission.(type) {
+	case nil, views.AllowAny, *views.AllowAny:
+		return true
+	}
+	return false
+}
+
+func (g *generator) operationID(viewSet *views.ViewSetDescription, action views.ActionDescription) string {
+	base := fmt.Sprintf("%s_%s", action.Name, strings.ToLower(viewSet.ModelName))
+	if action.ID == views.ActionExtra {
+		base = fmt.Sprintf("%s_%s", strings.ToLower(action.Method), base)
+	}
+	operationID := base
+	for i := 2; g.operationIDs[operationID]; i++ {
+		operationID = fmt.Sprintf("%s_%d", base, i)
+	}
+	g.operationIDs[operationID] = true
+	return operationID
+}
+
+// schemaRef describes the serializer as a component schema and returns a reference to it
+func (g *generator) schemaRef(
+	viewSet *views.ViewSetDescription, serializer serializers.Serializer, actionName string,
+) map[string]any {
+	key := serializerKey(serializer)
+	if name, ok := g.schemaNames[key]; ok && key != nil {
+		return ref(name)
+	}
+	name := viewSet.ModelName
+	if _, taken := g.schemas[name]; taken {
+		name = viewSet.ModelName + camelCase(actionName)
+	}
+	for i := 2; g.schemas[name] != nil; i++ {
+		name = fmt.Sprintf("%s%s%d", viewSet.ModelName, camelCase(actionName), i)
+	}
+	g.schemas[name] = serializerSchema(serializer, viewSet.FieldTypes, viewSet.FieldTags)
+	if key != nil {
+		g.schemaNames[key] = name
+	}
+	return ref(name)
+}
+
+// partialSchemaRef describes the request body of partial updates, which is the referenced schema without
+// the required properties, named like in drf-spectacular (`PatchedProduct`)
+func (g *generator) partialSchemaRef(schemaRef map[string]any) map[string]any {
+	name := strings.TrimPrefix(schemaRef["$ref"].(string), "#/components/schemas/")
+	partialName := "Patched" + name
+	if _, ok := g.schemas[partialName]; !ok {
+		partialSchema := map[string]any{}
+		for k, v := range g.schemas[name].(map[string]any) {
+			if k != "required" {
+				partialSchema[k] = v
+			}
+		}
+		g.schemas[partialName] = partialSchema
+	}
+	return ref(partialName)
+}
+
+// updateSchemaRef describes the request body of full updates, which is the referenced schema requiring all
+// the writable fields without a default, apart from the ones accepting null, that are cleared when missing
+func (g *generator) updateSchemaRef(serializer serializers.Serializer, schemaRef map[string]any) map[string]any {
+	name := strings.TrimPrefix(schemaRef["$ref"].(string), "#/components/schemas/")
+	updateName := "Updated" + name
+	if _, ok := g.schemas[updateName]; !ok {
+		updateSchema := map[string]any{}
+		for k, v := range g.schemas[name].(map[string]any) {
+			updateSchema[k] = v
+		}
+		required, _ := updateSchema["required"].([]string)
+		required = append([]string{}, required...)
+		if lister, ok := serializer.(serializers.FieldLister); ok {
+			for _, field := range lister.ListFields() {
+				if field.IsWritable() && !fields.HasDefault(field) && !fields.AllowsNull(field) {
+					required = append(required, field.Name())
+				}
+			}
+		}
+		if len(required) > 0 {
+			updateSchema["required"] = uniqueSorted(required)
+		}
+		g.schemas[updateName] = updateSchema
+	}
+	return ref(updateName)
+}
+
+// listSchema describes the response of the List action, wrapped in the envelope of the pagination, if any
+func listSchema(envelope *common.PaginationEnvelope, itemSchema map[string]any) map[string]any {
+	results := map[string]any{"type": "array", "items": itemSchema}
+	if envelope == nil {
+		return results
+	}
+	properties := map[string]any{
+		"next":     map[string]any{"type": []any{"string", "null"}, "format": "uri"},
+		"previous": map[string]any{"type": []any{"string", "null"}, "format": "uri"},
+		"results":  results,
+	}
+	required := []string{"next", "previous", "results"}
+	if envelope.Count {
+		properties["count"] = map[string]any{"type": "integer", "minimum": 0}
+		required = append(required, "count")
+	}
+	return map[string]any{"type": "object", "properties": properties, "required": uniqueSorted(required)}
+}
+
+// serializerKey identifies the serializer instance, only pointers are used, as other types are not
+// guaranteed to be comparable
+func serializerKey(serializer serializers.Serializer) any {
+	if serializer == nil || reflect.ValueOf(serializer).Kind() != reflect.Pointer {
+		return nil
+	}
+	return serializer
+}
+
+func serializerSchema(
+	serializer serializers.Serializer, fieldTypes map[string]string, fieldTags map[string]map[string]string,
+) map[string]any {
+	schema := map[string]any{"type": "object"}
+	lister, ok := serializer.(serializers.FieldLister)
+	if !ok {
+		return schema
+	}
+	properties := map[string]any{}
+	required := []string{}
+	for _, field := range lister.ListFields() {
+		propertySchema := fieldSchema(fieldTypes[field.Name()], fieldTags[field.Name()])
+		if !field.IsWritable() {
+			propertySchema["readOnly"] = true
+		}
+		if !field.IsReadable() {
+			propertySchema["writeOnly"] = true
+		}
+		applyFieldOptions(propertySchema, field)
+		if field.IsWritable() && fields.IsRequired(field) && !fields.HasDefault(field) {
+			required = append(required, field.Name())
+		}
+		properties[field.Name()] = propertySchema
+	}
+	if validating, ok := serializer.(interface {
+		Validators() []serializers.Validator
+	}); ok {
+		for _, validator := range validating.Validators() {
+			required = append(required, applyValidator(properties, validator)...)
+		}
+	}
+	schema["properties"] = properties
+	if len(required) > 0 {
+		schema["required"] = uniqueSorted(required)
+	}
+	return schema
+}
+
+// applyFieldOptions reflects the null, blank and default options of the field in the property schema
+func applyFieldOptions(propertySchema map[string]any, field fields.Field) {
+	if itsType, ok := propertySchema["type"].(string); ok && fields.AllowsNull(field) {
+		propertySchema["type"] = []any{itsType, "null"}
+	}
+	if isStringSchema(propertySchema) && !fields.AllowsBlank(field) {
+		propertySchema["minLength"] = int64(1)
+	}
+	if staticDefault, ok := field.(interface{ StaticDefault() (any, bool) }); ok {
+		if value, hasValue := staticDefault.StaticDefault(); hasValue {
+			propertySchema["default"] = value
+		}
+	}
+}
+
+func isStringSchema(propertySchema map[string]any) bool {
+	switch itsType := propertySchema["type"].(type) {
+	case string:
+		return itsType == "string"
+	case []any:
+		return len(itsType) > 0 && itsType[0] == "string"
+	}
+	return false
+}
+
+// applyValidator reflects the rules of go-playground and JSON Schema validators in the property schemas
+// and returns the names of the required properties
+func applyValidator(properties map[string]any, validator serializers.Validator) []string {
+	required := []string{}
+	switch v := validator.(type) {
+	case interface{ Rules() map[string]any }:
+		for name, rules := range v.Rules() {
+			propertySchema, ok := properties[name].(map[string]any)
+			rulesStr, isStr := rules.(string)
+			if !ok || !isStr {
+				continue
+			}
+			if applyPlaygroundRules(propertySchema, rulesStr) {
+				required = append(required, name)
+			}
+		}
+	case interface{ RawSchema() map[string]any }:
+		required = append(required, applyJSONSchema(properties, v.RawSchema())...)
+	}
+	return required
+}
+
+var ginParamRegexp = regexp.MustCompile(`[:*]([A-Za-z0-9_]+)`)
+
+// convertPath converts gin path params (`/products/:product_id`) to OpenAPI ones (`/products/{product_id}`)
+func convertPath(ginPath string) (string, []string) {
+	params := []string{}
+	for _, match := range ginParamRegexp.FindAllStringSubmatch(ginPath, -1) {
+		params = append(params, match[1])
+	}
+	return ginParamRegexp.ReplaceAllString(ginPath, "{$1}"), params
+}
+
+func ref(name string) map[string]any {
+	return map[string]any{"$ref": "#/components/schemas/" + name}
+}
+
+func jsonResponse(description string, schema map[string]any) map[string]any {
+	return map[string]any{
+		"description": description,
+		"content": map[string]any{
+			"application/json": map[string]any{"schema": schema},
+		},
+	}
+}
+
+func jsonRequestBody(schema map[string]any) map[string]any {
+	return map[string]any{
+		"required": true,
+		"content": map[string]any{
+			"application/json": map[string]any{"schema": schema},
+		},
+	}
+}
+
+func camelCase(s string) string {
+	var sb strings.Builder
+	for _, part := range strings.Split(s, "_") {
+		if part == "" {
+			continue
+		}
+		sb.WriteString(strings.ToUpper(part[:1]))
+		sb.WriteString(part[1:])
+	}
+	return sb.String()
+}
+
+func uniqueSorted(s []string) []string {
+	seen := map[string]bool{}
+	ret := []string{}
+	for _, item := range s {
+		if !seen[item] {
+			seen[item] = true
+			ret = append(ret, item)
+		}
+	}
+	sort.Strings(ret)
+	return ret
+}
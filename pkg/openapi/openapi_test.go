@@ -0,0 +1,379 @@
+package openapi
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/glothriel/grf/pkg/authentication"
+	"github.com/glothriel/grf/pkg/fields"
+	"github.com/glothriel/grf/pkg/queries"
+	"github.com/glothriel/grf/pkg/serializers"
+	"github.com/glothriel/grf/pkg/views"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+type Product struct {
+	ID     uint    `json:"id"`
+	Name   string  `json:"name"`
+	Price  float64 `json:"price"`
+	Secret string  `json:"secret"`
+}
+
+type Photo struct {
+	ID  uint   `json:"id"`
+	URL string `json:"url"`
+}
+
+func prepareRegistry() *views.Registry {
+	registry := views.NewRegistry()
+	_, r := gin.CreateTestContext(httptest.NewRecorder())
+	views.NewModelViewSet[Product]("/products", queries.InMemory[Product]()).WithSerializer(
+		serializers.NewValidatingSerializer[Product](
+			serializers.NewModelSerializer[Product]().WithField("secret", func(oldField fields.Field) {
+				oldField.WithWriteOnly()
+			}),
+			serializers.NewGoPlaygroundValidator[Product](map[string]any{
+				"name":  "required,min=3,max=10",
+				"price": "gte=0",
+			}),
+		),
+	).WithRegistry(registry).Register(r)
+	views.NewModelViewSet[Photo]("/products/:product_id/photos", queries.InMemory[Photo]()).WithActions(
+		views.ActionList, views.ActionRetrieve,
+	).WithSerializer(
+		serializers.NewValidatingSerializer[Photo](
+			serializers.NewModelSerializer[Photo](),
+			serializers.NewJSONSchemaValidator(map[string]any{
+				"type":       "object",
+				"properties": map[string]any{"url": map[string]any{"type": "string", "format": "uri"}},
+				"required":   []string{"url"},
+			}),
+		),
+	).WithRegistry(registry).Register(r)
+	return registry
+}
+
+func TestGenerateListsPathsAndParams(t *testing.T) {
+	// given
+	registry := prepareRegistry()
+
+	// when
+	document := Generate(registry, Info{Title: "Shop", Version: "1.0.0"})
+
+	// then
+	assert.Equal(t, "3.1.0", document["openapi"])
+	assert.Equal(t, map[string]any{"title": "Shop", "version": "1.0.0"}, document["info"])
+	paths := document["paths"].(map[string]any)
+	assert.Len(t, paths, 4)
+
+	productList := paths["/products"].(map[string]any)
+	assert.Contains(t, productList, "get")
+	assert.Contains(t, productList, "post")
+	assert.NotContains(t, productList, "parameters")
+
+	productDetail := paths["/products/{product_id}"].(map[string]any)
+	for _, method := range []string{"get", "put", "patch", "delete"} {
+		assert.Contains(t, productDetail, method)
+	}
+	assert.Equal(t, []any{
+		map[string]any{
+			"name": "product_id", "in": "path", "required": true,
+			"schema": map[string]any{"type": "integer", "minimum": 0},
+		},
+	}, productDetail["parameters"])
+	assert.Equal(t, "partial_update_product", productDetail["patch"].(map[string]any)["operationId"])
+
+	photoDetail := paths["/products/{product_id}/photos/{photo_id}"].(map[string]any)
+	// The type of the parent ID is unknown on nested routes
+	assert.Equal(t, []any{
+		map[string]any{"name": "product_id", "in": "path", "required": true, "schema": map[string]any{"type": "string"}},
+		map[string]any{
+			"name": "photo_id", "in": "path", "required": true,
+			"schema": map[string]any{"type": "integer", "minimum": 0},
+		},
+	}, photoDetail["parameters"])
+	assert.Contains(t, photoDetail, "get")
+	assert.NotContains(t, photoDetail, "put")
+	assert.NotContains(t, paths["/products/{product_id}/photos"], "post")
+}
+
+func TestGenerateDescribesSerializerFields(t *testing.T) {
+	// given
+	registry := prepareRegistry()
+
+	// when
+	document := Generate(registry, Info{Title: "Shop", Version: "1.0.0"})
+
+	// then
+	schemas := document["components"].(map[string]any)["schemas"].(map[string]any)
+	assert.Equal(t, map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"id":     map[string]any{"type": "integer", "minimum": 0, "readOnly": true},
+			"name":   map[string]any{"type": "string", "minLength": int64(3), "maxLength": int64(10)},
+			"price":  map[string]any{"type": "number", "format": "double", "minimum": int64(0)},
+			"secret": map[string]any{"type": "string", "writeOnly": true},
+		},
+		"required": []string{"name"},
+	}, schemas["Product"])
+	assert.Equal(t, map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"id":  map[string]any{"type": "integer", "minimum": 0, "readOnly": true},
+			"url": map[string]any{"type": "string", "format": "uri"},
+		},
+		"required": []string{"url"},
+	}, schemas["Photo"])
+	assert.Contains(t, schemas, "ValidationError")
+}
+
+type Ticket struct {
+	ID     uint           `json:"id"`
+	Title  string         `json:"title" grf:"required;allow_blank:false"`
+	Note   sql.NullString `json:"note"`
+	Status string         `json:"status" grf:"required"`
+}
+
+func TestGenerateDescribesFieldOptions(t *testing.T) {
+	// given
+	registry := views.NewRegistry()
+	_, r := gin.CreateTestContext(httptest.NewRecorder())
+	views.NewModelViewSet[Ticket]("/tickets", queries.InMemory[Ticket]()).WithSerializer(
+		serializers.NewModelSerializer[Ticket]().WithField("status", func(oldField fields.Field) {
+			oldField.(fields.OptionsField).WithDefault("open")
+		}),
+	).WithRegistry(registry).Register(r)
+
+	// when
+	document := Generate(registry, Info{Title: "Support", Version: "1.0.0"})
+
+	// then
+	schemas := document["components"].(map[string]any)["schemas"].(map[string]any)
+	assert.Equal(t, map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"id":     map[string]any{"type": "integer", "minimum": 0, "readOnly": true},
+			"title":  map[string]any{"type": "string", "minLength": int64(1)},
+			"note":   map[string]any{"type": []any{"string", "null"}},
+			"status": map[string]any{"type": "string", "default": "open"},
+		},
+		"required": []string{"title"},
+	}, schemas["Ticket"])
+}
+
+func TestGenerateNamesSchemasOfActionSerializers(t *testing.T) {
+	// given
+	registry := views.NewRegistry()
+	_, r := gin.CreateTestContext(httptest.NewRecorder())
+	views.NewModelViewSet[Photo]("/photos", queries.InMemory[Photo]()).WithListSerializer(
+		serializers.NewModelSerializer[Photo]().WithModelFields([]string{"id"}),
+	).WithRegistry(registry).Register(r)
+
+	// when
+	document := Generate(registry, Info{Title: "Shop", Version: "1.0.0"})
+
+	// then
+	paths := document["paths"].(map[string]any)
+	schemas := document["components"].(map[string]any)["schemas"].(map[string]any)
+	listSchema := paths["/photos"].(map[string]any)["get"].(map[string]any)["responses"].(map[string]any)["200"]
+	createSchema := paths["/photos"].(map[string]any)["post"].(map[string]any)["requestBody"]
+	assert.Equal(t, ref("PhotoList"), listSchema.(map[string]any)["content"].(map[string]any)["application/json"].(map[string]any)["schema"].(map[string]any)["items"])
+	assert.Equal(t, ref("Photo"), createSchema.(map[string]any)["content"].(map[string]any)["application/json"].(map[string]any)["schema"])
+	assert.Len(t, schemas["PhotoList"].(map[string]any)["properties"], 1)
+	assert.Len(t, schemas["Photo"].(map[string]any)["properties"], 2)
+}
+
+func TestGenerateDescribesPartialUpdateWithoutRequiredProperties(t *testing.T) {
+	// given
+	registry := prepareRegistry()
+
+	// when
+	document := Generate(registry, Info{Title: "Shop", Version: "1.0.0"})
+
+	// then
+	detail := document["paths"].(map[string]any)["/products/{product_id}"].(map[string]any)
+	schemas := document["components"].(map[string]any)["schemas"].(map[string]any)
+	requestSchema := func(method string) any {
+		requestBody := detail[method].(map[string]any)["requestBody"].(map[string]any)
+		return requestBody["content"].(map[string]any)["application/json"].(map[string]any)["schema"]
+	}
+	assert.Equal(t, ref("UpdatedProduct"), requestSchema("put"))
+	assert.Equal(t, ref("PatchedProduct"), requestSchema("patch"))
+	assert.NotContains(t, schemas["PatchedProduct"], "required")
+	assert.Equal(t, schemas["Product"].(map[string]any)["properties"], schemas["PatchedProduct"].(map[string]any)["properties"])
+	assert.Equal(t, []string{"name"}, schemas["Product"].(map[string]any)["required"])
+}
+
+func TestGenerateDescribesFullUpdateRequiringAllWritableFields(t *testing.T) {
+	// given
+	registry := views.NewRegistry()
+	_, r := gin.CreateTestContext(httptest.NewRecorder())
+	views.NewModelViewSet[Ticket]("/tickets", queries.InMemory[Ticket]()).WithSerializer(
+		serializers.NewModelSerializer[Ticket]().WithField("status", func(oldField fields.Field) {
+			oldField.(fields.OptionsField).WithDefault("open")
+		}),
+	).WithRegistry(registry).Register(r)
+
+	// when
+	document := Generate(registry, Info{Title: "Support", Version: "1.0.0"})
+
+	// then
+	schemas := document["components"].(map[string]any)["schemas"].(map[string]any)
+	put := document["paths"].(map[string]any)["/tickets/{ticket_id}"].(map[string]any)["put"].(map[string]any)
+	requestBody := put["requestBody"].(map[string]any)["content"].(map[string]any)["application/json"].(map[string]any)
+	assert.Equal(t, ref("UpdatedTicket"), requestBody["schema"])
+	// `note` accepts null and `status` has a default, so neither has to be sent
+	assert.Equal(t, []string{"title"}, schemas["UpdatedTicket"].(map[string]any)["required"])
+	assert.Equal(t, schemas["Ticket"].(map[string]any)["properties"], schemas["UpdatedTicket"].(map[string]any)["properties"])
+}
+
+func TestGenerateDescribesFullUpdateOfProducts(t *testing.T) {
+	// given
+	registry := prepareRegistry()
+
+	// when
+	document := Generate(registry, Info{Title: "Shop", Version: "1.0.0"})
+
+	// then
+	schemas := document["components"].(map[string]any)["schemas"].(map[string]any)
+	assert.Equal(t, []string{"name", "price", "secret"}, schemas["UpdatedProduct"].(map[string]any)["required"])
+}
+
+type Article struct {
+	ID    uint   `json:"id"`
+	Title string `json:"title"`
+	Body  string `json:"body"`
+}
+
+func TestGenerateDescribesAuthenticationAndPermissionResponses(t *testing.T) {
+	// given
+	registry := views.NewRegistry()
+	_, r := gin.CreateTestContext(httptest.NewRecorder())
+	views.NewModelViewSet[Article]("/articles", queries.InMemory[Article]()).WithAuthentication(
+		authentication.NewAPIKeyAuthentication("X-API-Key", func(*gin.Context, string) (authentication.User, error) {
+			return nil, nil
+		}),
+	).WithActionPermissions(views.IsAuthenticated{}, views.ActionCreate).WithRegistry(registry).Register(r)
+	views.NewModelViewSet[Photo]("/photos", queries.InMemory[Photo]()).WithRegistry(registry).Register(r)
+
+	// when
+	document := Generate(registry, Info{Title: "Blog", Version: "1.0.0"})
+
+	// then
+	paths := document["paths"].(map[string]any)
+	responses := func(path, method string) map[string]any {
+		return paths[path].(map[string]any)[method].(map[string]any)["responses"].(map[string]any)
+	}
+	assert.Equal(t, jsonResponse("Unauthorized", ref("Error")), responses("/articles", "get")["401"])
+	assert.NotContains(t, responses("/articles", "get"), "403")
+	assert.Equal(t, jsonResponse("Unauthorized", ref("Error")), responses("/articles", "post")["401"])
+	assert.Equal(t, jsonResponse("Forbidden", ref("Error")), responses("/articles", "post")["403"])
+	assert.NotContains(t, responses("/photos", "get"), "401")
+	assert.NotContains(t, responses("/photos", "get"), "403")
+}
+
+type Invoice struct {
+	ID       uint             `json:"id"`
+	Total    decimal.Decimal  `json:"total"`
+	Rate     decimal.Decimal  `json:"rate" grf:"decimal_format:number"`
+	Discount *decimal.Decimal `json:"discount" grf:"decimal_format:number"`
+}
+
+func TestGenerateDescribesDecimalFormats(t *testing.T) {
+	// given
+	registry := views.NewRegistry()
+	_, r := gin.CreateTestContext(httptest.NewRecorder())
+	views.NewModelViewSet[Invoice]("/invoices", queries.InMemory[Invoice]()).WithRegistry(registry).Register(r)
+
+	// when
+	document := Generate(registry, Info{Title: "Billing", Version: "1.0.0"})
+
+	// then
+	schemas := document["components"].(map[string]any)["schemas"].(map[string]any)
+	properties := schemas["Invoice"].(map[string]any)["properties"].(map[string]any)
+	assert.Equal(t, "string", properties["total"].(map[string]any)["type"])
+	assert.Equal(t, "number", properties["rate"].(map[string]any)["type"])
+	assert.Equal(t, []any{"number", "null"}, properties["discount"].(map[string]any)["type"])
+}
+
+func TestHandlerServesJSONDocument(t *testing.T) {
+	// given
+	registry := prepareRegistry()
+	_, r := gin.CreateTestContext(httptest.NewRecorder())
+	r.GET("/openapi.json", Handler(registry, Info{Title: "Shop", Version: "1.0.0"}))
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/openapi.json", nil)
+
+	// when
+	r.ServeHTTP(w, req)
+
+	// then
+	assert.Equal(t, http.StatusOK, w.Code)
+	var document map[string]any
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &document))
+	assert.Equal(t, "3.1.0", document["openapi"])
+	assert.Contains(t, document["paths"], "/products/{product_id}")
+}
+
+func TestSchemaForGoType(t *testing.T) {
+	tests := []struct {
+		goType string
+		want   map[string]any
+	}{
+		{"string", map[string]any{"type": "string"}},
+		{"*int", map[string]any{"type": []any{"integer", "null"}}},
+		{"uuid.UUID", map[string]any{"type": "string", "format": "uuid"}},
+		{"[]string", map[string]any{"type": "array", "items": map[string]any{"type": "string"}}},
+		{"models.SliceField[bool]", map[string]any{"type": "array", "items": map[string]any{"type": "boolean"}}},
+		{"models.JSONField[map[string]interface {}]", map[string]any{"type": "object"}},
+		{"models.JSONField[main.Attributes]", map[string]any{"type": "object"}},
+		{"models.JSONField[[]string]", map[string]any{"type": "array", "items": map[string]any{"type": "string"}}},
+		{"models.Money", map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"amount":   map[string]any{"type": "string", "format": "decimal"},
+				"currency": map[string]any{"type": "string", "pattern": "^[A-Z]{3}$"},
+			},
+			"required": []any{"amount", "currency"},
+		}},
+		{"main.Unknown", map[string]any{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.goType, func(t *testing.T) {
+			assert.Equal(t, tt.want, SchemaForGoType(tt.goType))
+		})
+	}
+}
+
+func TestConvertPath(t *testing.T) {
+	tests := []struct {
+		name       string
+		ginPath    string
+		wantPath   string
+		wantParams []string
+	}{
+		{"no params", "/products", "/products", []string{}},
+		{"single param", "/products/:product_id", "/products/{product_id}", []string{"product_id"}},
+		{
+			"nested params",
+			"/products/:product_id/photos/:photo_id",
+			"/products/{product_id}/photos/{photo_id}",
+			[]string{"product_id", "photo_id"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// when
+			path, params := convertPath(tt.ginPath)
+
+			// then
+			assert.Equal(t, tt.wantPath, path)
+			assert.Equal(t, tt.wantParams, params)
+		})
+	}
+}
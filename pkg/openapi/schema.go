@@ -0,0 +1,224 @@
+package openapi
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/glothriel/grf/pkg/detectors"
+	"github.com/glothriel/grf/pkg/models"
+)
+
+// goTypeSchemas maps Go types, as reported by detectors.FieldTypes, to JSON Schema
+var goTypeSchemas = map[string]map[string]any{
+	"string":          {"type": "string"},
+	"bool":            {"type": "boolean"},
+	"float32":         {"type": "number", "format": "float"},
+	"float64":         {"type": "number", "format": "double"},
+	"int":             {"type": "integer"},
+	"int8":            {"type": "integer"},
+	"int16":           {"type": "integer"},
+	"int32":           {"type": "integer", "format": "int32"},
+	"int64":           {"type": "integer", "format": "int64"},
+	"uint":            {"type": "integer", "minimum": 0},
+	"uint8":           {"type": "integer", "minimum": 0},
+	"uint16":          {"type": "integer", "minimum": 0},
+	"uint32":          {"type": "integer", "minimum": 0},
+	"uint64":          {"type": "integer", "minimum": 0},
+	"time.Time":       {"type": "string", "format": "date-time"},
+	"uuid.UUID":       {"type": "string", "format": "uuid"},
+	"decimal.Decimal": {"type": "string", "format": "decimal"},
+	"sql.NullInt32":   {"type": []any{"integer", "null"}, "format": "int32"},
+	"sql.NullString":  {"type": []any{"string", "null"}},
+	"models.Money": {
+		"type": "object",
+		"properties": map[string]any{
+			"amount":   map[string]any{"type": "string", "format": "decimal"},
+			"currency": map[string]any{"type": "string", "pattern": "^[A-Z]{3}$"},
+		},
+		"required": []any{"amount", "currency"},
+	},
+}
+
+// SchemaForGoType returns the JSON Schema of the wire representation of a Go type. Unknown types
+// result in an empty schema, which accepts any value.
+func SchemaForGoType(goType string) map[string]any {
+	if strings.HasPrefix(goType, "*") {
+		schema := SchemaForGoType(goType[1:])
+		if itsType, ok := schema["type"].(string); ok {
+			schema["type"] = []any{itsType, "null"}
+		}
+		return schema
+	}
+	if strings.HasPrefix(goType, "[]") {
+		return map[string]any{"type": "array", "items": SchemaForGoType(goType[2:])}
+	}
+	if strings.HasPrefix(goType, "models.SliceField[") && strings.HasSuffix(goType, "]") {
+		return map[string]any{
+			"type":  "array",
+			"items": SchemaForGoType(goType[len("models.SliceField[") : len(goType)-1]),
+		}
+	}
+	if strings.HasPrefix(goType, "models.JSONField[") && strings.HasSuffix(goType, "]") {
+		// Structs are not described, but they are represented as objects
+		schema := SchemaForGoType(goType[len("models.JSONField[") : len(goType)-1])
+		if len(schema) == 0 {
+			schema["type"] = "object"
+		}
+		return schema
+	}
+	if strings.HasPrefix(goType, "map[string]") {
+		return map[string]any{"type": "object"}
+	}
+	schema := map[string]any{}
+	for k, v := range goTypeSchemas[goType] {
+		schema[k] = v
+	}
+	return schema
+}
+
+// fieldSchema returns the schema of a model field, taking the `grf` tag settings changing its wire format into
+// account
+func fieldSchema(goType string, tags map[string]string) map[string]any {
+	schema := SchemaForGoType(goType)
+	isDecimal := strings.TrimPrefix(goType, "*") == "decimal.Decimal"
+	if isDecimal && tags[models.TagDecimalFormat] == detectors.DecimalFormatNumber {
+		switch schema["type"].(type) {
+		case string:
+			schema["type"] = "number"
+		case []any:
+			schema["type"] = []any{"number", "null"}
+		}
+	}
+	return schema
+}
+
+// applyPlaygroundRules translates go-playground/validator rules, like `required,min=3,max=10`, to
+// JSON Schema keywords. Returns true if the field is required. Rules without a JSON Schema
+// counterpart are ignored.
+func applyPlaygroundRules(schema map[string]any, rules string) bool {
+	required := false
+	for _, rule := range strings.Split(rules, ",") {
+		name, param, _ := strings.Cut(strings.TrimSpace(rule), "=")
+		switch name {
+		case "required":
+			required = true
+		case "omitempty":
+			// Does not change the schema
+		case "min", "max", "len":
+			applyLength(schema, name, param)
+		case "gt", "gte", "lt", "lte":
+			applyBound(schema, name, param)
+		case "oneof":
+			enum := []any{}
+			for _, option := range strings.Fields(param) {
+				enum = append(enum, typedParam(schema, option))
+			}
+			schema["enum"] = enum
+		case "email":
+			schema["format"] = "email"
+		case "url", "uri", "http_url":
+			schema["format"] = "uri"
+		case "uuid", "uuid3", "uuid4", "uuid5":
+			schema["format"] = "uuid"
+		case "ipv4", "ipv6", "hostname":
+			schema["format"] = name
+		case "datetime":
+			schema["format"] = "date-time"
+		}
+	}
+	return required
+}
+
+// applyLength handles rules, which go-playground applies to lengths of strings and collections
+// and to values of numbers
+func applyLength(schema map[string]any, rule, param string) {
+	num, parseErr := strconv.ParseFloat(param, 64)
+	if parseErr != nil {
+		return
+	}
+	keywords := map[string]map[string][]string{
+		"string": {"min": {"minLength"}, "max": {"maxLength"}, "len": {"minLength", "maxLength"}},
+		"array":  {"min": {"minItems"}, "max": {"maxItems"}, "len": {"minItems", "maxItems"}},
+		"object": {"min": {"minProperties"}, "max": {"maxProperties"}, "len": {"minProperties", "maxProperties"}},
+		"number": {"min": {"minimum"}, "max": {"maximum"}, "len": {"const"}},
+	}
+	for _, keyword := range keywords[schemaKind(schema)][rule] {
+		schema[keyword] = numericParam(num)
+	}
+}
+
+func applyBound(schema map[string]any, rule, param string) {
+	num, parseErr := strconv.ParseFloat(param, 64)
+	if parseErr != nil {
+		return
+	}
+	if schemaKind(schema) != "number" {
+		return
+	}
+	keywords := map[string]string{
+		"gt": "exclusiveMinimum", "gte": "minimum", "lt": "exclusiveMaximum", "lte": "maximum",
+	}
+	schema[keywords[rule]] = numericParam(num)
+}
+
+// schemaKind groups JSON Schema types by how go-playground interprets the size rules
+func schemaKind(schema map[string]any) string {
+	itsType := schema["type"]
+	if types, ok := itsType.([]any); ok && len(types) > 0 {
+		itsType = types[0]
+	}
+	switch itsType {
+	case "integer", "number":
+		return "number"
+	case "array", "object", "string":
+		return itsType.(string)
+	}
+	return ""
+}
+
+func numericParam(num float64) any {
+	if num == float64(int64(num)) {
+		return int64(num)
+	}
+	return num
+}
+
+func typedParam(schema map[string]any, param string) any {
+	if schemaKind(schema) == "number" {
+		if num, parseErr := strconv.ParseFloat(param, 64); parseErr == nil {
+			return numericParam(num)
+		}
+	}
+	return param
+}
+
+// applyJSONSchema merges the property schemas from a JSON Schema validator into the field schemas
+// and returns the names of the required properties
+func applyJSONSchema(properties map[string]any, rawSchema map[string]any) []string {
+	rawProperties, _ := rawSchema["properties"].(map[string]any)
+	for name, rawProperty := range rawProperties {
+		propertySchema, ok := properties[name].(map[string]any)
+		if !ok {
+			continue
+		}
+		rawPropertySchema, ok := rawProperty.(map[string]any)
+		if !ok {
+			continue
+		}
+		for k, v := range rawPropertySchema {
+			propertySchema[k] = v
+		}
+	}
+	required := []string{}
+	switch rawRequired := rawSchema["required"].(type) {
+	case []string:
+		required = append(required, rawRequired...)
+	case []any:
+		for _, name := range rawRequired {
+			if nameStr, ok := name.(string); ok {
+				required = append(required, nameStr)
+			}
+		}
+	}
+	return required
+}
@@ -1,9 +1,14 @@
 package common
 
-import "github.com/gin-gonic/gin"
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/glothriel/grf/pkg/models"
+)
 
+// QueryMod modifies the query executed by the driver, based on the request. Errors returned by Apply
+// are rendered by the views, so for example a *serializers.ValidationError results in 400 Bad Request.
 type QueryMod interface {
-	Apply(*gin.Context)
+	Apply(*gin.Context) error
 }
 
 type Pagination interface {
@@ -11,14 +16,37 @@ type Pagination interface {
 	Format(*gin.Context, []any) (any, error)
 }
 
+// PaginationEnvelope describes the object wrapping the results of the List action: `next`, `previous`
+// and `results`, and also `count` if Count is set
+type PaginationEnvelope struct {
+	Count bool
+}
+
+// EnvelopeDescriber is implemented by the paginations wrapping the results in an envelope, it's used to
+// describe the responses in the API documentation. Nil means the results are returned as they are.
+type EnvelopeDescriber interface {
+	Envelope() *PaginationEnvelope
+}
+
+// ListProcessor is implemented by the paginations that need the listed instances before they are
+// serialized, for example to drop the additional instance fetched to tell if there is a next page. The List
+// views call it with the results of CRUD().List, so it works with any List query.
+type ListProcessor interface {
+	ProcessList(*gin.Context, []models.InternalValue) ([]models.InternalValue, error)
+}
+
 type CompositeQueryMod struct {
 	children []QueryMod
 }
 
-func (c CompositeQueryMod) Apply(ctx *gin.Context) {
+// Apply applies all the children in order, stopping at the first error
+func (c CompositeQueryMod) Apply(ctx *gin.Context) error {
 	for _, child := range c.children {
-		child.Apply(ctx)
+		if err := child.Apply(ctx); err != nil {
+			return err
+		}
 	}
+	return nil
 }
 
 func NewCompositeQueryMod(children ...QueryMod) CompositeQueryMod {
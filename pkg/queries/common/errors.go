@@ -1,6 +1,49 @@
 package common
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+)
 
 var ErrorInternal = errors.New("internal error")
 var ErrorNotFound = errors.New("not found")
+
+// Constraint is the kind of the database constraint
+type Constraint string
+
+const (
+	ConstraintUnique     Constraint = "unique"
+	ConstraintForeignKey Constraint = "foreign_key"
+	ConstraintNotNull    Constraint = "not_null"
+	ConstraintCheck      Constraint = "check"
+)
+
+// ConstraintError is returned by the query drivers when the database rejects a write because of a violated
+// constraint. Unique constraint violations and deletes of referenced instances are rendered as 409 Conflict,
+// other as 400 Bad Request. The responses describe the violation using the JSON names of the fields, the
+// names of the constraints are not exposed to the clients.
+type ConstraintError struct {
+	Constraint Constraint
+	// Fields are the JSON names of the fields violating the constraint, empty if they can't be determined
+	Fields []string
+	// Name is the name of the constraint, if the database reports it. It's meant for the logs and is not
+	// exposed to the clients.
+	Name string
+	// Referenced is true for the foreign key violations caused by deleting an instance, that other instances
+	// still refer to
+	Referenced bool
+	Err        error
+}
+
+// IsConflict tells if the violation is caused by the state of other instances, and not by the payload
+func (e *ConstraintError) IsConflict() bool {
+	return e.Constraint == ConstraintUnique || e.Referenced
+}
+
+func (e *ConstraintError) Error() string {
+	return fmt.Sprintf("%s constraint violated: %s", e.Constraint, e.Err)
+}
+
+func (e *ConstraintError) Unwrap() error {
+	return e.Err
+}
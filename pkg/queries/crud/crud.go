@@ -55,3 +55,29 @@ func (q *CRUD[Model]) WithDestroy(f DestroyQueryFunc) *CRUD[Model] {
 	q.Destroy = f
 	return q
 }
+
+// WrapList adds a layer around the List query, the layer receives the previous query and can call it
+func (q *CRUD[Model]) WrapList(layer func(ListQueryFunc) ListQueryFunc) *CRUD[Model] {
+	return q.WithList(layer(q.List))
+}
+
+// WrapRetrieve adds a layer around the Retrieve query, the layer receives the previous query and can call it
+func (q *CRUD[Model]) WrapRetrieve(layer func(RetrieveQueryFunc) RetrieveQueryFunc) *CRUD[Model] {
+	return q.WithRetrieve(layer(q.Retrieve))
+}
+
+// WrapCreate adds a layer around the Create query, the layer receives the previous query and can call it,
+// for example gormq.CreateTx
+func (q *CRUD[Model]) WrapCreate(layer func(CreateQueryFunc) CreateQueryFunc) *CRUD[Model] {
+	return q.WithCreate(layer(q.Create))
+}
+
+// WrapUpdate adds a layer around the Update query, the layer receives the previous query and can call it
+func (q *CRUD[Model]) WrapUpdate(layer func(UpdateQueryFunc) UpdateQueryFunc) *CRUD[Model] {
+	return q.WithUpdate(layer(q.Update))
+}
+
+// WrapDestroy adds a layer around the Destroy query, the layer receives the previous query and can call it
+func (q *CRUD[Model]) WrapDestroy(layer func(DestroyQueryFunc) DestroyQueryFunc) *CRUD[Model] {
+	return q.WithDestroy(layer(q.Destroy))
+}
@@ -4,9 +4,12 @@ import (
 	"fmt"
 
 	"github.com/gin-gonic/gin"
+	"github.com/glothriel/grf/pkg/authentication"
 	"github.com/glothriel/grf/pkg/models"
 	"github.com/glothriel/grf/pkg/queries/common"
 	"github.com/glothriel/grf/pkg/queries/crud"
+	"github.com/glothriel/grf/pkg/queries/filters"
+	"github.com/glothriel/grf/pkg/serializers"
 	"github.com/google/uuid"
 	"github.com/sirupsen/logrus"
 )
@@ -19,9 +22,133 @@ type InMemoryQueryDriver[Model any] struct {
 	update   func(id any, new models.InternalValue) (models.InternalValue, error)
 	delete   func(id any) error
 
+	filterSet *filters.FilterSet[Model]
+	ordering  *filters.Ordering[Model]
+	scopes    []ScopeFunc
+
 	q *crud.CRUD[Model]
 }
 
+// ScopeFunc tells if the user is allowed to access the instance
+type ScopeFunc func(ctx *gin.Context, user authentication.User, intVal models.InternalValue) bool
+
+// WithScope narrows the List, Retrieve, Update and Destroy queries to the instances of the current user.
+// Instances outside of the scope result in 404 Not Found. It can be called multiple times, all the scopes
+// are applied.
+func (d *InMemoryQueryDriver[Model]) WithScope(scopeFunc ScopeFunc) *InMemoryQueryDriver[Model] {
+	d.scopes = append(d.scopes, scopeFunc)
+	return d
+}
+
+// WithOwnerField scopes the queries to the instances, where the field is equal to the ID of the authenticated
+// user, and sets the field to the ID of the user on Create and Update. Anonymous users don't see any instances.
+// The ID is converted to the type of the field, see filters.ConvertToField.
+func (d *InMemoryQueryDriver[Model]) WithOwnerField(field string) *InMemoryQueryDriver[Model] {
+	assignOwner(d.q, field)
+	return d.WithScope(func(ctx *gin.Context, user authentication.User, intVal models.InternalValue) bool {
+		if !user.IsAuthenticated() {
+			return false
+		}
+		ownerID, convertErr := filters.ConvertToField[Model](serializers.CtxFieldTypeMapper(ctx), field, user.ID())
+		if convertErr != nil {
+			logrus.Errorf("Could not use the ID of the user as the owner: %s", convertErr)
+			return false
+		}
+		return filters.Match(intVal, []filters.Condition{{Field: field, Lookup: filters.Exact, Value: ownerID}})
+	})
+}
+
+// assignOwner wraps Create and Update, so they set the field to the ID of the authenticated user, and the
+// clients can't assign the instances to other users
+func assignOwner[Model any](q *crud.CRUD[Model], field string) {
+	q.WrapCreate(func(create crud.CreateQueryFunc) crud.CreateQueryFunc {
+		return func(ctx *gin.Context, new models.InternalValue) (models.InternalValue, error) {
+			if ownerErr := setOwner[Model](ctx, field, new); ownerErr != nil {
+				return nil, ownerErr
+			}
+			return create(ctx, new)
+		}
+	}).WrapUpdate(func(update crud.UpdateQueryFunc) crud.UpdateQueryFunc {
+		return func(ctx *gin.Context, old models.InternalValue, new models.InternalValue, id any) (
+			models.InternalValue, error,
+		) {
+			if ownerErr := setOwner[Model](ctx, field, new); ownerErr != nil {
+				return nil, ownerErr
+			}
+			return update(ctx, old, new, id)
+		}
+	})
+}
+
+func setOwner[Model any](ctx *gin.Context, field string, intVal models.InternalValue) error {
+	user, userErr := authentication.CurrentUser(ctx)
+	if userErr != nil {
+		return userErr
+	}
+	if !user.IsAuthenticated() {
+		return authentication.ErrorNotAuthenticated
+	}
+	ownerID, convertErr := filters.ConvertToField[Model](serializers.CtxFieldTypeMapper(ctx), field, user.ID())
+	if convertErr != nil {
+		return fmt.Errorf("Could not use the ID of the user as the owner: %w", convertErr)
+	}
+	intVal[field] = ownerID
+	return nil
+}
+
+func (d InMemoryQueryDriver[Model]) inScope(ctx *gin.Context, intVal models.InternalValue) (bool, error) {
+	if len(d.scopes) == 0 {
+		return true, nil
+	}
+	user, userErr := authentication.CurrentUser(ctx)
+	if userErr != nil {
+		return false, userErr
+	}
+	for _, scope := range d.scopes {
+		if !scope(ctx, user, intVal) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// retrieveInScope returns the instance, or common.ErrorNotFound if it's outside of the scope
+func (d InMemoryQueryDriver[Model]) retrieveInScope(ctx *gin.Context, id any) (models.InternalValue, error) {
+	elem, retrieveErr := d.retrieve(id)
+	if retrieveErr != nil {
+		return nil, retrieveErr
+	}
+	inScope, scopeErr := d.inScope(ctx, elem)
+	if scopeErr != nil {
+		return nil, scopeErr
+	}
+	if !inScope {
+		return nil, common.ErrorNotFound
+	}
+	return elem, nil
+}
+
+// Exists implements serializers.UniquenessChecker, the scope and the filters of the driver are not applied
+func (d InMemoryQueryDriver[Model]) Exists(ctx *gin.Context, values map[string]any, excludeID any) (bool, error) {
+	conditions := make([]filters.Condition, 0, len(values))
+	for field, value := range values {
+		conditions = append(conditions, filters.Condition{Field: field, Lookup: filters.Exact, Value: value})
+	}
+	elems, listErr := d.list(ctx)
+	if listErr != nil {
+		return false, listErr
+	}
+	for _, elem := range elems {
+		if excludeID != nil && fmt.Sprintf("%v", elem["id"]) == fmt.Sprintf("%v", excludeID) {
+			continue
+		}
+		if filters.Match(elem, conditions) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
 // Pagination implements db.QueryDriver interface
 func (d InMemoryQueryDriver[Model]) Pagination() common.Pagination {
 	return dummyPagination[Model]{}
@@ -29,28 +156,81 @@ func (d InMemoryQueryDriver[Model]) Pagination() common.Pagination {
 
 // Filter implements db.QueryDriver interface
 func (d InMemoryQueryDriver[Model]) Filter() common.QueryMod {
-	return dummyQueryMod{}
+	if d.filterSet == nil {
+		return dummyQueryMod{}
+	}
+	return filterSetQueryMod[Model]{filterSet: d.filterSet}
+}
+
+// WithFilterSet filters the List and Retrieve queries using the conditions parsed from the query params
+func (d *InMemoryQueryDriver[Model]) WithFilterSet(filterSet *filters.FilterSet[Model]) *InMemoryQueryDriver[Model] {
+	d.filterSet = filterSet
+	return d
 }
 
 // Order implements db.QueryDriver interface
 func (d InMemoryQueryDriver[Model]) Order() common.QueryMod {
-	return dummyQueryMod{}
+	if d.ordering == nil {
+		return dummyQueryMod{}
+	}
+	return orderingQueryMod[Model]{ordering: d.ordering}
 }
 
-// CRUD implements db.QueryDriver interface
+// WithOrdering allows the clients to sort the results of the List query using the `?ordering=` query param
+func (d *InMemoryQueryDriver[Model]) WithOrdering(ordering *filters.Ordering[Model]) *InMemoryQueryDriver[Model] {
+	d.ordering = ordering
+	return d
+}
+
+// CRUD implements db.QueryDriver interface. The driver always returns the same instance, so the queries can
+// be replaced or wrapped before the ViewSet is registered.
 func (d InMemoryQueryDriver[Model]) CRUD() *crud.CRUD[Model] {
-	return d.q.WithCreate(func(ctx *gin.Context, m models.InternalValue) (models.InternalValue, error) {
+	return d.q
+}
+
+// defaultQueries creates the queries operating on the storage functions of the driver
+func (d *InMemoryQueryDriver[Model]) defaultQueries() *crud.CRUD[Model] {
+	return (&crud.CRUD[Model]{}).WithCreate(func(ctx *gin.Context, m models.InternalValue) (models.InternalValue, error) {
 		return d.create(ctx, m)
 	}).WithUpdate(func(
 		ctx *gin.Context, old models.InternalValue, new models.InternalValue, id any,
 	) (models.InternalValue, error) {
+		if _, scopeErr := d.retrieveInScope(ctx, id); scopeErr != nil {
+			return nil, scopeErr
+		}
 		return d.update(id, new)
 	}).WithDestroy(func(ctx *gin.Context, id any) error {
+		if _, scopeErr := d.retrieveInScope(ctx, id); scopeErr != nil {
+			return scopeErr
+		}
 		return d.delete(id)
 	}).WithRetrieve(func(ctx *gin.Context, id any) (models.InternalValue, error) {
-		return d.retrieve(id)
+		elem, retrieveErr := d.retrieveInScope(ctx, id)
+		if retrieveErr != nil {
+			return nil, retrieveErr
+		}
+		if !filters.Match(elem, ctxConditions(ctx)) {
+			return nil, common.ErrorNotFound
+		}
+		return elem, nil
 	}).WithList(func(ctx *gin.Context) ([]models.InternalValue, error) {
-		return d.list(ctx)
+		elems, listErr := d.list(ctx)
+		if listErr != nil {
+			return nil, listErr
+		}
+		conditions := ctxConditions(ctx)
+		matching := []models.InternalValue{}
+		for _, elem := range elems {
+			inScope, scopeErr := d.inScope(ctx, elem)
+			if scopeErr != nil {
+				return nil, scopeErr
+			}
+			if inScope && filters.Match(elem, conditions) {
+				matching = append(matching, elem)
+			}
+		}
+		filters.Sort(matching, ctxOrdering(ctx))
+		return matching, nil
 	})
 }
 
@@ -67,7 +247,8 @@ func (d InMemoryQueryDriver[Model]) Middleware() []gin.HandlerFunc {
 type dummyPagination[Model any] struct {
 }
 
-func (d dummyPagination[Model]) Apply(*gin.Context) {
+func (d dummyPagination[Model]) Apply(*gin.Context) error {
+	return nil
 }
 
 func (d dummyPagination[Model]) Format(_ *gin.Context, models []any) (any, error) {
@@ -77,7 +258,67 @@ func (d dummyPagination[Model]) Format(_ *gin.Context, models []any) (any, error
 type dummyQueryMod struct {
 }
 
-func (d dummyQueryMod) Apply(*gin.Context) {
+func (d dummyQueryMod) Apply(*gin.Context) error {
+	return nil
+}
+
+type filterSetQueryMod[Model any] struct {
+	filterSet *filters.FilterSet[Model]
+}
+
+// Apply stores the parsed conditions in the context, so they can be used by the queries
+func (f filterSetQueryMod[Model]) Apply(ctx *gin.Context) error {
+	conditions, parseErr := f.filterSet.Parse(ctx)
+	if parseErr != nil {
+		return parseErr
+	}
+	if orderableErr := filters.CheckOrderable(conditions); orderableErr != nil {
+		return orderableErr
+	}
+	ctx.Set(ctxConditionsKey, conditions)
+	return nil
+}
+
+const ctxConditionsKey = "db:inmemory:conditions"
+
+func ctxConditions(ctx *gin.Context) []filters.Condition {
+	if ctx == nil {
+		return nil
+	}
+	anyVal, ok := ctx.Get(ctxConditionsKey)
+	if !ok {
+		return nil
+	}
+	conditions, _ := anyVal.([]filters.Condition)
+	return conditions
+}
+
+type orderingQueryMod[Model any] struct {
+	ordering *filters.Ordering[Model]
+}
+
+// Apply stores the parsed ordering in the context, so it can be used by the List query
+func (o orderingQueryMod[Model]) Apply(ctx *gin.Context) error {
+	terms, parseErr := o.ordering.Parse(ctx)
+	if parseErr != nil {
+		return parseErr
+	}
+	ctx.Set(ctxOrderingKey, terms)
+	return nil
+}
+
+const ctxOrderingKey = "db:inmemory:ordering"
+
+func ctxOrdering(ctx *gin.Context) []filters.OrderingTerm {
+	if ctx == nil {
+		return nil
+	}
+	anyVal, ok := ctx.Get(ctxOrderingKey)
+	if !ok {
+		return nil
+	}
+	terms, _ := anyVal.([]filters.OrderingTerm)
+	return terms
 }
 
 // InMemoryDriver creates InMemoryQueryDriver with given seed data.
@@ -85,7 +326,6 @@ func InMemoryDriver[Model any](seed ...Model) *InMemoryQueryDriver[Model] {
 	storage := map[any]models.InternalValue{}
 	var newID = newIDGenerator[Model](storage)
 	driver := &InMemoryQueryDriver[Model]{
-		q: &crud.CRUD[Model]{},
 		list: func(*gin.Context) ([]models.InternalValue, error) {
 			ivs := make([]models.InternalValue, len(storage))
 			i := 0
@@ -122,6 +362,7 @@ func InMemoryDriver[Model any](seed ...Model) *InMemoryQueryDriver[Model] {
 			return nil
 		},
 	}
+	driver.q = driver.defaultQueries()
 	for _, m := range seed {
 		intVal := models.AsInternalValue(m)
 		_, createErr := driver.create(nil, intVal)
@@ -1,12 +1,17 @@
 package dummy
 
 import (
+	"net/http"
 	"net/http/httptest"
 	"testing"
 
 	"github.com/gin-gonic/gin"
+	"github.com/glothriel/grf/pkg/authentication"
 	"github.com/glothriel/grf/pkg/models"
 	"github.com/glothriel/grf/pkg/queries/common"
+	"github.com/glothriel/grf/pkg/queries/filters"
+	"github.com/glothriel/grf/pkg/serializers"
+	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -33,6 +38,122 @@ func TestDummyList(t *testing.T) {
 	}, list)
 }
 
+func TestDummyListWithFilterSet(t *testing.T) {
+	// given
+	driver := InMemoryDriver(MockModel{Foo: "bar"}, MockModel{Foo: "baz"}, MockModel{Foo: "qux"}).WithFilterSet(
+		filters.NewFilterSet[MockModel]().WithField("foo", filters.Exact, filters.Contains),
+	)
+	ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+	ctx.Request, _ = http.NewRequest(http.MethodGet, "/?foo__contains=ba", nil)
+
+	// when
+	applyErr := driver.Filter().Apply(ctx)
+	list, listErr := driver.CRUD().List(ctx)
+
+	// then
+	assert.NoError(t, applyErr)
+	assert.NoError(t, listErr)
+	assert.ElementsMatch(t, []models.InternalValue{
+		{"id": uint(1), "foo": "bar"},
+		{"id": uint(2), "foo": "baz"},
+	}, list)
+}
+
+type pricedModel struct {
+	ID     uint            `json:"id"`
+	Price  decimal.Decimal `json:"price"`
+	Active bool            `json:"active"`
+}
+
+func TestDummyListWithDecimalRange(t *testing.T) {
+	// given
+	driver := InMemoryDriver(
+		pricedModel{Price: decimal.RequireFromString("9.99")},
+		pricedModel{Price: decimal.RequireFromString("12.50")},
+		pricedModel{Price: decimal.RequireFromString("20.00")},
+	).WithFilterSet(
+		filters.NewFilterSet[pricedModel]().WithField("price", filters.Gte, filters.Range),
+	)
+	for query, wantIDs := range map[string][]uint{
+		"price__gte=10":        {2, 3},
+		"price__range=10,12.5": {2},
+	} {
+		t.Run(query, func(t *testing.T) {
+			ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+			ctx.Request, _ = http.NewRequest(http.MethodGet, "/?"+query, nil)
+
+			// when
+			applyErr := driver.Filter().Apply(ctx)
+			list, listErr := driver.CRUD().List(ctx)
+
+			// then
+			assert.NoError(t, applyErr)
+			assert.NoError(t, listErr)
+			ids := []uint{}
+			for _, elem := range list {
+				ids = append(ids, elem["id"].(uint))
+			}
+			assert.ElementsMatch(t, wantIDs, ids)
+		})
+	}
+}
+
+func TestDummyFilterSetRejectsUnorderableLookups(t *testing.T) {
+	// given
+	driver := InMemoryDriver(pricedModel{Active: true}).WithFilterSet(
+		filters.NewFilterSet[pricedModel]().WithField("active", filters.Gt),
+	)
+	ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+	ctx.Request, _ = http.NewRequest(http.MethodGet, "/?active__gt=false", nil)
+
+	// when
+	applyErr := driver.Filter().Apply(ctx)
+
+	// then
+	assert.Equal(t, &serializers.ValidationError{FieldErrors: map[string][]string{
+		"active__gt": {"Lookup `gt` is not supported for values of type `bool`"},
+	}}, applyErr)
+}
+
+func TestDummyRetrieveWithFilterSet(t *testing.T) {
+	// given
+	driver := InMemoryDriver(MockModel{Foo: "bar"}).WithFilterSet(
+		filters.NewFilterSet[MockModel]().WithField("foo"),
+	)
+	ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+	ctx.Request, _ = http.NewRequest(http.MethodGet, "/?foo=baz", nil)
+
+	// when
+	applyErr := driver.Filter().Apply(ctx)
+	_, retrieveErr := driver.CRUD().Retrieve(ctx, 1)
+
+	// then
+	assert.NoError(t, applyErr)
+	assert.Equal(t, common.ErrorNotFound, retrieveErr)
+}
+
+func TestDummyListWithOrdering(t *testing.T) {
+	// given
+	driver := InMemoryDriver(MockModel{Foo: "b"}, MockModel{Foo: "c"}, MockModel{Foo: "a"}).WithOrdering(
+		filters.NewOrdering[MockModel]("foo"),
+	)
+	ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+	ctx.Request, _ = http.NewRequest(http.MethodGet, "/?ordering=-foo", nil)
+
+	// when
+	applyErr := driver.Order().Apply(ctx)
+	list, listErr := driver.CRUD().List(ctx)
+
+	// then
+	assert.NoError(t, applyErr)
+	assert.NoError(t, listErr)
+	assert.Equal(t, []models.InternalValue{
+		{"id": uint(2), "foo": "c"},
+		{"id": uint(1), "foo": "b"},
+		{"id": uint(3), "foo": "a"},
+	}, list)
+}
+
 func TestDummyRetrievie(t *testing.T) {
 	// given
 	driver := InMemoryDriver(MockModel{Foo: "bar"})
@@ -183,3 +304,78 @@ func TestFormat(t *testing.T) {
 	assert.Equal(t, []any{1}, formatted)
 	assert.NoError(t, err)
 }
+
+type OwnedModel struct {
+	ID      uint   `json:"id"`
+	Name    string `json:"name"`
+	OwnerID string `json:"owner_id"`
+}
+
+func TestDummyOwnerFieldScopesQueries(t *testing.T) {
+	// given
+	driver := InMemoryDriver(
+		OwnedModel{Name: "first", OwnerID: "alice"},
+		OwnedModel{Name: "second", OwnerID: "bob"},
+	).WithOwnerField("owner_id")
+	ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+	authentication.CtxSetUser(ctx, &authentication.SimpleUser{Identifier: "alice"})
+
+	// when
+	list, listErr := driver.CRUD().List(ctx)
+	_, retrieveErr := driver.CRUD().Retrieve(ctx, 2)
+	_, updateErr := driver.CRUD().Update(ctx, nil, models.InternalValue{"id": uint(2), "name": "changed"}, 2)
+	destroyErr := driver.CRUD().Destroy(ctx, 2)
+	created, createErr := driver.CRUD().Create(ctx, models.InternalValue{"name": "third", "owner_id": "bob"})
+
+	// then
+	assert.NoError(t, listErr)
+	assert.Equal(t, []models.InternalValue{{"id": uint(1), "name": "first", "owner_id": "alice"}}, list)
+	assert.Equal(t, common.ErrorNotFound, retrieveErr)
+	assert.Equal(t, common.ErrorNotFound, updateErr)
+	assert.Equal(t, common.ErrorNotFound, destroyErr)
+	assert.NoError(t, createErr)
+	assert.Equal(t, "alice", created["owner_id"])
+}
+
+type NumericallyOwnedModel struct {
+	ID      uint   `json:"id"`
+	Name    string `json:"name"`
+	OwnerID uint   `json:"owner_id"`
+}
+
+func TestDummyOwnerFieldConvertsStringIDs(t *testing.T) {
+	// given
+	driver := InMemoryDriver(
+		NumericallyOwnedModel{Name: "first", OwnerID: 7},
+		NumericallyOwnedModel{Name: "second", OwnerID: 8},
+	).WithOwnerField("owner_id")
+	ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+	// Users created from JWTs have the `sub` claim as their ID
+	authentication.CtxSetUser(ctx, &authentication.SimpleUser{Identifier: "7"})
+
+	// when
+	list, listErr := driver.CRUD().List(ctx)
+	created, createErr := driver.CRUD().Create(ctx, models.InternalValue{"name": "third", "owner_id": uint(8)})
+
+	// then
+	assert.NoError(t, listErr)
+	assert.Equal(t, []models.InternalValue{{"id": uint(1), "name": "first", "owner_id": uint(7)}}, list)
+	assert.NoError(t, createErr)
+	assert.Equal(t, uint(7), created["owner_id"])
+}
+
+func TestDummyScopeWithAnonymousUser(t *testing.T) {
+	// given
+	driver := InMemoryDriver(OwnedModel{Name: "first", OwnerID: "alice"}).WithOwnerField("owner_id")
+	ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+	authentication.CtxSetUser(ctx, authentication.AnonymousUser{})
+
+	// when
+	list, listErr := driver.CRUD().List(ctx)
+	_, createErr := driver.CRUD().Create(ctx, models.InternalValue{"name": "second"})
+
+	// then
+	assert.NoError(t, listErr)
+	assert.Empty(t, list)
+	assert.ErrorIs(t, createErr, authentication.ErrorNotAuthenticated)
+}
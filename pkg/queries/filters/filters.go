@@ -0,0 +1,361 @@
+package filters
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/glothriel/grf/pkg/models"
+	"github.com/glothriel/grf/pkg/serializers"
+	"github.com/glothriel/grf/pkg/types"
+	"github.com/sirupsen/logrus"
+)
+
+// Lookup is the comparison used by a filter, it's passed as a suffix of the query param, for example
+// `?price__gte=10`. A query param without a suffix uses Exact.
+type Lookup string
+
+const (
+	Exact     Lookup = "exact"
+	IExact    Lookup = "iexact"
+	Contains  Lookup = "contains"
+	IContains Lookup = "icontains"
+	Gt        Lookup = "gt"
+	Gte       Lookup = "gte"
+	Lt        Lookup = "lt"
+	Lte       Lookup = "lte"
+	// In accepts a comma separated list of values, for example `?id__in=1,2,3`
+	In Lookup = "in"
+	// IsNull accepts a boolean, for example `?deleted_at__isnull=true`
+	IsNull Lookup = "isnull"
+	// Range accepts two comma separated values, both inclusive, for example `?price__range=10,20`
+	Range Lookup = "range"
+)
+
+// AllLookups lists all the supported lookups
+var AllLookups = []Lookup{Exact, IExact, Contains, IContains, Gt, Gte, Lt, Lte, In, IsNull, Range}
+
+const lookupSeparator = "__"
+
+// Condition is a single filter parsed from the query params
+type Condition struct {
+	// Field is the JSON name of the model field
+	Field string
+	// GoField is the name of the model struct field
+	GoField string
+	// Path holds the keys inside the document for the fields implementing models.JSONDocument, for
+	// example `?attributes__size__width__gte=10` results in ["size", "width"]. It's empty for other fields.
+	Path   []string
+	Lookup Lookup
+	// Value is converted to the type of the field. It's a []any for In and Range lookups and a bool
+	// for IsNull. Values compared with the keys of JSON documents are decoded from JSON if possible,
+	// so `10` becomes float64(10) and `true` a bool, and are used as strings otherwise.
+	Value any
+}
+
+// FilterSet declares which fields of the model can be used for filtering, and using which lookups
+type FilterSet[Model any] struct {
+	lookups  map[string]map[Lookup]bool
+	goFields map[string]reflect.StructField
+	// mapper is nil unless set using WithFieldTypeMapper, see fieldTypeMapper
+	mapper *types.FieldTypeMapper
+}
+
+// WithField allows filtering on the model field using given lookups, Exact is used if none are passed. Nullable
+// fields, like pointers, sql.NullInt64 or gorm.DeletedAt, are filtered by the values they hold. IsNull can be
+// used with fields of any type.
+func (f *FilterSet[Model]) WithField(name string, lookups ...Lookup) *FilterSet[Model] {
+	if _, ok := f.goFields[name]; !ok {
+		var m Model
+		logrus.Panicf("Could not find field `%s` on model `%s` when registering filter", name, reflect.TypeOf(m))
+	}
+	if len(lookups) == 0 {
+		lookups = []Lookup{Exact}
+	}
+	if !onlyIsNull(lookups) && !f.isJSONDocument(name) && !f.isConvertible(valueType(f.goFields[name].Type)) {
+		logrus.Panicf("Filtering by field `%s` of type `%s` is not supported", name, f.goFields[name].Type)
+	}
+	if _, ok := f.lookups[name]; !ok {
+		f.lookups[name] = map[Lookup]bool{}
+	}
+	for _, lookup := range lookups {
+		if !isSupported(lookup) {
+			logrus.Panicf("Unsupported lookup `%s` for field `%s`", lookup, name)
+		}
+		f.lookups[name][lookup] = true
+	}
+	return f
+}
+
+// WithFieldTypeMapper sets the mapper used to convert the query params to the types of the fields. By default
+// the FilterSet uses the mapper of the ViewSet handling the request, see serializers.CtxFieldTypeMapper, so the
+// query params are converted the same way as the payloads.
+func (f *FilterSet[Model]) WithFieldTypeMapper(mapper *types.FieldTypeMapper) *FilterSet[Model] {
+	f.mapper = mapper
+	return f
+}
+
+// Parse extracts the conditions from the query params of the request. Query params that don't refer
+// to any declared field are ignored, so they can be used by other query mods (like pagination). Values
+// that can't be converted, as well as lookups that were not declared, result in a *serializers.ValidationError.
+func (f *FilterSet[Model]) Parse(ctx *gin.Context) ([]Condition, error) {
+	conditions := []Condition{}
+	validationErr := &serializers.ValidationError{FieldErrors: map[string][]string{}}
+	for param, values := range ctx.Request.URL.Query() {
+		field, path, lookup, ok := f.resolve(param)
+		if !ok {
+			continue
+		}
+		if !f.lookups[field][lookup] {
+			validationErr.FieldErrors[param] = []string{
+				fmt.Sprintf("Lookup `%s` is not allowed for field `%s`", lookup, field),
+			}
+			continue
+		}
+		if pathErr := f.validatePath(field, path); pathErr != nil {
+			validationErr.FieldErrors[param] = []string{pathErr.Error()}
+			continue
+		}
+		value, convertErr := f.convertLookupValue(f.fieldTypeMapper(ctx), field, path, lookup, values[0])
+		if convertErr != nil {
+			validationErr.FieldErrors[param] = []string{convertErr.Error()}
+			continue
+		}
+		conditions = append(conditions, Condition{
+			Field:   field,
+			GoField: f.goFields[field].Name,
+			Path:    path,
+			Lookup:  lookup,
+			Value:   value,
+		})
+	}
+	if len(validationErr.FieldErrors) > 0 {
+		return nil, validationErr
+	}
+	return conditions, nil
+}
+
+// resolve splits the query param into the field name, the path inside JSON documents and the lookup
+func (f *FilterSet[Model]) resolve(param string) (string, []string, Lookup, bool) {
+	if _, ok := f.lookups[param]; ok {
+		return param, nil, Exact, true
+	}
+	for field := range f.lookups {
+		if !f.isJSONDocument(field) || !strings.HasPrefix(param, field+lookupSeparator) {
+			continue
+		}
+		path := strings.Split(param[len(field)+len(lookupSeparator):], lookupSeparator)
+		if last := Lookup(path[len(path)-1]); len(path) > 1 && isSupported(last) {
+			return field, path[:len(path)-1], last, true
+		}
+		return field, path, Exact, true
+	}
+	separatorIndex := strings.LastIndex(param, lookupSeparator)
+	if separatorIndex == -1 {
+		return "", nil, "", false
+	}
+	field := param[:separatorIndex]
+	if _, ok := f.lookups[field]; !ok {
+		return "", nil, "", false
+	}
+	return field, nil, Lookup(param[separatorIndex+len(lookupSeparator):]), true
+}
+
+var jsonKeyRegexp = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// validatePath makes sure that JSON documents are filtered by their keys, and that the keys are safe to
+// be used in the JSON paths of the database queries
+func (f *FilterSet[Model]) validatePath(field string, path []string) error {
+	if !f.isJSONDocument(field) {
+		return nil
+	}
+	if len(path) == 0 {
+		return fmt.Errorf("Field `%s` can only be filtered by its keys, for example `%s__key`", field, field)
+	}
+	for _, key := range path {
+		if !jsonKeyRegexp.MatchString(key) {
+			return fmt.Errorf("`%s` is not a valid key of field `%s`", key, field)
+		}
+	}
+	return nil
+}
+
+func (f *FilterSet[Model]) convertLookupValue(
+	mapper *types.FieldTypeMapper, field string, path []string, lookup Lookup, raw string,
+) (any, error) {
+	switch lookup {
+	case IsNull:
+		isNull, parseErr := strconv.ParseBool(raw)
+		if parseErr != nil {
+			return nil, fmt.Errorf("`%s` is not a valid boolean", raw)
+		}
+		return isNull, nil
+	case In, Range:
+		rawValues := strings.Split(raw, ",")
+		if lookup == Range && len(rawValues) != 2 {
+			return nil, fmt.Errorf("Range requires exactly two comma separated values")
+		}
+		values := make([]any, 0, len(rawValues))
+		for _, rawValue := range rawValues {
+			value, convertErr := f.convertValue(mapper, field, path, rawValue)
+			if convertErr != nil {
+				return nil, convertErr
+			}
+			values = append(values, value)
+		}
+		return values, nil
+	case Contains, IContains, IExact:
+		// Pattern lookups compare text, so the value is not converted to the type of the field
+		return raw, nil
+	}
+	return f.convertValue(mapper, field, path, raw)
+}
+
+// convertValue converts the query param using the field type mapper. The mapper expects values decoded from
+// JSON, so if the raw string is not accepted, it's decoded as JSON first, so `?price=10` becomes float64(10).
+// Types that the mapper can't convert from strings are supported if they implement encoding.TextUnmarshaler.
+// The keys of JSON documents can hold values of any type, so the raw string is decoded as JSON if possible.
+func (f *FilterSet[Model]) convertValue(mapper *types.FieldTypeMapper, field string, path []string, raw string) (any, error) {
+	if len(path) > 0 {
+		var decoded any
+		if json.Unmarshal([]byte(raw), &decoded) == nil {
+			return decoded, nil
+		}
+		return raw, nil
+	}
+	fieldType := valueType(f.goFields[field].Type)
+	var mapperErr error
+	if convert, noConverterErr := mapper.ToInternalValue(fieldType.String()); noConverterErr == nil {
+		value, convertErr := convert(raw)
+		if convertErr == nil {
+			return value, nil
+		}
+		mapperErr = convertErr
+		var decoded any
+		if json.Unmarshal([]byte(raw), &decoded) == nil {
+			value, decodedConvertErr := convert(decoded)
+			if decodedConvertErr == nil {
+				return value, nil
+			}
+			mapperErr = decodedConvertErr
+		}
+	}
+	ptr := reflect.New(fieldType)
+	if unmarshaler, ok := ptr.Interface().(encoding.TextUnmarshaler); ok {
+		if unmarshalErr := unmarshaler.UnmarshalText([]byte(raw)); unmarshalErr != nil {
+			return nil, fmt.Errorf("`%s` is not a valid value: %s", raw, unmarshalErr)
+		}
+		return ptr.Elem().Interface(), nil
+	}
+	if mapperErr != nil {
+		return nil, fmt.Errorf("`%s` is not a valid value: %s", raw, mapperErr)
+	}
+	return nil, fmt.Errorf("Filtering by type `%s` is not supported", fieldType)
+}
+
+// ConvertToField converts the value to the type of the model field (JSON name) using the mapper, the way the
+// query params are converted, so for example the string `sub` claim of a JWT can be compared with a uint
+// column. Values that already have the type of the field are returned as they are.
+func ConvertToField[Model any](mapper *types.FieldTypeMapper, field string, value any) (any, error) {
+	filterSet := NewFilterSet[Model]().WithFieldTypeMapper(mapper)
+	goField, ok := filterSet.goFields[field]
+	if !ok {
+		var m Model
+		return nil, fmt.Errorf("Could not find field `%s` on model `%s`", field, reflect.TypeOf(m))
+	}
+	if value == nil || reflect.TypeOf(value) == valueType(goField.Type) {
+		return value, nil
+	}
+	return filterSet.convertValue(mapper, field, nil, fmt.Sprint(value))
+}
+
+// fieldTypeMapper returns the mapper set using WithFieldTypeMapper, or the one of the ViewSet handling the
+// request. The types of the fields are checked in WithField without the request, so types registered only in
+// the mapper of the ViewSet require WithFieldTypeMapper to be called before WithField.
+func (f *FilterSet[Model]) fieldTypeMapper(ctx *gin.Context) *types.FieldTypeMapper {
+	if f.mapper != nil {
+		return f.mapper
+	}
+	return serializers.CtxFieldTypeMapper(ctx)
+}
+
+func (f *FilterSet[Model]) isConvertible(fieldType reflect.Type) bool {
+	if f.fieldTypeMapper(nil).IsRegistered(fieldType.String()) {
+		return true
+	}
+	_, ok := reflect.New(fieldType).Interface().(encoding.TextUnmarshaler)
+	return ok
+}
+
+// isJSONDocument tells if the field is stored as a JSON document, which can be filtered by its keys
+func (f *FilterSet[Model]) isJSONDocument(field string) bool {
+	_, ok := reflect.New(f.goFields[field].Type).Elem().Interface().(models.JSONDocument)
+	return ok
+}
+
+// valueType returns the type the values of the field are converted to. Pointers are dereferenced and nullable
+// types are unwrapped, so both *int and sql.NullInt64 fields are filtered using ints.
+func valueType(fieldType reflect.Type) reflect.Type {
+	for fieldType.Kind() == reflect.Pointer {
+		fieldType = fieldType.Elem()
+	}
+	if valueField, ok := nullableValueField(fieldType); ok {
+		return fieldType.Field(valueField).Type
+	}
+	return fieldType
+}
+
+// nullableValueField returns the index of the field holding the value of the nullable types, like
+// sql.NullString, sql.Null[T] or gorm.DeletedAt, which are structs with the value and a `Valid` flag
+func nullableValueField(fieldType reflect.Type) (int, bool) {
+	if fieldType.Kind() != reflect.Struct || fieldType.NumField() != 2 {
+		return 0, false
+	}
+	for i := 0; i < 2; i++ {
+		valid := fieldType.Field(i)
+		if valid.Name == "Valid" && valid.Type.Kind() == reflect.Bool && fieldType.Field(1-i).IsExported() {
+			return 1 - i, true
+		}
+	}
+	return 0, false
+}
+
+func onlyIsNull(lookups []Lookup) bool {
+	for _, lookup := range lookups {
+		if lookup != IsNull {
+			return false
+		}
+	}
+	return true
+}
+
+func isSupported(lookup Lookup) bool {
+	for _, supported := range AllLookups {
+		if lookup == supported {
+			return true
+		}
+	}
+	return false
+}
+
+// NewFilterSet creates an empty FilterSet, use WithField to declare the filters
+func NewFilterSet[Model any]() *FilterSet[Model] {
+	var m Model
+	goFields := map[string]reflect.StructField{}
+	for _, field := range reflect.VisibleFields(reflect.TypeOf(m)) {
+		jsonTag := strings.Split(field.Tag.Get("json"), ",")[0]
+		if field.Anonymous || jsonTag == "" || jsonTag == "-" {
+			continue
+		}
+		goFields[jsonTag] = field
+	}
+	return &FilterSet[Model]{
+		lookups:  map[string]map[Lookup]bool{},
+		goFields: goFields,
+	}
+}
@@ -0,0 +1,398 @@
+package filters
+
+import (
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/glothriel/grf/pkg/models"
+	"github.com/glothriel/grf/pkg/serializers"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+type mockModel struct {
+	ID        uint      `json:"id"`
+	Name      string    `json:"name"`
+	Price     float64   `json:"price"`
+	Active    bool      `json:"active"`
+	Ref       uuid.UUID `json:"ref"`
+	CreatedAt time.Time `json:"created_at"`
+
+	Attributes models.JSONField[map[string]any] `json:"attributes"`
+}
+
+func mockFilterSet() *FilterSet[mockModel] {
+	return NewFilterSet[mockModel]().
+		WithField("id", Exact, In).
+		WithField("name", Exact, IExact, Contains, IContains).
+		WithField("price", Gt, Gte, Lt, Lte, Range).
+		WithField("active").
+		WithField("ref", Exact, IsNull).
+		WithField("created_at", Gte).
+		WithField("attributes", Exact, Gte, In)
+}
+
+func ctxWithQuery(query string) *gin.Context {
+	ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+	ctx.Request, _ = http.NewRequest(http.MethodGet, "/?"+query, nil)
+	return ctx
+}
+
+func TestParse(t *testing.T) {
+	ref := uuid.MustParse("4c2b9e4e-7a3c-4a3e-9f3b-0b6a6c2e1b7d")
+	tests := []struct {
+		name           string
+		query          string
+		wantConditions []Condition
+		wantErrors     map[string][]string
+	}{
+		{
+			name:           "no params",
+			query:          "",
+			wantConditions: []Condition{},
+		},
+		{
+			name:           "unrelated params are ignored",
+			query:          "limit=10&foo__bar=baz",
+			wantConditions: []Condition{},
+		},
+		{
+			name:           "exact without suffix",
+			query:          "name=foo",
+			wantConditions: []Condition{{Field: "name", GoField: "Name", Lookup: Exact, Value: "foo"}},
+		},
+		{
+			name:           "exact with suffix",
+			query:          "id__exact=3",
+			wantConditions: []Condition{{Field: "id", GoField: "ID", Lookup: Exact, Value: uint(3)}},
+		},
+		{
+			name:           "number",
+			query:          "price__gte=10.5",
+			wantConditions: []Condition{{Field: "price", GoField: "Price", Lookup: Gte, Value: 10.5}},
+		},
+		{
+			name:           "bool",
+			query:          "active=true",
+			wantConditions: []Condition{{Field: "active", GoField: "Active", Lookup: Exact, Value: true}},
+		},
+		{
+			name:           "text unmarshaler",
+			query:          "ref=" + ref.String(),
+			wantConditions: []Condition{{Field: "ref", GoField: "Ref", Lookup: Exact, Value: ref}},
+		},
+		{
+			name:  "time",
+			query: "created_at__gte=2024-01-02T03:04:05Z",
+			wantConditions: []Condition{{
+				Field: "created_at", GoField: "CreatedAt", Lookup: Gte,
+				Value: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+			}},
+		},
+		{
+			name:           "in",
+			query:          "id__in=1,2,3",
+			wantConditions: []Condition{{Field: "id", GoField: "ID", Lookup: In, Value: []any{uint(1), uint(2), uint(3)}}},
+		},
+		{
+			name:           "range",
+			query:          "price__range=1,2",
+			wantConditions: []Condition{{Field: "price", GoField: "Price", Lookup: Range, Value: []any{1.0, 2.0}}},
+		},
+		{
+			name:           "isnull",
+			query:          "ref__isnull=false",
+			wantConditions: []Condition{{Field: "ref", GoField: "Ref", Lookup: IsNull, Value: false}},
+		},
+		{
+			name:           "contains keeps the raw value",
+			query:          "name__icontains=Fo",
+			wantConditions: []Condition{{Field: "name", GoField: "Name", Lookup: IContains, Value: "Fo"}},
+		},
+		{
+			name:  "json document key",
+			query: "attributes__color=red",
+			wantConditions: []Condition{{
+				Field: "attributes", GoField: "Attributes", Path: []string{"color"}, Lookup: Exact, Value: "red",
+			}},
+		},
+		{
+			name:  "json document nested key with lookup",
+			query: "attributes__size__width__gte=10",
+			wantConditions: []Condition{{
+				Field: "attributes", GoField: "Attributes", Path: []string{"size", "width"}, Lookup: Gte, Value: 10.0,
+			}},
+		},
+		{
+			name:  "json document key values are decoded",
+			query: "attributes__tag__in=true,null,foo",
+			wantConditions: []Condition{{
+				Field: "attributes", GoField: "Attributes", Path: []string{"tag"}, Lookup: In,
+				Value: []any{true, nil, "foo"},
+			}},
+		},
+		{
+			name:       "json document without key",
+			query:      "attributes=red",
+			wantErrors: map[string][]string{"attributes": {"Field `attributes` can only be filtered by its keys, for example `attributes__key`"}},
+		},
+		{
+			name:       "json document invalid key",
+			query:      "attributes__co.lor=red",
+			wantErrors: map[string][]string{"attributes__co.lor": {"`co.lor` is not a valid key of field `attributes`"}},
+		},
+		{
+			name:       "json document lookup not declared",
+			query:      "attributes__size__lt=10",
+			wantErrors: map[string][]string{"attributes__size__lt": {"Lookup `lt` is not allowed for field `attributes`"}},
+		},
+		{
+			name:       "invalid number",
+			query:      "price__gt=abc",
+			wantErrors: map[string][]string{"price__gt": {"`abc` is not a valid value: Error converting request value to internal value for type `float64`: Expected type `float64`, got `string`"}},
+		},
+		{
+			name:       "negative uint",
+			query:      "id=-1",
+			wantErrors: map[string][]string{"id": {"`-1` is not a valid value: Error converting request value to internal value for type `uint`: Value -1.000000 is not an integer"}},
+		},
+		{
+			name:       "invalid item of in",
+			query:      "id__in=1,a",
+			wantErrors: map[string][]string{"id__in": {"`a` is not a valid value: Error converting request value to internal value for type `uint`: Expected type `float64`, got `string`"}},
+		},
+		{
+			name:       "range with a single value",
+			query:      "price__range=1",
+			wantErrors: map[string][]string{"price__range": {"Range requires exactly two comma separated values"}},
+		},
+		{
+			name:       "invalid boolean",
+			query:      "ref__isnull=maybe",
+			wantErrors: map[string][]string{"ref__isnull": {"`maybe` is not a valid boolean"}},
+		},
+		{
+			name:       "lookup not declared",
+			query:      "price=10",
+			wantErrors: map[string][]string{"price": {"Lookup `exact` is not allowed for field `price`"}},
+		},
+		{
+			name:       "unknown lookup",
+			query:      "name__startswith=a",
+			wantErrors: map[string][]string{"name__startswith": {"Lookup `startswith` is not allowed for field `name`"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// given
+			ctx := ctxWithQuery(tt.query)
+
+			// when
+			conditions, parseErr := mockFilterSet().Parse(ctx)
+
+			// then
+			if tt.wantErrors != nil {
+				assert.Equal(t, &serializers.ValidationError{FieldErrors: tt.wantErrors}, parseErr)
+				return
+			}
+			assert.NoError(t, parseErr)
+			assert.Equal(t, tt.wantConditions, conditions)
+		})
+	}
+}
+
+func TestWithFieldPanicsOnUnknownField(t *testing.T) {
+	assert.Panics(t, func() {
+		NewFilterSet[mockModel]().WithField("unknown")
+	})
+}
+
+func TestWithFieldPanicsOnUnsupportedLookup(t *testing.T) {
+	assert.Panics(t, func() {
+		NewFilterSet[mockModel]().WithField("name", Lookup("startswith"))
+	})
+}
+
+func TestMatch(t *testing.T) {
+	intVal := models.InternalValue{
+		"id":         uint(2),
+		"name":       "Foo_bar",
+		"price":      12.5,
+		"cost":       decimal.RequireFromString("12.50"),
+		"budget":     models.NewMoney(decimal.RequireFromString("12.50"), "USD"),
+		"created_at": time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC),
+		"ref":        nil,
+		"attributes": models.NewJSONField(map[string]any{
+			"color": "red", "size": map[string]any{"width": 10}, "new": true,
+		}),
+	}
+	tests := []struct {
+		name      string
+		condition Condition
+		want      bool
+	}{
+		{"exact", Condition{Field: "id", Lookup: Exact, Value: uint(2)}, true},
+		{"exact mismatch", Condition{Field: "id", Lookup: Exact, Value: uint(3)}, false},
+		{"exact different numeric types", Condition{Field: "id", Lookup: Exact, Value: 2}, true},
+		{"iexact", Condition{Field: "name", Lookup: IExact, Value: "foo_BAR"}, true},
+		{"contains", Condition{Field: "name", Lookup: Contains, Value: "o_b"}, true},
+		{"contains is case sensitive", Condition{Field: "name", Lookup: Contains, Value: "foo"}, false},
+		{"icontains", Condition{Field: "name", Lookup: IContains, Value: "FOO"}, true},
+		{"gt", Condition{Field: "price", Lookup: Gt, Value: 12.5}, false},
+		{"gte", Condition{Field: "price", Lookup: Gte, Value: 12.5}, true},
+		{"lt", Condition{Field: "price", Lookup: Lt, Value: 13.0}, true},
+		{"lte", Condition{Field: "price", Lookup: Lte, Value: 12.0}, false},
+		{"gt string", Condition{Field: "name", Lookup: Gt, Value: "A"}, true},
+		{
+			"gte time",
+			Condition{Field: "created_at", Lookup: Gte, Value: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)},
+			true,
+		},
+		{"in", Condition{Field: "id", Lookup: In, Value: []any{uint(1), uint(2)}}, true},
+		{"not in", Condition{Field: "id", Lookup: In, Value: []any{uint(1), uint(3)}}, false},
+		{"range", Condition{Field: "price", Lookup: Range, Value: []any{12.5, 20.0}}, true},
+		{"outside range", Condition{Field: "price", Lookup: Range, Value: []any{1.0, 12.0}}, false},
+		{"gte decimal", Condition{Field: "cost", Lookup: Gte, Value: decimal.RequireFromString("10")}, true},
+		{"lt decimal", Condition{Field: "cost", Lookup: Lt, Value: decimal.RequireFromString("12.5")}, false},
+		{
+			"decimal range",
+			Condition{Field: "cost", Lookup: Range, Value: []any{decimal.RequireFromString("10"), decimal.RequireFromString("12.5")}},
+			true,
+		},
+		{
+			"outside decimal range",
+			Condition{Field: "cost", Lookup: Range, Value: []any{decimal.RequireFromString("1"), decimal.RequireFromString("12.49")}},
+			false,
+		},
+		{
+			"gt money",
+			Condition{Field: "budget", Lookup: Gt, Value: models.NewMoney(decimal.RequireFromString("12.49"), "USD")},
+			true,
+		},
+		{
+			"money in other currency",
+			Condition{Field: "budget", Lookup: Gt, Value: models.NewMoney(decimal.RequireFromString("1"), "EUR")},
+			false,
+		},
+		{"isnull", Condition{Field: "ref", Lookup: IsNull, Value: true}, true},
+		{"isnull false", Condition{Field: "name", Lookup: IsNull, Value: false}, true},
+		{"nil is never greater", Condition{Field: "ref", Lookup: Gt, Value: 1.0}, false},
+		{"json key", Condition{Field: "attributes", Path: []string{"color"}, Lookup: Exact, Value: "red"}, true},
+		{"json key mismatch", Condition{Field: "attributes", Path: []string{"color"}, Lookup: Exact, Value: "blue"}, false},
+		{"json bool key", Condition{Field: "attributes", Path: []string{"new"}, Lookup: Exact, Value: true}, true},
+		{
+			"json nested key",
+			Condition{Field: "attributes", Path: []string{"size", "width"}, Lookup: Gte, Value: 10.0},
+			true,
+		},
+		{"json missing key", Condition{Field: "attributes", Path: []string{"weight"}, Lookup: IsNull, Value: true}, true},
+		{
+			"json key of a scalar",
+			Condition{Field: "attributes", Path: []string{"color", "hue"}, Lookup: IsNull, Value: true},
+			true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Match(intVal, []Condition{tt.condition}))
+		})
+	}
+}
+
+func TestCheckOrderable(t *testing.T) {
+	// given
+	conditions := []Condition{
+		{Field: "price", Lookup: Gte, Value: decimal.RequireFromString("10")},
+		{Field: "created_at", Lookup: Range, Value: []any{time.Now(), time.Now()}},
+		{Field: "ref", Lookup: Exact, Value: uuid.New()},
+		{Field: "ref", Lookup: Gt, Value: uuid.New()},
+		{Field: "attributes", Path: []string{"new"}, Lookup: Lte, Value: true},
+	}
+
+	// when
+	checkErr := CheckOrderable(conditions)
+
+	// then
+	assert.Equal(t, &serializers.ValidationError{FieldErrors: map[string][]string{
+		"ref__gt":              {"Lookup `gt` is not supported for values of type `uuid.UUID`"},
+		"attributes__new__lte": {"Lookup `lte` is not supported for values of type `bool`"},
+	}}, checkErr)
+}
+
+type nullableModel struct {
+	ID        uint           `json:"id"`
+	Stock     *int           `json:"stock"`
+	ExpiresAt *time.Time     `json:"expires_at"`
+	Nickname  sql.NullString `json:"nickname"`
+	DeletedAt gorm.DeletedAt `json:"deleted_at"`
+	Tags      []string       `json:"tags"`
+}
+
+func TestParseNullableFields(t *testing.T) {
+	// given
+	filterSet := NewFilterSet[nullableModel]().
+		WithField("stock", Gte, IsNull).
+		WithField("expires_at", Lt).
+		WithField("nickname", Exact).
+		WithField("deleted_at", IsNull).
+		WithField("tags", IsNull)
+	ctx := ctxWithQuery(
+		"stock__gte=5&expires_at__lt=2024-01-02T00:00:00Z&nickname=foo&deleted_at__isnull=true&tags__isnull=false",
+	)
+
+	// when
+	conditions, parseErr := filterSet.Parse(ctx)
+
+	// then
+	assert.NoError(t, parseErr)
+	assert.ElementsMatch(t, []Condition{
+		{Field: "stock", GoField: "Stock", Lookup: Gte, Value: 5},
+		{Field: "expires_at", GoField: "ExpiresAt", Lookup: Lt, Value: time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)},
+		{Field: "nickname", GoField: "Nickname", Lookup: Exact, Value: "foo"},
+		{Field: "deleted_at", GoField: "DeletedAt", Lookup: IsNull, Value: true},
+		{Field: "tags", GoField: "Tags", Lookup: IsNull, Value: false},
+	}, conditions)
+}
+
+func TestWithFieldPanicsOnUnsupportedTypeUnlessIsNull(t *testing.T) {
+	assert.Panics(t, func() {
+		NewFilterSet[nullableModel]().WithField("tags", Exact, IsNull)
+	})
+}
+
+func TestMatchNullableFields(t *testing.T) {
+	stock := 7
+	expiresAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	intVal := models.InternalValue{
+		"stock":      &stock,
+		"expires_at": &expiresAt,
+		"nickname":   sql.NullString{String: "foo", Valid: true},
+		"deleted_at": gorm.DeletedAt{},
+		"missing":    (*int)(nil),
+	}
+	tests := []struct {
+		name      string
+		condition Condition
+		want      bool
+	}{
+		{"pointer gte", Condition{Field: "stock", Lookup: Gte, Value: 5}, true},
+		{"pointer lt", Condition{Field: "stock", Lookup: Lt, Value: 5}, false},
+		{"pointer isnull", Condition{Field: "stock", Lookup: IsNull, Value: false}, true},
+		{"time pointer", Condition{Field: "expires_at", Lookup: Lt, Value: expiresAt.Add(time.Hour)}, true},
+		{"null type", Condition{Field: "nickname", Lookup: Exact, Value: "foo"}, true},
+		{"invalid null type isnull", Condition{Field: "deleted_at", Lookup: IsNull, Value: true}, true},
+		{"nil pointer isnull", Condition{Field: "missing", Lookup: IsNull, Value: true}, true},
+		{"nil pointer is never equal", Condition{Field: "missing", Lookup: Exact, Value: 0}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Match(intVal, []Condition{tt.condition}))
+		})
+	}
+}
@@ -0,0 +1,270 @@
+package filters
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/glothriel/grf/pkg/models"
+	"github.com/glothriel/grf/pkg/serializers"
+)
+
+// Match checks if the internal value satisfies all the conditions. It's used by query drivers that
+// can't translate the conditions to a query, like the in-memory one.
+func Match(intVal models.InternalValue, conditions []Condition) bool {
+	for _, condition := range conditions {
+		value := nullableValue(intVal[condition.Field])
+		if len(condition.Path) > 0 {
+			value = documentValue(value, condition.Path)
+		}
+		if !matchCondition(value, condition) {
+			return false
+		}
+	}
+	return true
+}
+
+// nullableValue dereferences pointers and unwraps nullable types, like sql.NullInt64 or gorm.DeletedAt, so
+// they are compared using the values they hold. Nil pointers and invalid nullable values are returned as nil.
+func nullableValue(value any) any {
+	reflected := reflect.ValueOf(value)
+	for reflected.Kind() == reflect.Pointer {
+		if reflected.IsNil() {
+			return nil
+		}
+		reflected = reflected.Elem()
+	}
+	if !reflected.IsValid() {
+		return nil
+	}
+	if valueField, ok := nullableValueField(reflected.Type()); ok {
+		if !reflected.FieldByName("Valid").Bool() {
+			return nil
+		}
+		return reflected.Field(valueField).Interface()
+	}
+	return reflected.Interface()
+}
+
+// documentValue returns the value stored under the path in the JSON document, or nil if there's none
+func documentValue(value any, path []string) any {
+	document, ok := value.(models.JSONDocument)
+	if !ok || isNil(value) {
+		return nil
+	}
+	encoded, marshalErr := json.Marshal(document.JSONData())
+	if marshalErr != nil {
+		return nil
+	}
+	var current any
+	if unmarshalErr := json.Unmarshal(encoded, &current); unmarshalErr != nil {
+		return nil
+	}
+	for _, key := range path {
+		object, isObject := current.(map[string]any)
+		if !isObject {
+			return nil
+		}
+		current = object[key]
+	}
+	return current
+}
+
+func matchCondition(value any, condition Condition) bool {
+	switch condition.Lookup {
+	case Exact:
+		return compare(value, condition.Value) == 0
+	case IExact:
+		return strings.EqualFold(fmt.Sprint(value), fmt.Sprint(condition.Value))
+	case Contains:
+		return value != nil && strings.Contains(fmt.Sprint(value), fmt.Sprint(condition.Value))
+	case IContains:
+		return value != nil && strings.Contains(
+			strings.ToLower(fmt.Sprint(value)), strings.ToLower(fmt.Sprint(condition.Value)),
+		)
+	case Gt:
+		return compare(value, condition.Value) == 1
+	case Gte:
+		result := compare(value, condition.Value)
+		return result == 0 || result == 1
+	case Lt:
+		return compare(value, condition.Value) == -1
+	case Lte:
+		result := compare(value, condition.Value)
+		return result == 0 || result == -1
+	case In:
+		for _, option := range condition.Value.([]any) {
+			if compare(value, option) == 0 {
+				return true
+			}
+		}
+		return false
+	case Range:
+		bounds := condition.Value.([]any)
+		lower, upper := compare(value, bounds[0]), compare(value, bounds[1])
+		return (lower == 0 || lower == 1) && (upper == 0 || upper == -1)
+	case IsNull:
+		return isNil(value) == condition.Value.(bool)
+	}
+	return false
+}
+
+// incomparable is returned by compare when the values can't be ordered
+const incomparable = 2
+
+// compare returns -1, 0 or 1 if a is less than, equal to or greater than b, or incomparable
+func compare(a, b any) int {
+	if isNil(a) || isNil(b) {
+		return incomparable
+	}
+	if aNum, aOk := asFloat(a); aOk {
+		if bNum, bOk := asFloat(b); bOk {
+			return compareOrdered(aNum, bNum)
+		}
+		return incomparable
+	}
+	if aMoney, aOk := a.(models.Money); aOk {
+		if bMoney, bOk := b.(models.Money); bOk && aMoney.Currency == bMoney.Currency {
+			return aMoney.Amount.Cmp(bMoney.Amount)
+		}
+		return incomparable
+	}
+	if result, ok := compareWithCmp(a, b); ok {
+		return result
+	}
+	if aTime, aOk := a.(time.Time); aOk {
+		if bTime, bOk := b.(time.Time); bOk {
+			return aTime.Compare(bTime)
+		}
+		return incomparable
+	}
+	if aValue := reflect.ValueOf(a); aValue.Kind() == reflect.String {
+		if bValue := reflect.ValueOf(b); bValue.Kind() == reflect.String {
+			return compareOrdered(aValue.String(), bValue.String())
+		}
+		return incomparable
+	}
+	if reflect.TypeOf(a).Comparable() && reflect.TypeOf(a) == reflect.TypeOf(b) && a == b {
+		return 0
+	}
+	if fmt.Sprint(a) == fmt.Sprint(b) {
+		return 0
+	}
+	return incomparable
+}
+
+// compareWithCmp compares the values using the `Cmp(T) int` method of a, like the one of decimal.Decimal
+func compareWithCmp(a, b any) (int, bool) {
+	method := reflect.ValueOf(a).MethodByName("Cmp")
+	if !method.IsValid() || !isCmpMethod(method.Type(), reflect.TypeOf(a)) {
+		return 0, false
+	}
+	if reflect.TypeOf(b) != reflect.TypeOf(a) {
+		return incomparable, true
+	}
+	result := int(method.Call([]reflect.Value{reflect.ValueOf(b)})[0].Int())
+	if result < 0 {
+		return -1, true
+	}
+	if result > 0 {
+		return 1, true
+	}
+	return 0, true
+}
+
+func isCmpMethod(methodType, receiverType reflect.Type) bool {
+	return methodType.NumIn() == 1 && methodType.In(0) == receiverType &&
+		methodType.NumOut() == 1 && methodType.Out(0).Kind() == reflect.Int
+}
+
+// isOrderable tells if compare can order the values of the type, not only check them for equality
+func isOrderable(v any) bool {
+	if isNil(v) {
+		return false
+	}
+	if _, ok := asFloat(v); ok {
+		return true
+	}
+	switch v.(type) {
+	case time.Time, models.Money:
+		return true
+	}
+	if reflect.ValueOf(v).Kind() == reflect.String {
+		return true
+	}
+	method := reflect.ValueOf(v).MethodByName("Cmp")
+	return method.IsValid() && isCmpMethod(method.Type(), reflect.TypeOf(v))
+}
+
+// CheckOrderable makes sure that the values of the Gt, Gte, Lt, Lte and Range conditions can be ordered by
+// Match. Query drivers using Match call it after parsing the conditions, as otherwise such conditions would
+// never match. The errors are returned as a *serializers.ValidationError keyed by the query params.
+func CheckOrderable(conditions []Condition) error {
+	validationErr := &serializers.ValidationError{FieldErrors: map[string][]string{}}
+	for _, condition := range conditions {
+		var values []any
+		switch condition.Lookup {
+		case Gt, Gte, Lt, Lte:
+			values = []any{condition.Value}
+		case Range:
+			values = condition.Value.([]any)
+		default:
+			continue
+		}
+		for _, value := range values {
+			if !isOrderable(value) {
+				validationErr.FieldErrors[condition.param()] = []string{
+					fmt.Sprintf("Lookup `%s` is not supported for values of type `%T`", condition.Lookup, value),
+				}
+				break
+			}
+		}
+	}
+	if len(validationErr.FieldErrors) > 0 {
+		return validationErr
+	}
+	return nil
+}
+
+// param returns the query param the condition was parsed from
+func (c Condition) param() string {
+	parts := append([]string{c.Field}, c.Path...)
+	return strings.Join(append(parts, string(c.Lookup)), lookupSeparator)
+}
+
+func compareOrdered[T float64 | string](a, b T) int {
+	if a < b {
+		return -1
+	}
+	if a > b {
+		return 1
+	}
+	return 0
+}
+
+func asFloat(v any) (float64, bool) {
+	value := reflect.ValueOf(v)
+	switch value.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(value.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(value.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return value.Float(), true
+	}
+	return 0, false
+}
+
+func isNil(v any) bool {
+	if v == nil {
+		return true
+	}
+	value := reflect.ValueOf(v)
+	switch value.Kind() {
+	case reflect.Pointer, reflect.Map, reflect.Slice, reflect.Interface:
+		return value.IsNil()
+	}
+	return false
+}
@@ -0,0 +1,114 @@
+package filters
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/glothriel/grf/pkg/detectors"
+	"github.com/glothriel/grf/pkg/models"
+	"github.com/glothriel/grf/pkg/serializers"
+	"github.com/sirupsen/logrus"
+)
+
+// DefaultOrderingParam is the name of the query param used by Ordering
+const DefaultOrderingParam = "ordering"
+
+// OrderingTerm is a single field the results are sorted by
+type OrderingTerm struct {
+	// Field is the JSON name of the model field
+	Field string
+	// GoField is the name of the model struct field
+	GoField    string
+	Descending bool
+}
+
+// Ordering allows the clients to sort the results using a query param, like `?ordering=-price,name`.
+// Only whitelisted fields are accepted, a `-` prefix means descending order.
+type Ordering[Model any] struct {
+	param           string
+	allowed         map[string]string
+	defaultOrdering []OrderingTerm
+}
+
+// WithDefault sets the ordering used when the query param is absent, using the same format as the
+// query param, for example `WithDefault("-created_at", "id")`
+func (o *Ordering[Model]) WithDefault(fields ...string) *Ordering[Model] {
+	terms, parseErr := o.parseTerms(fields)
+	if parseErr != nil {
+		logrus.Panicf("Invalid default ordering: %s", parseErr)
+	}
+	o.defaultOrdering = terms
+	return o
+}
+
+// WithParam changes the name of the query param, `ordering` by default
+func (o *Ordering[Model]) WithParam(param string) *Ordering[Model] {
+	o.param = param
+	return o
+}
+
+// Parse returns the ordering requested by the client, or the default one if the query param is absent.
+// Fields that are not whitelisted result in a *serializers.ValidationError.
+func (o *Ordering[Model]) Parse(ctx *gin.Context) ([]OrderingTerm, error) {
+	raw := ctx.Query(o.param)
+	if raw == "" {
+		return o.defaultOrdering, nil
+	}
+	terms, parseErr := o.parseTerms(strings.Split(raw, ","))
+	if parseErr != nil {
+		return nil, &serializers.ValidationError{FieldErrors: map[string][]string{o.param: {parseErr.Error()}}}
+	}
+	return terms, nil
+}
+
+func (o *Ordering[Model]) parseTerms(fields []string) ([]OrderingTerm, error) {
+	terms := []OrderingTerm{}
+	for _, field := range fields {
+		field = strings.TrimSpace(field)
+		descending := strings.HasPrefix(field, "-")
+		field = strings.TrimPrefix(field, "-")
+		goField, ok := o.allowed[field]
+		if !ok {
+			return nil, fmt.Errorf("Ordering by `%s` is not allowed", field)
+		}
+		terms = append(terms, OrderingTerm{Field: field, GoField: goField, Descending: descending})
+	}
+	return terms, nil
+}
+
+// NewOrdering creates an Ordering accepting the given JSON field names
+func NewOrdering[Model any](fields ...string) *Ordering[Model] {
+	fieldNames := detectors.FieldNames[Model]()
+	allowed := map[string]string{}
+	for _, field := range fields {
+		goField, ok := fieldNames[field]
+		if !ok || field == "" {
+			var m Model
+			logrus.Panicf("Could not find field `%s` on model `%s` when registering ordering", field, reflect.TypeOf(m))
+		}
+		allowed[field] = goField
+	}
+	return &Ordering[Model]{
+		param:           DefaultOrderingParam,
+		allowed:         allowed,
+		defaultOrdering: []OrderingTerm{},
+	}
+}
+
+// Sort sorts the internal values in place, it's used by query drivers that can't order the results
+// in a query, like the in-memory one. Values that can't be compared keep their relative order.
+func Sort(intVals []models.InternalValue, terms []OrderingTerm) {
+	sort.SliceStable(intVals, func(i, j int) bool {
+		for _, term := range terms {
+			result := compare(intVals[i][term.Field], intVals[j][term.Field])
+			if result == 0 || result == incomparable {
+				continue
+			}
+			return (result == -1) != term.Descending
+		}
+		return false
+	})
+}
@@ -0,0 +1,101 @@
+package filters
+
+import (
+	"testing"
+
+	"github.com/glothriel/grf/pkg/models"
+	"github.com/glothriel/grf/pkg/serializers"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOrderingParse(t *testing.T) {
+	tests := []struct {
+		name       string
+		query      string
+		ordering   *Ordering[mockModel]
+		wantTerms  []OrderingTerm
+		wantErrors map[string][]string
+	}{
+		{
+			name:      "absent param without default",
+			query:     "",
+			ordering:  NewOrdering[mockModel]("name"),
+			wantTerms: []OrderingTerm{},
+		},
+		{
+			name:      "absent param uses default",
+			query:     "",
+			ordering:  NewOrdering[mockModel]("name", "price").WithDefault("-price"),
+			wantTerms: []OrderingTerm{{Field: "price", GoField: "Price", Descending: true}},
+		},
+		{
+			name:     "multiple fields",
+			query:    "ordering=-price,name",
+			ordering: NewOrdering[mockModel]("name", "price").WithDefault("-price"),
+			wantTerms: []OrderingTerm{
+				{Field: "price", GoField: "Price", Descending: true},
+				{Field: "name", GoField: "Name", Descending: false},
+			},
+		},
+		{
+			name:      "custom param",
+			query:     "sort=name",
+			ordering:  NewOrdering[mockModel]("name").WithParam("sort"),
+			wantTerms: []OrderingTerm{{Field: "name", GoField: "Name"}},
+		},
+		{
+			name:       "field not whitelisted",
+			query:      "ordering=name,-id",
+			ordering:   NewOrdering[mockModel]("name"),
+			wantErrors: map[string][]string{"ordering": {"Ordering by `id` is not allowed"}},
+		},
+		{
+			name:       "raw SQL is rejected",
+			query:      "ordering=name%3BDROP%20TABLE%20products",
+			ordering:   NewOrdering[mockModel]("name"),
+			wantErrors: map[string][]string{"ordering": {"Ordering by `name;DROP TABLE products` is not allowed"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// given
+			ctx := ctxWithQuery(tt.query)
+
+			// when
+			terms, parseErr := tt.ordering.Parse(ctx)
+
+			// then
+			if tt.wantErrors != nil {
+				assert.Equal(t, &serializers.ValidationError{FieldErrors: tt.wantErrors}, parseErr)
+				return
+			}
+			assert.NoError(t, parseErr)
+			assert.Equal(t, tt.wantTerms, terms)
+		})
+	}
+}
+
+func TestNewOrderingPanicsOnUnknownField(t *testing.T) {
+	assert.Panics(t, func() {
+		NewOrdering[mockModel]("unknown")
+	})
+}
+
+func TestSort(t *testing.T) {
+	// given
+	intVals := []models.InternalValue{
+		{"id": uint(1), "name": "b", "price": 2.0},
+		{"id": uint(2), "name": "a", "price": 1.0},
+		{"id": uint(3), "name": "c", "price": 2.0},
+	}
+
+	// when
+	Sort(intVals, []OrderingTerm{{Field: "price", Descending: true}, {Field: "name"}})
+
+	// then
+	assert.Equal(t, []models.InternalValue{
+		{"id": uint(1), "name": "b", "price": 2.0},
+		{"id": uint(3), "name": "c", "price": 2.0},
+		{"id": uint(2), "name": "a", "price": 1.0},
+	}, intVals)
+}
@@ -2,6 +2,7 @@ package gormq
 
 import (
 	"github.com/gin-gonic/gin"
+	"github.com/glothriel/grf/pkg/queries/filters"
 	"github.com/sirupsen/logrus"
 	"gorm.io/gorm"
 )
@@ -38,3 +39,18 @@ func CtxQuery(ctx *gin.Context) *gorm.DB {
 func New(ctx *gin.Context) *gorm.DB {
 	return CtxGetFactory(ctx).Create(ctx)
 }
+
+// CtxSetOrdering stores the ordering requested by the client, so the pagination can take it into account
+func CtxSetOrdering(ctx *gin.Context, terms []filters.OrderingTerm) {
+	ctx.Set("db:gorm:ordering", terms)
+}
+
+// CtxOrdering returns the ordering requested by the client, or nil if WithOrdering is not used
+func CtxOrdering(ctx *gin.Context) []filters.OrderingTerm {
+	anyVal, ok := ctx.Get("db:gorm:ordering")
+	if !ok {
+		return nil
+	}
+	terms, _ := anyVal.([]filters.OrderingTerm)
+	return terms
+}
@@ -0,0 +1,420 @@
+package gormq
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/glothriel/grf/pkg/detectors"
+	"github.com/glothriel/grf/pkg/models"
+	"github.com/glothriel/grf/pkg/queries/common"
+	"github.com/glothriel/grf/pkg/queries/filters"
+	"github.com/glothriel/grf/pkg/serializers"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// DefaultCursorParam is the name of the query param used by CursorPagination
+const DefaultCursorParam = "cursor"
+
+// CursorPaginatedResponse is the envelope returned by CursorPagination, it does not contain the count, as
+// counting the rows is what keyset pagination is supposed to avoid
+type CursorPaginatedResponse struct {
+	Next     *string `json:"next"`
+	Previous *string `json:"previous"`
+	Results  []any   `json:"results"`
+}
+
+// CursorPagination (keyset pagination) uses the values of the last returned row to find the next page, so
+// its performance does not depend on how deep the client is, and inserts do not shift the pages. The
+// results are sorted by the ordering requested using WithOrdering (if any) followed by the key fields,
+// which together must uniquely identify a row and can't be NULL. NULLs can't be compared, so they would
+// break the keyset condition, the nullable fields (pointers and types like sql.NullString) are rejected both
+// as the key fields and in the ordering requested by the client. WithOrderBy is ignored.
+//
+// Apply fetches one row more than the page size to tell if there is a next page, ProcessList drops it and
+// builds the cursors. Custom List views not calling ProcessList get the cursors built by Format using the
+// representations, which then have to contain the ordering and key fields.
+//
+// The cursors are signed, so the clients can't craft their own, tampered cursors result in 400 Bad Request.
+type CursorPagination[Model any] struct {
+	secret      []byte
+	keys        []filters.OrderingTerm
+	param       string
+	pageSize    int
+	maxPageSize int
+}
+
+// NewCursorPagination creates a CursorPagination ordering by the given JSON field names, using the same
+// format as Ordering, for example `NewCursorPagination[Post](secret, "-created_at", "-id")`. The secret
+// is used to sign the cursors.
+func NewCursorPagination[Model any](secret []byte, keys ...string) *CursorPagination[Model] {
+	var m Model
+	if len(secret) == 0 {
+		logrus.Panicf("CursorPagination for model `%s` requires a secret", reflect.TypeOf(m))
+	}
+	if len(keys) == 0 {
+		logrus.Panicf("CursorPagination for model `%s` requires at least one key field", reflect.TypeOf(m))
+	}
+	fieldNames := detectors.FieldNames[Model]()
+	terms := []filters.OrderingTerm{}
+	for _, key := range keys {
+		descending := strings.HasPrefix(key, "-")
+		field := strings.TrimPrefix(key, "-")
+		goField, ok := fieldNames[field]
+		if !ok || field == "" {
+			logrus.Panicf("Could not find field `%s` on model `%s` when registering cursor pagination", field, reflect.TypeOf(m))
+		}
+		if isNullableField[Model](goField) {
+			logrus.Panicf("Field `%s` on model `%s` can be null and can't be a cursor pagination key", field, reflect.TypeOf(m))
+		}
+		terms = append(terms, filters.OrderingTerm{Field: field, GoField: goField, Descending: descending})
+	}
+	return &CursorPagination[Model]{
+		secret:   secret,
+		keys:     terms,
+		param:    DefaultCursorParam,
+		pageSize: DefaultPageSize,
+	}
+}
+
+// WithPageSize sets the page size used when the `page_size` query param is absent
+func (p *CursorPagination[Model]) WithPageSize(pageSize int) *CursorPagination[Model] {
+	p.pageSize = pageSize
+	return p
+}
+
+// WithMaxPageSize caps the page size requested by the client
+func (p *CursorPagination[Model]) WithMaxPageSize(maxPageSize int) *CursorPagination[Model] {
+	p.maxPageSize = maxPageSize
+	return p
+}
+
+// WithParam changes the name of the query param, `cursor` by default
+func (p *CursorPagination[Model]) WithParam(param string) *CursorPagination[Model] {
+	p.param = param
+	return p
+}
+
+// cursor is the position between two rows. Backward cursors point at the rows before the position.
+type cursor struct {
+	Fields   []string          `json:"f"`
+	Values   []json.RawMessage `json:"v"`
+	Backward bool              `json:"b,omitempty"`
+}
+
+type cursorPaginationState struct {
+	backward  bool
+	hasCursor bool
+	pageSize  int
+	terms     []filters.OrderingTerm
+	// processed is set by ProcessList, otherwise Format trims the page and builds the cursors
+	processed bool
+	next      *string
+	previous  *string
+}
+
+func (p *CursorPagination[Model]) Apply(c *gin.Context, db *gorm.DB) *gorm.DB {
+	pageSize, pageSizeErr := queryParamInt(c, "page_size", p.pageSize, 1)
+	if pageSizeErr != nil {
+		_ = db.AddError(pageSizeErr)
+		return db
+	}
+	if p.maxPageSize > 0 && pageSize > p.maxPageSize {
+		pageSize = p.maxPageSize
+	}
+	terms := p.orderingTerms(c)
+	for _, term := range CtxOrdering(c) {
+		if isNullableField[Model](term.GoField) {
+			_ = db.AddError(serializers.NewValidationError().Add(
+				serializers.NonFieldErrors,
+				fmt.Sprintf("Ordering by `%s` is not supported by the cursor pagination, as it can be null", term.Field),
+			))
+			return db
+		}
+	}
+	columns, columnsErr := p.columns(db, terms)
+	if columnsErr != nil {
+		_ = db.AddError(columnsErr)
+		return db
+	}
+
+	var current *cursor
+	if raw := c.Query(p.param); raw != "" {
+		decoded, decodeErr := p.decode(raw, terms)
+		if decodeErr != nil {
+			_ = db.AddError(decodeErr)
+			return db
+		}
+		current = decoded
+	}
+	backward := current != nil && current.Backward
+
+	// One row more than the page size is fetched to tell if there are more rows, it's dropped by ProcessList
+	// or Format, see cursorPage. The ordering set by the other query mods is replaced, as the keyset condition must
+	// match the ORDER BY.
+	db = db.Session(&gorm.Session{}).Limit(pageSize + 1)
+	delete(db.Statement.Clauses, "ORDER BY")
+	for i, term := range terms {
+		db = db.Order(clause.OrderByColumn{Column: columns[i], Desc: term.Descending != backward})
+	}
+	if current != nil {
+		values, valuesErr := p.cursorValues(current, terms)
+		if valuesErr != nil {
+			_ = db.AddError(valuesErr)
+			return db
+		}
+		db = db.Where(keysetExpression(columns, terms, values, backward))
+	}
+	c.Set(ctxPaginationKey, &cursorPaginationState{
+		backward:  backward,
+		hasCursor: current != nil,
+		pageSize:  pageSize,
+		terms:     terms,
+	})
+	return db
+}
+
+// ProcessList drops the additional row fetched by Apply, restores the order of the rows fetched backward and
+// builds the cursors using the rows returned to the client, so the rows inserted concurrently are never
+// skipped
+func (p *CursorPagination[Model]) ProcessList(
+	c *gin.Context, intVals []models.InternalValue,
+) ([]models.InternalValue, error) {
+	state := ctxCursorPaginationState(c)
+	if state == nil {
+		return intVals, nil
+	}
+	state.processed = true
+	return cursorPage(c, p, state, intVals, func(intVal models.InternalValue) map[string]any {
+		return intVal
+	}), nil
+}
+
+// cursorPage trims and orders the page of rows and sets the cursors of the state, fields returns the values
+// of the row keyed by the JSON names of the fields
+func cursorPage[Model, Row any](
+	c *gin.Context, p *CursorPagination[Model], state *cursorPaginationState, rows []Row,
+	fields func(Row) map[string]any,
+) []Row {
+	hasMore := len(rows) > state.pageSize
+	if hasMore {
+		rows = rows[:state.pageSize]
+	}
+	if state.backward {
+		// The previous page is fetched in the reversed order
+		reversed := make([]Row, len(rows))
+		for i, row := range rows {
+			reversed[len(rows)-1-i] = row
+		}
+		rows = reversed
+	}
+	if len(rows) == 0 {
+		return rows
+	}
+	if state.backward || hasMore {
+		state.next = p.pageURL(c, state.terms, fields(rows[len(rows)-1]), false)
+	}
+	if (state.backward && hasMore) || (!state.backward && state.hasCursor) {
+		state.previous = p.pageURL(c, state.terms, fields(rows[0]), true)
+	}
+	return rows
+}
+
+func ctxCursorPaginationState(c *gin.Context) *cursorPaginationState {
+	anyVal, ok := c.Get(ctxPaginationKey)
+	if !ok {
+		return nil
+	}
+	state, _ := anyVal.(*cursorPaginationState)
+	return state
+}
+
+func (p *CursorPagination[Model]) Envelope() *common.PaginationEnvelope {
+	return &common.PaginationEnvelope{}
+}
+
+func (p *CursorPagination[Model]) Format(c *gin.Context, entities []any) (any, error) {
+	state := ctxCursorPaginationState(c)
+	if state == nil {
+		return CursorPaginatedResponse{Results: entities}, nil
+	}
+	if !state.processed {
+		entities = cursorPage(c, p, state, entities, representationFields)
+	}
+	return CursorPaginatedResponse{Next: state.next, Previous: state.previous, Results: entities}, nil
+}
+
+func representationFields(entity any) map[string]any {
+	switch typed := entity.(type) {
+	case serializers.Representation:
+		return typed
+	case map[string]any:
+		return typed
+	}
+	return nil
+}
+
+// orderingTerms returns the ordering requested by the client followed by the key fields it doesn't contain
+func (p *CursorPagination[Model]) orderingTerms(c *gin.Context) []filters.OrderingTerm {
+	terms := []filters.OrderingTerm{}
+	seen := map[string]bool{}
+	for _, termList := range [][]filters.OrderingTerm{CtxOrdering(c), p.keys} {
+		for _, term := range termList {
+			if seen[term.Field] {
+				continue
+			}
+			seen[term.Field] = true
+			terms = append(terms, term)
+		}
+	}
+	return terms
+}
+
+func (p *CursorPagination[Model]) columns(db *gorm.DB, terms []filters.OrderingTerm) ([]clause.Column, error) {
+	var m Model
+	stmt := &gorm.Statement{DB: db}
+	if parseErr := stmt.Parse(&m); parseErr != nil {
+		return nil, parseErr
+	}
+	columns := []clause.Column{}
+	for _, term := range terms {
+		field := stmt.Schema.LookUpField(term.GoField)
+		if field == nil || field.DBName == "" {
+			return nil, fmt.Errorf("Field `%s` is not a database column and can't be used for pagination", term.Field)
+		}
+		columns = append(columns, clause.Column{Table: clause.CurrentTable, Name: field.DBName})
+	}
+	return columns, nil
+}
+
+// keysetExpression builds `(a > ?) OR (a = ? AND b > ?) OR ...`, flipping the comparisons for the
+// descending fields and for the backward cursors
+func keysetExpression(
+	columns []clause.Column, terms []filters.OrderingTerm, values []any, backward bool,
+) clause.Expression {
+	alternatives := []clause.Expression{}
+	for i := range terms {
+		conjunction := []clause.Expression{}
+		for j := 0; j < i; j++ {
+			conjunction = append(conjunction, clause.Eq{Column: columns[j], Value: values[j]})
+		}
+		if terms[i].Descending != backward {
+			conjunction = append(conjunction, clause.Lt{Column: columns[i], Value: values[i]})
+		} else {
+			conjunction = append(conjunction, clause.Gt{Column: columns[i], Value: values[i]})
+		}
+		alternatives = append(alternatives, clause.And(conjunction...))
+	}
+	return clause.Or(alternatives...)
+}
+
+func (p *CursorPagination[Model]) pageURL(
+	c *gin.Context, terms []filters.OrderingTerm, row map[string]any, backward bool,
+) *string {
+	token, encodeErr := p.encode(row, terms, backward)
+	if encodeErr != nil {
+		logrus.Errorf("Could not encode the pagination cursor: %s", encodeErr)
+		return nil
+	}
+	return pageURL(c, map[string]string{p.param: token})
+}
+
+func (p *CursorPagination[Model]) encode(row map[string]any, terms []filters.OrderingTerm, backward bool) (string, error) {
+	payload := cursor{Fields: termNames(terms), Values: []json.RawMessage{}, Backward: backward}
+	for _, term := range terms {
+		value, ok := row[term.Field]
+		if !ok {
+			return "", fmt.Errorf("the listed rows don't contain field `%s`", term.Field)
+		}
+		encoded, marshalErr := json.Marshal(value)
+		if marshalErr != nil {
+			return "", marshalErr
+		}
+		payload.Values = append(payload.Values, encoded)
+	}
+	data, marshalErr := json.Marshal(payload)
+	if marshalErr != nil {
+		return "", marshalErr
+	}
+	return base64.RawURLEncoding.EncodeToString(append(p.sign(data), data...)), nil
+}
+
+func (p *CursorPagination[Model]) decode(token string, terms []filters.OrderingTerm) (*cursor, error) {
+	invalidCursorErr := &serializers.ValidationError{FieldErrors: map[string][]string{p.param: {"Invalid cursor"}}}
+	raw, decodeErr := base64.RawURLEncoding.DecodeString(token)
+	if decodeErr != nil || len(raw) < sha256.Size {
+		return nil, invalidCursorErr
+	}
+	signature, data := raw[:sha256.Size], raw[sha256.Size:]
+	if !hmac.Equal(signature, p.sign(data)) {
+		return nil, invalidCursorErr
+	}
+	var decoded cursor
+	if unmarshalErr := json.Unmarshal(data, &decoded); unmarshalErr != nil {
+		return nil, invalidCursorErr
+	}
+	// The cursor is valid only for the ordering it was created for
+	if strings.Join(decoded.Fields, ",") != strings.Join(termNames(terms), ",") || len(decoded.Values) != len(terms) {
+		return nil, invalidCursorErr
+	}
+	return &decoded, nil
+}
+
+// cursorValues converts the values stored in the cursor to the types of the model fields
+func (p *CursorPagination[Model]) cursorValues(current *cursor, terms []filters.OrderingTerm) ([]any, error) {
+	var m Model
+	modelType := reflect.TypeOf(m)
+	values := []any{}
+	for i, term := range terms {
+		field, _ := modelType.FieldByName(term.GoField)
+		value := reflect.New(field.Type)
+		if unmarshalErr := json.Unmarshal(current.Values[i], value.Interface()); unmarshalErr != nil {
+			return nil, &serializers.ValidationError{FieldErrors: map[string][]string{p.param: {"Invalid cursor"}}}
+		}
+		values = append(values, value.Elem().Interface())
+	}
+	return values, nil
+}
+
+func (p *CursorPagination[Model]) sign(data []byte) []byte {
+	mac := hmac.New(sha256.New, p.secret)
+	mac.Write(data)
+	return mac.Sum(nil)
+}
+
+func termNames(terms []filters.OrderingTerm) []string {
+	names := []string{}
+	for _, term := range terms {
+		name := term.Field
+		if term.Descending {
+			name = "-" + name
+		}
+		names = append(names, name)
+	}
+	return names
+}
+
+// isNullableField tells if the model field can hold NULL, which is true for pointers and the types like
+// sql.NullString, that have a `Valid` flag
+func isNullableField[Model any](goField string) bool {
+	var m Model
+	field, ok := reflect.TypeOf(m).FieldByName(goField)
+	if !ok {
+		return false
+	}
+	switch field.Type.Kind() {
+	case reflect.Pointer:
+		return true
+	case reflect.Struct:
+		valid, hasValid := field.Type.FieldByName("Valid")
+		return hasValid && valid.Type.Kind() == reflect.Bool
+	}
+	return false
+}
@@ -0,0 +1,313 @@
+package gormq
+
+import (
+	"encoding/base64"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/glothriel/grf/pkg/models"
+	"github.com/glothriel/grf/pkg/queries/common"
+	"github.com/glothriel/grf/pkg/queries/filters"
+	"github.com/glothriel/grf/pkg/serializers"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+type cursorModel struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	Score     int       `json:"score"`
+	Rank      *int      `json:"rank"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+var cursorSecret = []byte("secret")
+
+func prepareCursorDB(t *testing.T) *gorm.DB {
+	db := prepareGorm(t)
+	assert.NoError(t, db.AutoMigrate(&cursorModel{}))
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	// Rows 1-6, every two share the same created_at, so the id is needed to tell them apart
+	for i, score := range []int{3, 1, 2, 3, 1, 2} {
+		assert.NoError(t, db.Create(&cursorModel{Score: score, CreatedAt: base.Add(time.Duration(i/2) * time.Hour)}).Error)
+	}
+	return db
+}
+
+func cursorDriver(db *gorm.DB, pagination *CursorPagination[cursorModel]) *GormQueryDriver[cursorModel] {
+	return Gorm[cursorModel](Static(db)).
+		WithFilterSet(filters.NewFilterSet[cursorModel]().WithField("score", filters.Gte)).
+		WithOrdering(filters.NewOrdering[cursorModel]("score", "rank")).
+		WithOrderBy("id ASC").
+		WithPagination(pagination)
+}
+
+func listCursorPage(
+	t *testing.T, queryDriver *GormQueryDriver[cursorModel], pageURL string,
+) (CursorPaginatedResponse, error) {
+	ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+	ctx.Request = httptest.NewRequest("GET", pageURL, nil)
+	for _, middleware := range queryDriver.Middleware() {
+		middleware(ctx)
+	}
+	for _, queryMod := range []common.QueryMod{
+		queryDriver.Filter(), queryDriver.Order(), queryDriver.Pagination(),
+	} {
+		if applyErr := queryMod.Apply(ctx); applyErr != nil {
+			return CursorPaginatedResponse{}, applyErr
+		}
+	}
+	list, listErr := queryDriver.CRUD().List(ctx)
+	assert.NoError(t, listErr)
+	list, processErr := queryDriver.Pagination().(common.ListProcessor).ProcessList(ctx, list)
+	assert.NoError(t, processErr)
+	entities := []any{}
+	for _, entity := range list {
+		entities = append(entities, entity["id"])
+	}
+	formatted, formatErr := queryDriver.Pagination().Format(ctx, entities)
+	assert.NoError(t, formatErr)
+	return formatted.(CursorPaginatedResponse), nil
+}
+
+func walkPages(t *testing.T, queryDriver *GormQueryDriver[cursorModel], pageURL string, backward bool) [][]any {
+	pages := [][]any{}
+	for pageURL != "" {
+		page, listErr := listCursorPage(t, queryDriver, pageURL)
+		assert.NoError(t, listErr)
+		pages = append(pages, page.Results)
+		next := page.Next
+		if backward {
+			next = page.Previous
+		}
+		pageURL = ""
+		if next != nil {
+			pageURL = *next
+		}
+		if len(pages) > 10 {
+			t.Fatal("too many pages")
+		}
+	}
+	return pages
+}
+
+func TestCursorPaginationWalksPagesInBothDirections(t *testing.T) {
+	tests := []struct {
+		name      string
+		keys      []string
+		query     string
+		wantPages [][]any
+	}{
+		{
+			name:      "descending composite key",
+			keys:      []string{"-created_at", "-id"},
+			wantPages: [][]any{{uint(6), uint(5), uint(4)}, {uint(3), uint(2), uint(1)}},
+		},
+		{
+			name:      "ascending key",
+			keys:      []string{"id"},
+			query:     "&page_size=4",
+			wantPages: [][]any{{uint(1), uint(2), uint(3), uint(4)}, {uint(5), uint(6)}},
+		},
+		{
+			name:      "composes with ordering",
+			keys:      []string{"id"},
+			query:     "&ordering=-score&page_size=2",
+			wantPages: [][]any{{uint(1), uint(4)}, {uint(3), uint(6)}, {uint(2), uint(5)}},
+		},
+		{
+			name:      "composes with filters",
+			keys:      []string{"-id"},
+			query:     "&score__gte=2&page_size=2",
+			wantPages: [][]any{{uint(6), uint(4)}, {uint(3), uint(1)}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// given
+			queryDriver := cursorDriver(prepareCursorDB(t), NewCursorPagination[cursorModel](cursorSecret, tt.keys...).WithPageSize(3))
+
+			// when
+			forward := walkPages(t, queryDriver, "/cursors?x=1"+tt.query, false)
+			lastPage, _ := listCursorPage(t, queryDriver, "/cursors?x=1"+tt.query)
+			for lastPage.Next != nil {
+				lastPage, _ = listCursorPage(t, queryDriver, *lastPage.Next)
+			}
+			backward := [][]any{}
+			if lastPage.Previous != nil {
+				backward = walkPages(t, queryDriver, *lastPage.Previous, true)
+			}
+
+			// then
+			assert.Equal(t, tt.wantPages, forward)
+			wantBackward := [][]any{}
+			for i := len(tt.wantPages) - 2; i >= 0; i-- {
+				wantBackward = append(wantBackward, tt.wantPages[i])
+			}
+			assert.Equal(t, wantBackward, backward)
+		})
+	}
+}
+
+func TestCursorPaginationIsNotShiftedByInserts(t *testing.T) {
+	// given
+	db := prepareCursorDB(t)
+	queryDriver := cursorDriver(db, NewCursorPagination[cursorModel](cursorSecret, "id").WithPageSize(3))
+	firstPage, _ := listCursorPage(t, queryDriver, "/cursors")
+
+	// when
+	assert.NoError(t, db.Delete(&cursorModel{}, 1).Error)
+	assert.NoError(t, db.Create(&cursorModel{Score: 5}).Error)
+	secondPage, listErr := listCursorPage(t, queryDriver, *firstPage.Next)
+
+	// then
+	assert.NoError(t, listErr)
+	assert.Equal(t, []any{uint(4), uint(5), uint(6)}, secondPage.Results)
+}
+
+func TestCursorPaginationComputesCursorsFromListedRows(t *testing.T) {
+	// given
+	db := prepareCursorDB(t)
+	queries := 0
+	assert.NoError(t, db.Callback().Query().After("gorm:query").Register("count_queries", func(*gorm.DB) {
+		queries++
+	}))
+	queryDriver := cursorDriver(db, NewCursorPagination[cursorModel](cursorSecret, "id").WithPageSize(3))
+
+	// when
+	firstPage, listErr := listCursorPage(t, queryDriver, "/cursors")
+
+	// then
+	assert.NoError(t, listErr)
+	assert.Equal(t, 1, queries)
+	assert.Equal(t, []any{uint(1), uint(2), uint(3)}, firstPage.Results)
+	assert.NotNil(t, firstPage.Next)
+	assert.Nil(t, firstPage.Previous)
+}
+
+func TestCursorPaginationWorksWithCustomListQueries(t *testing.T) {
+	// given
+	db := prepareCursorDB(t)
+	queryDriver := cursorDriver(db, NewCursorPagination[cursorModel](cursorSecret, "id").WithPageSize(2))
+	queryDriver.CRUD().WithList(func(ctx *gin.Context) ([]models.InternalValue, error) {
+		rows := []cursorModel{}
+		if findErr := CtxQuery(ctx).Find(&rows).Error; findErr != nil {
+			return nil, findErr
+		}
+		intVals := []models.InternalValue{}
+		for _, row := range rows {
+			intVals = append(intVals, models.InternalValue{"id": row.ID, "score": row.Score})
+		}
+		return intVals, nil
+	})
+
+	// when
+	pages := walkPages(t, queryDriver, "/cursors", false)
+
+	// then
+	assert.Equal(t, [][]any{{uint(1), uint(2)}, {uint(3), uint(4)}, {uint(5), uint(6)}}, pages)
+}
+
+func TestCursorPaginationFormatTrimsPagesNotProcessedByList(t *testing.T) {
+	// given
+	queryDriver := cursorDriver(prepareCursorDB(t), NewCursorPagination[cursorModel](cursorSecret, "id").WithPageSize(2))
+	ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+	ctx.Request = httptest.NewRequest("GET", "/cursors", nil)
+	for _, middleware := range queryDriver.Middleware() {
+		middleware(ctx)
+	}
+	assert.NoError(t, queryDriver.Pagination().Apply(ctx))
+	list, listErr := queryDriver.CRUD().List(ctx)
+	representations := []any{}
+	for _, intVal := range list {
+		representations = append(representations, serializers.Representation{"id": intVal["id"]})
+	}
+
+	// when
+	formatted, formatErr := queryDriver.Pagination().Format(ctx, representations)
+
+	// then
+	assert.NoError(t, listErr)
+	assert.NoError(t, formatErr)
+	page := formatted.(CursorPaginatedResponse)
+	assert.Len(t, list, 3)
+	assert.Equal(t, []any{serializers.Representation{"id": uint(1)}, serializers.Representation{"id": uint(2)}}, page.Results)
+	if assert.NotNil(t, page.Next) {
+		nextPage, nextErr := listCursorPage(t, queryDriver, *page.Next)
+		assert.NoError(t, nextErr)
+		assert.Equal(t, []any{uint(3), uint(4)}, nextPage.Results)
+	}
+}
+
+func TestCursorPaginationRejectsNullableFields(t *testing.T) {
+	// given
+	queryDriver := cursorDriver(prepareCursorDB(t), NewCursorPagination[cursorModel](cursorSecret, "id"))
+
+	// when
+	_, listErr := listCursorPage(t, queryDriver, "/cursors?ordering=rank")
+
+	// then
+	assert.Equal(t, &serializers.ValidationError{FieldErrors: map[string][]string{
+		serializers.NonFieldErrors: {"Ordering by `rank` is not supported by the cursor pagination, as it can be null"},
+	}}, listErr)
+	assert.Panics(t, func() {
+		NewCursorPagination[cursorModel](cursorSecret, "rank", "id")
+	})
+}
+
+func TestCursorPaginationRejectsInvalidCursors(t *testing.T) {
+	queryDriver := cursorDriver(prepareCursorDB(t), NewCursorPagination[cursorModel](cursorSecret, "id").WithPageSize(3))
+	firstPage, _ := listCursorPage(t, queryDriver, "/cursors")
+	nextURL, _ := url.Parse(*firstPage.Next)
+	validCursor := nextURL.Query().Get("cursor")
+	raw, _ := base64.RawURLEncoding.DecodeString(validCursor)
+	tampered := append([]byte{}, raw...)
+	tampered[len(tampered)-3]++
+	otherSecret := NewCursorPagination[cursorModel]([]byte("other"), "id").WithPageSize(3)
+	otherSecretPage, _ := listCursorPage(t, cursorDriver(prepareCursorDB(t), otherSecret), "/cursors")
+	otherSecretURL, _ := url.Parse(*otherSecretPage.Next)
+
+	tests := []struct {
+		name  string
+		query string
+	}{
+		{"not base64", "cursor=***"},
+		{"too short", "cursor=YWJj"},
+		{"tampered", "cursor=" + base64.RawURLEncoding.EncodeToString(tampered)},
+		{"signed with another secret", "cursor=" + otherSecretURL.Query().Get("cursor")},
+		{"created for another ordering", "ordering=score&cursor=" + validCursor},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// when
+			_, listErr := listCursorPage(t, queryDriver, "/cursors?"+tt.query)
+
+			// then
+			assert.Equal(t, &serializers.ValidationError{
+				FieldErrors: map[string][]string{"cursor": {"Invalid cursor"}},
+			}, listErr)
+		})
+	}
+}
+
+func TestNewCursorPaginationPanicsOnUnknownField(t *testing.T) {
+	assert.Panics(t, func() {
+		NewCursorPagination[cursorModel](cursorSecret, "unknown")
+	})
+}
+
+func TestCursorPaginationSinglePageHasNoLinks(t *testing.T) {
+	// given
+	queryDriver := cursorDriver(prepareCursorDB(t), NewCursorPagination[cursorModel](cursorSecret, "id"))
+
+	// when
+	page, listErr := listCursorPage(t, queryDriver, "/cursors")
+
+	// then
+	assert.NoError(t, listErr)
+	assert.Nil(t, page.Next)
+	assert.Nil(t, page.Previous)
+	assert.Len(t, page.Results, 6)
+}
@@ -0,0 +1,196 @@
+package gormq
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+
+	"github.com/glothriel/grf/pkg/detectors"
+	"github.com/glothriel/grf/pkg/queries/common"
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+)
+
+// Postgres error codes of the constraint violations
+const (
+	pgUniqueViolation     = "23505"
+	pgForeignKeyViolation = "23503"
+	pgNotNullViolation    = "23502"
+	pgCheckViolation      = "23514"
+)
+
+// SQLite extended result codes of the constraint violations, see https://www.sqlite.org/rescode.html
+const (
+	sqliteConstraintCheck      = 275
+	sqliteConstraintForeignKey = 787
+	sqliteConstraintNotNull    = 1299
+	sqliteConstraintPrimaryKey = 1555
+	sqliteConstraintUnique     = 2067
+)
+
+var sqliteConstraints = map[int]common.Constraint{
+	sqliteConstraintCheck:      common.ConstraintCheck,
+	sqliteConstraintForeignKey: common.ConstraintForeignKey,
+	sqliteConstraintNotNull:    common.ConstraintNotNull,
+	sqliteConstraintPrimaryKey: common.ConstraintUnique,
+	sqliteConstraintUnique:     common.ConstraintUnique,
+}
+
+// TranslateError converts the constraint violations reported by SQLite, Postgres or gorm (when the
+// TranslateError option is on) to *common.ConstraintError, with the columns translated to the JSON names of
+// the Model fields. Other errors are returned unchanged.
+func TranslateError[Model any](db *gorm.DB, err error) error {
+	if err == nil {
+		return nil
+	}
+	constraintErr := parseConstraintError(err)
+	if constraintErr == nil {
+		return err
+	}
+	constraintErr.Fields = columnsToFields[Model](db, constraintErr.Fields)
+	return constraintErr
+}
+
+// TranslateDestroyError is like TranslateError, but used when deleting rows. Deleting a row can only violate
+// the foreign keys of the rows referring to it, so such violations are marked as Referenced.
+func TranslateDestroyError[Model any](db *gorm.DB, err error) error {
+	translated := TranslateError[Model](db, err)
+	var constraintErr *common.ConstraintError
+	if errors.As(translated, &constraintErr) && constraintErr.Constraint == common.ConstraintForeignKey {
+		constraintErr.Referenced = true
+		constraintErr.Fields = nil
+	}
+	return translated
+}
+
+// parseConstraintError returns the ConstraintError with the column names in Fields, or nil if the error is
+// not a constraint violation
+func parseConstraintError(err error) *common.ConstraintError {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return parsePostgresError(pgErr)
+	}
+	if code, ok := sqliteExtendedCode(err); ok {
+		if constraint, isConstraint := sqliteConstraints[code]; isConstraint {
+			return parseSQLiteError(constraint, err)
+		}
+		return nil
+	}
+	switch {
+	case errors.Is(err, gorm.ErrDuplicatedKey):
+		return &common.ConstraintError{Constraint: common.ConstraintUnique, Err: err}
+	case errors.Is(err, gorm.ErrForeignKeyViolated):
+		return &common.ConstraintError{Constraint: common.ConstraintForeignKey, Err: err}
+	case errors.Is(err, gorm.ErrCheckConstraintViolated):
+		return &common.ConstraintError{Constraint: common.ConstraintCheck, Err: err}
+	}
+	return nil
+}
+
+func parsePostgresError(pgErr *pgconn.PgError) *common.ConstraintError {
+	constraintErr := &common.ConstraintError{Name: pgErr.ConstraintName, Err: pgErr}
+	switch pgErr.Code {
+	case pgUniqueViolation:
+		constraintErr.Constraint = common.ConstraintUnique
+	case pgForeignKeyViolation:
+		constraintErr.Constraint = common.ConstraintForeignKey
+	case pgNotNullViolation:
+		constraintErr.Constraint = common.ConstraintNotNull
+	case pgCheckViolation:
+		constraintErr.Constraint = common.ConstraintCheck
+	default:
+		return nil
+	}
+	if pgErr.ColumnName != "" {
+		constraintErr.Fields = []string{pgErr.ColumnName}
+	} else {
+		// Unique and foreign key violations describe the columns in the detail, for example
+		// `Key (email)=(john@example.com) already exists.`
+		if keys, ok := strings.CutPrefix(pgErr.Detail, "Key ("); ok {
+			if columns, _, found := strings.Cut(keys, ")="); found {
+				constraintErr.Fields = splitColumns(columns)
+			}
+		}
+	}
+	return constraintErr
+}
+
+// sqliteExtendedCode returns the extended result code of the errors returned by the SQLite drivers. The
+// drivers are not imported, as mattn/go-sqlite3 requires cgo, so its Error is read using reflection. The
+// drivers exposing the code using a `Code() int` method, like modernc.org/sqlite, are supported too.
+func sqliteExtendedCode(err error) (int, bool) {
+	for ; err != nil; err = errors.Unwrap(err) {
+		if coder, ok := err.(interface{ Code() int }); ok {
+			return coder.Code(), true
+		}
+		v := reflect.Indirect(reflect.ValueOf(err))
+		if v.Kind() != reflect.Struct || v.Type().PkgPath() != "github.com/mattn/go-sqlite3" {
+			continue
+		}
+		if code := v.FieldByName("ExtendedCode"); code.IsValid() && code.CanInt() {
+			return int(code.Int()), true
+		}
+	}
+	return 0, false
+}
+
+// parseSQLiteError parses the details of the message, which are `table.column, table.column` for unique and
+// not null constraints, and `name` for check constraints, for example
+// `UNIQUE constraint failed: members.email_address, members.team_id`
+func parseSQLiteError(constraint common.Constraint, err error) *common.ConstraintError {
+	constraintErr := &common.ConstraintError{Constraint: constraint, Err: err}
+	_, message, _ := strings.Cut(err.Error(), "constraint failed")
+	message = strings.TrimSpace(strings.TrimPrefix(message, ":"))
+	if message == "" {
+		return constraintErr
+	}
+	if constraint == common.ConstraintCheck {
+		constraintErr.Name = message
+		return constraintErr
+	}
+	for _, column := range splitColumns(message) {
+		if _, columnName, hasTable := strings.Cut(column, "."); hasTable {
+			column = columnName
+		}
+		constraintErr.Fields = append(constraintErr.Fields, column)
+	}
+	return constraintErr
+}
+
+func splitColumns(columns string) []string {
+	ret := []string{}
+	for _, column := range strings.Split(columns, ",") {
+		ret = append(ret, strings.Trim(strings.TrimSpace(column), `"`))
+	}
+	return ret
+}
+
+// columnsToFields translates the column names to the JSON names of the Model fields, using
+// detectors.FieldNames and the gorm schema of the Model. Unknown columns are skipped.
+func columnsToFields[Model any](db *gorm.DB, columns []string) []string {
+	if len(columns) == 0 {
+		return nil
+	}
+	var m Model
+	stmt := &gorm.Statement{DB: db}
+	if parseErr := stmt.Parse(&m); parseErr != nil {
+		return nil
+	}
+	columnFields := map[string]string{}
+	for jsonTag, goField := range detectors.FieldNames[Model]() {
+		jsonName := strings.Split(jsonTag, ",")[0]
+		if jsonName == "" || jsonName == "-" {
+			continue
+		}
+		if field := stmt.Schema.LookUpField(goField); field != nil && field.DBName != "" {
+			columnFields[field.DBName] = jsonName
+		}
+	}
+	fields := []string{}
+	for _, column := range columns {
+		if field, ok := columnFields[column]; ok {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
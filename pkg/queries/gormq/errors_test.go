@@ -0,0 +1,222 @@
+package gormq
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/glothriel/grf/pkg/models"
+	"github.com/glothriel/grf/pkg/queries/common"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+type Team struct {
+	ID uint `gorm:"primaryKey" json:"id"`
+}
+
+type Member struct {
+	ID       uint    `gorm:"primaryKey" json:"id"`
+	Email    string  `gorm:"column:email_address;uniqueIndex" json:"email"`
+	Nickname *string `gorm:"not null" json:"nickname"`
+	Age      int     `gorm:"check:age_not_negative,age >= 0" json:"age"`
+	TeamID   *uint   `json:"team_id"`
+	Team     *Team   `json:"team" grf:"relation"`
+}
+
+func TestGormConstraintErrors(t *testing.T) {
+	nickname := "johnny"
+	tests := []struct {
+		name    string
+		intVal  models.InternalValue
+		wantErr *common.ConstraintError
+	}{
+		{
+			name:    "unique",
+			intVal:  models.InternalValue{"email": "john@example.com", "nickname": &nickname},
+			wantErr: &common.ConstraintError{Constraint: common.ConstraintUnique, Fields: []string{"email"}},
+		},
+		{
+			name:    "not null",
+			intVal:  models.InternalValue{"email": "jane@example.com"},
+			wantErr: &common.ConstraintError{Constraint: common.ConstraintNotNull, Fields: []string{"nickname"}},
+		},
+		{
+			name:    "check",
+			intVal:  models.InternalValue{"email": "jane@example.com", "nickname": &nickname, "age": -1},
+			wantErr: &common.ConstraintError{Constraint: common.ConstraintCheck, Name: "age_not_negative"},
+		},
+		{
+			name:    "foreign key",
+			intVal:  models.InternalValue{"email": "jane@example.com", "nickname": &nickname, "team_id": uint(5)},
+			wantErr: &common.ConstraintError{Constraint: common.ConstraintForeignKey},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// given
+			db, openErr := gorm.Open(sqlite.Open("file::memory:?_foreign_keys=on"))
+			assert.NoError(t, openErr)
+			assert.NoError(t, db.AutoMigrate(&Team{}, &Member{}))
+			ctx, queryDriver := prepareCtx[Member](t, db)
+			_, seedErr := queryDriver.CRUD().Create(ctx, models.InternalValue{"email": "john@example.com", "nickname": &nickname})
+			assert.NoError(t, seedErr)
+
+			// when
+			_, createErr := queryDriver.CRUD().Create(ctx, tt.intVal)
+
+			// then
+			constraintErr, ok := createErr.(*common.ConstraintError)
+			if assert.True(t, ok, "%v", createErr) {
+				assert.Equal(t, tt.wantErr.Constraint, constraintErr.Constraint)
+				assert.Equal(t, tt.wantErr.Fields, constraintErr.Fields)
+				assert.Equal(t, tt.wantErr.Name, constraintErr.Name)
+			}
+		})
+	}
+}
+
+func TestGormConstraintErrorOnUpdate(t *testing.T) {
+	// given
+	nickname := "johnny"
+	ctx, queryDriver := prepareCtx[Member](t)
+	_, firstErr := queryDriver.CRUD().Create(ctx, models.InternalValue{"email": "john@example.com", "nickname": &nickname})
+	second, secondErr := queryDriver.CRUD().Create(ctx, models.InternalValue{"email": "jane@example.com", "nickname": &nickname})
+
+	// when
+	second["email"] = "john@example.com"
+	_, updateErr := queryDriver.CRUD().Update(ctx, second, second, second["id"])
+
+	// then
+	assert.NoError(t, firstErr)
+	assert.NoError(t, secondErr)
+	constraintErr, ok := updateErr.(*common.ConstraintError)
+	if assert.True(t, ok, "%v", updateErr) {
+		assert.Equal(t, common.ConstraintUnique, constraintErr.Constraint)
+		assert.Equal(t, []string{"email"}, constraintErr.Fields)
+	}
+}
+
+func TestGormConstraintErrorOnDestroy(t *testing.T) {
+	// given
+	nickname := "johnny"
+	db, openErr := gorm.Open(sqlite.Open("file::memory:?_foreign_keys=on"))
+	assert.NoError(t, openErr)
+	assert.NoError(t, db.AutoMigrate(&Team{}, &Member{}))
+	ctx, teamDriver := prepareCtx[Team](t, db)
+	team, teamErr := teamDriver.CRUD().Create(ctx, models.InternalValue{})
+	_, memberDriver := prepareCtx[Member](t, db)
+	_, memberErr := memberDriver.CRUD().Create(ctx, models.InternalValue{
+		"email": "john@example.com", "nickname": &nickname, "team_id": team["id"],
+	})
+
+	// when
+	destroyErr := teamDriver.CRUD().Destroy(ctx, team["id"])
+
+	// then
+	assert.NoError(t, teamErr)
+	assert.NoError(t, memberErr)
+	constraintErr, ok := destroyErr.(*common.ConstraintError)
+	if assert.True(t, ok, "%v", destroyErr) {
+		assert.Equal(t, common.ConstraintForeignKey, constraintErr.Constraint)
+		assert.True(t, constraintErr.Referenced)
+		assert.True(t, constraintErr.IsConflict())
+		assert.Empty(t, constraintErr.Fields)
+	}
+}
+
+// sqliteCodeError mimics the errors of the SQLite drivers exposing the extended result code using a method
+type sqliteCodeError struct {
+	code    int
+	message string
+}
+
+func (e sqliteCodeError) Error() string {
+	return e.message
+}
+
+func (e sqliteCodeError) Code() int {
+	return e.code
+}
+
+func TestTranslateError(t *testing.T) {
+	db := prepareGorm(t)
+	tests := []struct {
+		name    string
+		err     error
+		wantErr error
+	}{
+		{
+			name: "postgres unique",
+			err: &pgconn.PgError{
+				Code: "23505", ConstraintName: "idx_members_email_address",
+				Detail: "Key (email_address)=(john@example.com) already exists.",
+			},
+			wantErr: &common.ConstraintError{
+				Constraint: common.ConstraintUnique, Fields: []string{"email"}, Name: "idx_members_email_address",
+			},
+		},
+		{
+			name: "postgres foreign key",
+			err: &pgconn.PgError{
+				Code: "23503", ConstraintName: "fk_members_team",
+				Detail: `Key (team_id)=(5) is not present in table "teams".`,
+			},
+			wantErr: &common.ConstraintError{
+				Constraint: common.ConstraintForeignKey, Fields: []string{"team_id"}, Name: "fk_members_team",
+			},
+		},
+		{
+			name:    "postgres not null",
+			err:     &pgconn.PgError{Code: "23502", ColumnName: "nickname"},
+			wantErr: &common.ConstraintError{Constraint: common.ConstraintNotNull, Fields: []string{"nickname"}},
+		},
+		{
+			name:    "postgres check",
+			err:     &pgconn.PgError{Code: "23514", ConstraintName: "age_not_negative"},
+			wantErr: &common.ConstraintError{Constraint: common.ConstraintCheck, Name: "age_not_negative"},
+		},
+		{
+			name: "sqlite unique together",
+			err: sqliteCodeError{
+				code: sqliteConstraintUnique, message: "UNIQUE constraint failed: members.email_address, members.team_id",
+			},
+			wantErr: &common.ConstraintError{Constraint: common.ConstraintUnique, Fields: []string{"email", "team_id"}},
+		},
+		{
+			name: "sqlite check",
+			err: fmt.Errorf("wrapped: %w", sqliteCodeError{
+				code: sqliteConstraintCheck, message: "CHECK constraint failed: age_not_negative",
+			}),
+			wantErr: &common.ConstraintError{Constraint: common.ConstraintCheck, Name: "age_not_negative"},
+		},
+		{
+			name:    "sqlite message without constraint code",
+			err:     sqliteCodeError{code: 1, message: "UNIQUE constraint failed: members.email_address"},
+			wantErr: sqliteCodeError{code: 1, message: "UNIQUE constraint failed: members.email_address"},
+		},
+		{
+			name:    "translated by gorm",
+			err:     fmt.Errorf("wrapped: %w", gorm.ErrDuplicatedKey),
+			wantErr: &common.ConstraintError{Constraint: common.ConstraintUnique},
+		},
+		{
+			name:    "other postgres error",
+			err:     &pgconn.PgError{Code: "42P01"},
+			wantErr: &pgconn.PgError{Code: "42P01"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// when
+			translated := TranslateError[Member](db, tt.err)
+
+			// then
+			if wantConstraintErr, ok := tt.wantErr.(*common.ConstraintError); ok {
+				wantConstraintErr.Err = tt.err
+			}
+			assert.Equal(t, tt.wantErr, translated)
+		})
+	}
+}
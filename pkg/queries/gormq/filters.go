@@ -0,0 +1,228 @@
+package gormq
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/glothriel/grf/pkg/queries/filters"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// WithFilterSet filters the queries using the conditions parsed from the query params. It can be used
+// together with WithFilter, both are applied.
+func (g *GormQueryDriver[Model]) WithFilterSet(filterSet *filters.FilterSet[Model]) *GormQueryDriver[Model] {
+	g.filterSet.modFunc = func(ctx *gin.Context, db *gorm.DB) *gorm.DB {
+		conditions, parseErr := filterSet.Parse(ctx)
+		if parseErr != nil {
+			_ = db.AddError(parseErr)
+			return db
+		}
+		return ApplyConditions[Model](db, conditions)
+	}
+	return g
+}
+
+// ApplyConditions adds WHERE clauses matching the filter conditions to the query
+func ApplyConditions[Model any](db *gorm.DB, conditions []filters.Condition) *gorm.DB {
+	if len(conditions) == 0 {
+		return db
+	}
+	var m Model
+	stmt := &gorm.Statement{DB: db}
+	if parseErr := stmt.Parse(&m); parseErr != nil {
+		_ = db.AddError(parseErr)
+		return db
+	}
+	expressions := []clause.Expression{}
+	for _, condition := range conditions {
+		field := stmt.Schema.LookUpField(condition.GoField)
+		if field == nil || field.DBName == "" {
+			_ = db.AddError(fmt.Errorf("Field `%s` is not a database column and can't be filtered", condition.Field))
+			return db
+		}
+		column := clause.Column{Table: clause.CurrentTable, Name: field.DBName}
+		if len(condition.Path) > 0 {
+			expressions = append(expressions, documentConditionExpression(db.Dialector.Name(), column, condition))
+			continue
+		}
+		expressions = append(expressions, conditionExpression(column, condition))
+	}
+	return db.Where(clause.And(expressions...))
+}
+
+func conditionExpression(column clause.Column, condition filters.Condition) clause.Expression {
+	switch condition.Lookup {
+	case filters.IExact:
+		return clause.Expr{SQL: "LOWER(?) = LOWER(?)", Vars: []any{column, condition.Value}}
+	case filters.Contains:
+		return clause.Expr{
+			SQL:  "? LIKE ? ESCAPE ?",
+			Vars: []any{column, likePattern(condition.Value), likeEscapeChar},
+		}
+	case filters.IContains:
+		return clause.Expr{
+			SQL:  "LOWER(?) LIKE LOWER(?) ESCAPE ?",
+			Vars: []any{column, likePattern(condition.Value), likeEscapeChar},
+		}
+	case filters.Gt:
+		return clause.Gt{Column: column, Value: condition.Value}
+	case filters.Gte:
+		return clause.Gte{Column: column, Value: condition.Value}
+	case filters.Lt:
+		return clause.Lt{Column: column, Value: condition.Value}
+	case filters.Lte:
+		return clause.Lte{Column: column, Value: condition.Value}
+	case filters.In:
+		return clause.IN{Column: column, Values: condition.Value.([]any)}
+	case filters.Range:
+		bounds := condition.Value.([]any)
+		return clause.And(
+			clause.Gte{Column: column, Value: bounds[0]},
+			clause.Lte{Column: column, Value: bounds[1]},
+		)
+	case filters.IsNull:
+		if condition.Value.(bool) {
+			return clause.Eq{Column: column, Value: nil}
+		}
+		return clause.Neq{Column: column, Value: nil}
+	}
+	return clause.Eq{Column: column, Value: condition.Value}
+}
+
+// documentConditionExpression builds the condition for a key of a JSON document column. On Postgres the
+// extracted `jsonb` is compared with the value encoded as `jsonb`, so both the types and the values have to
+// match. Other databases use JSON_EXTRACT, which returns SQL values.
+func documentConditionExpression(dialect string, column clause.Column, condition filters.Condition) clause.Expression {
+	extract, extractText, value := sqliteDocumentExtract(column, condition.Path)
+	if dialect == "postgres" {
+		extract, extractText, value = postgresDocumentExtract(column, condition.Path)
+	}
+	compare := func(operator string, v any) clause.Expression {
+		compared := value(v)
+		return clause.Expr{
+			SQL:  fmt.Sprintf("%s %s %s", extract.SQL, operator, compared.SQL),
+			Vars: append(append([]any{}, extract.Vars...), compared.Vars...),
+		}
+	}
+	textExpr := func(sql string, vars ...any) clause.Expression {
+		return clause.Expr{
+			SQL:  strings.ReplaceAll(sql, "{}", extractText.SQL),
+			Vars: append(append([]any{}, extractText.Vars...), vars...),
+		}
+	}
+	switch condition.Lookup {
+	case filters.IExact:
+		return textExpr("LOWER({}) = LOWER(?)", condition.Value)
+	case filters.Contains:
+		return textExpr("{} LIKE ? ESCAPE ?", likePattern(condition.Value), likeEscapeChar)
+	case filters.IContains:
+		return textExpr("LOWER({}) LIKE LOWER(?) ESCAPE ?", likePattern(condition.Value), likeEscapeChar)
+	case filters.Gt:
+		return compare(">", condition.Value)
+	case filters.Gte:
+		return compare(">=", condition.Value)
+	case filters.Lt:
+		return compare("<", condition.Value)
+	case filters.Lte:
+		return compare("<=", condition.Value)
+	case filters.In:
+		options := []clause.Expression{}
+		for _, option := range condition.Value.([]any) {
+			options = append(options, compare("=", option))
+		}
+		return clause.Or(options...)
+	case filters.Range:
+		bounds := condition.Value.([]any)
+		return clause.And(compare(">=", bounds[0]), compare("<=", bounds[1]))
+	case filters.IsNull:
+		if condition.Value.(bool) {
+			return textExpr("{} IS NULL")
+		}
+		return textExpr("{} IS NOT NULL")
+	}
+	return compare("=", condition.Value)
+}
+
+func sqliteDocumentExtract(
+	column clause.Column, path []string,
+) (clause.Expr, clause.Expr, func(any) clause.Expr) {
+	jsonPath := "$"
+	for _, key := range path {
+		jsonPath += fmt.Sprintf(".\"%s\"", key)
+	}
+	extract := clause.Expr{SQL: "JSON_EXTRACT(?, ?)", Vars: []any{column, jsonPath}}
+	return extract, extract, func(v any) clause.Expr {
+		return clause.Expr{SQL: "?", Vars: []any{v}}
+	}
+}
+
+func postgresDocumentExtract(
+	column clause.Column, path []string,
+) (clause.Expr, clause.Expr, func(any) clause.Expr) {
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(path)), ", ")
+	vars := []any{column}
+	for _, key := range path {
+		vars = append(vars, key)
+	}
+	extract := clause.Expr{SQL: fmt.Sprintf("JSONB_EXTRACT_PATH(?, %s)", placeholders), Vars: vars}
+	extractText := clause.Expr{SQL: fmt.Sprintf("JSONB_EXTRACT_PATH_TEXT(?, %s)", placeholders), Vars: vars}
+	return extract, extractText, func(v any) clause.Expr {
+		encoded, _ := json.Marshal(v)
+		return clause.Expr{SQL: "CAST(CAST(? AS TEXT) AS JSONB)", Vars: []any{string(encoded)}}
+	}
+}
+
+const likeEscapeChar = "\\"
+
+// likePattern escapes the LIKE wildcards in the value and wraps it with `%`
+func likePattern(value any) string {
+	escaped := strings.NewReplacer(
+		likeEscapeChar, likeEscapeChar+likeEscapeChar,
+		"%", likeEscapeChar+"%",
+		"_", likeEscapeChar+"_",
+	).Replace(fmt.Sprint(value))
+	return "%" + escaped + "%"
+}
+
+// WithOrdering allows the clients to sort the results using the `?ordering=` query param. The clause set
+// using WithOrderBy is still applied after the requested ordering, so it can be used as a tie-breaker.
+func (g *GormQueryDriver[Model]) WithOrdering(ordering *filters.Ordering[Model]) *GormQueryDriver[Model] {
+	g.ordering.modFunc = func(ctx *gin.Context, db *gorm.DB) *gorm.DB {
+		terms, parseErr := ordering.Parse(ctx)
+		if parseErr != nil {
+			_ = db.AddError(parseErr)
+			return db
+		}
+		CtxSetOrdering(ctx, terms)
+		return ApplyOrdering[Model](db, terms)
+	}
+	return g
+}
+
+// ApplyOrdering adds ORDER BY clauses for the ordering terms to the query
+func ApplyOrdering[Model any](db *gorm.DB, terms []filters.OrderingTerm) *gorm.DB {
+	if len(terms) == 0 {
+		return db
+	}
+	var m Model
+	stmt := &gorm.Statement{DB: db}
+	if parseErr := stmt.Parse(&m); parseErr != nil {
+		_ = db.AddError(parseErr)
+		return db
+	}
+	for _, term := range terms {
+		field := stmt.Schema.LookUpField(term.GoField)
+		if field == nil || field.DBName == "" {
+			_ = db.AddError(fmt.Errorf("Field `%s` is not a database column and can't be used for ordering", term.Field))
+			return db
+		}
+		db = db.Order(clause.OrderByColumn{
+			Column: clause.Column{Table: clause.CurrentTable, Name: field.DBName},
+			Desc:   term.Descending,
+		})
+	}
+	return db
+}
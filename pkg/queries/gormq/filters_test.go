@@ -0,0 +1,258 @@
+package gormq
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/glothriel/grf/pkg/models"
+	"github.com/glothriel/grf/pkg/queries/filters"
+	"github.com/glothriel/grf/pkg/serializers"
+	"github.com/stretchr/testify/assert"
+)
+
+type FilteredModel struct {
+	ID    uint    `gorm:"primaryKey" json:"id"`
+	Name  string  `json:"name"`
+	Price float64 `json:"price"`
+}
+
+func TestGormFilterSet(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   string
+		wantIDs []uint
+	}{
+		{"no filters", "", []uint{1, 2, 3, 4}},
+		{"exact", "name=bob", []uint{1}},
+		{"iexact", "name__iexact=BOB", []uint{1}},
+		{"contains", "name__contains=li", []uint{2}},
+		{"contains escapes wildcards", "name__contains=%25", []uint{4}},
+		{"icontains", "name__icontains=D", []uint{3}},
+		{"gt", "price__gt=20", []uint{3}},
+		{"gte", "price__gte=20", []uint{2, 3}},
+		{"lt", "price__lt=20", []uint{1, 4}},
+		{"lte", "price__lte=10", []uint{1}},
+		{"in", "id__in=1,3", []uint{1, 3}},
+		{"range", "price__range=10,20", []uint{1, 2, 4}},
+		{"combined", "price__gte=10&name__icontains=a", []uint{2, 3}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// given
+			ctx, queryDriver := prepareCtx[FilteredModel](t)
+			for _, intVal := range []models.InternalValue{
+				{"name": "bob", "price": 10.0},
+				{"name": "alice", "price": 20.0},
+				{"name": "david", "price": 30.0},
+				{"name": "100%", "price": 15.0},
+			} {
+				_, createErr := queryDriver.CRUD().Create(ctx, intVal)
+				assert.NoError(t, createErr)
+			}
+			ctx.Request, _ = http.NewRequest(http.MethodGet, "/?"+tt.query, nil)
+			queryDriver.WithFilterSet(
+				filters.NewFilterSet[FilteredModel]().
+					WithField("id", filters.In).
+					WithField("name", filters.Exact, filters.IExact, filters.Contains, filters.IContains).
+					WithField("price", filters.Gt, filters.Gte, filters.Lt, filters.Lte, filters.Range),
+			)
+
+			// when
+			applyErr := queryDriver.Filter().Apply(ctx)
+			list, listErr := queryDriver.CRUD().List(ctx)
+
+			// then
+			assert.NoError(t, applyErr)
+			assert.NoError(t, listErr)
+			ids := []uint{}
+			for _, item := range list {
+				ids = append(ids, item["id"].(uint))
+			}
+			assert.ElementsMatch(t, tt.wantIDs, ids)
+		})
+	}
+}
+
+type NullableFilteredModel struct {
+	ID        uint       `gorm:"primaryKey" json:"id"`
+	Stock     *int       `json:"stock"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+func TestGormFilterSetNullableFields(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   string
+		wantIDs []uint
+	}{
+		{"pointer gte", "stock__gte=5", []uint{2}},
+		{"pointer isnull", "stock__isnull=true", []uint{1}},
+		{"time pointer lt", "expires_at__lt=2024-06-01T00:00:00Z", []uint{1}},
+		{"time pointer isnull", "expires_at__isnull=true", []uint{2}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// given
+			ctx, queryDriver := prepareCtx[NullableFilteredModel](t)
+			stock := 7
+			expiresAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+			for _, intVal := range []models.InternalValue{
+				{"stock": (*int)(nil), "expires_at": &expiresAt},
+				{"stock": &stock, "expires_at": (*time.Time)(nil)},
+			} {
+				_, createErr := queryDriver.CRUD().Create(ctx, intVal)
+				assert.NoError(t, createErr)
+			}
+			ctx.Request, _ = http.NewRequest(http.MethodGet, "/?"+tt.query, nil)
+			queryDriver.WithFilterSet(
+				filters.NewFilterSet[NullableFilteredModel]().
+					WithField("stock", filters.Gte, filters.IsNull).
+					WithField("expires_at", filters.Lt, filters.IsNull),
+			)
+
+			// when
+			applyErr := queryDriver.Filter().Apply(ctx)
+			list, listErr := queryDriver.CRUD().List(ctx)
+
+			// then
+			assert.NoError(t, applyErr)
+			assert.NoError(t, listErr)
+			ids := []uint{}
+			for _, item := range list {
+				ids = append(ids, item["id"].(uint))
+			}
+			assert.ElementsMatch(t, tt.wantIDs, ids)
+		})
+	}
+}
+
+type DocumentModel struct {
+	ID         uint                             `gorm:"primaryKey" json:"id"`
+	Attributes models.JSONField[map[string]any] `json:"attributes"`
+}
+
+func TestGormFilterSetJSONDocumentKeys(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   string
+		wantIDs []uint
+	}{
+		{"exact string", "attributes__color=red", []uint{1, 3}},
+		{"exact number", "attributes__size__width=10", []uint{1}},
+		{"exact bool", "attributes__new=true", []uint{2}},
+		{"iexact", "attributes__color__iexact=RED", []uint{1, 3}},
+		{"icontains", "attributes__color__icontains=LU", []uint{2}},
+		{"gt", "attributes__size__width__gt=10", []uint{2}},
+		{"in", "attributes__color__in=red,blue", []uint{1, 2, 3}},
+		{"range", "attributes__size__width__range=5,10", []uint{1}},
+		{"isnull", "attributes__size__isnull=true", []uint{3}},
+		{"combined", "attributes__color=red&attributes__size__isnull=false", []uint{1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// given
+			ctx, queryDriver := prepareCtx[DocumentModel](t)
+			for _, attributes := range []map[string]any{
+				{"color": "red", "size": map[string]any{"width": 10}},
+				{"color": "blue", "size": map[string]any{"width": 20}, "new": true},
+				{"color": "red"},
+			} {
+				_, createErr := queryDriver.CRUD().Create(
+					ctx, models.InternalValue{"attributes": models.NewJSONField(attributes)},
+				)
+				assert.NoError(t, createErr)
+			}
+			ctx.Request, _ = http.NewRequest(http.MethodGet, "/?"+tt.query, nil)
+			queryDriver.WithFilterSet(
+				filters.NewFilterSet[DocumentModel]().WithField("attributes", filters.AllLookups...),
+			)
+
+			// when
+			applyErr := queryDriver.Filter().Apply(ctx)
+			list, listErr := queryDriver.CRUD().List(ctx)
+
+			// then
+			assert.NoError(t, applyErr)
+			assert.NoError(t, listErr)
+			ids := []uint{}
+			for _, item := range list {
+				ids = append(ids, item["id"].(uint))
+			}
+			assert.ElementsMatch(t, tt.wantIDs, ids)
+		})
+	}
+}
+
+func TestGormFilterSetInvalidValue(t *testing.T) {
+	// given
+	ctx, queryDriver := prepareCtx[FilteredModel](t)
+	ctx.Request, _ = http.NewRequest(http.MethodGet, "/?price__gt=abc", nil)
+	queryDriver.WithFilterSet(filters.NewFilterSet[FilteredModel]().WithField("price", filters.Gt))
+
+	// when
+	applyErr := queryDriver.Filter().Apply(ctx)
+
+	// then
+	var validationErr *serializers.ValidationError
+	assert.ErrorAs(t, applyErr, &validationErr)
+	assert.Contains(t, validationErr.FieldErrors, "price__gt")
+}
+
+func TestGormOrdering(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   string
+		wantIDs []uint
+	}{
+		{"default", "", []uint{3, 2, 1}},
+		{"ties are broken by WithOrderBy", "ordering=price", []uint{2, 1, 3}},
+		{"multiple fields", "ordering=-price,name", []uint{3, 2, 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// given
+			ctx, queryDriver := prepareCtx[FilteredModel](t)
+			for _, intVal := range []models.InternalValue{
+				{"name": "bob", "price": 10.0},
+				{"name": "alice", "price": 10.0},
+				{"name": "david", "price": 30.0},
+			} {
+				_, createErr := queryDriver.CRUD().Create(ctx, intVal)
+				assert.NoError(t, createErr)
+			}
+			ctx.Request, _ = http.NewRequest(http.MethodGet, "/?"+tt.query, nil)
+			queryDriver.WithOrdering(
+				filters.NewOrdering[FilteredModel]("name", "price").WithDefault("-price"),
+			).WithOrderBy("id DESC")
+
+			// when
+			applyErr := queryDriver.Order().Apply(ctx)
+			list, listErr := queryDriver.CRUD().List(ctx)
+
+			// then
+			assert.NoError(t, applyErr)
+			assert.NoError(t, listErr)
+			ids := []uint{}
+			for _, item := range list {
+				ids = append(ids, item["id"].(uint))
+			}
+			assert.Equal(t, tt.wantIDs, ids)
+		})
+	}
+}
+
+func TestGormOrderingUnknownField(t *testing.T) {
+	// given
+	ctx, queryDriver := prepareCtx[FilteredModel](t)
+	ctx.Request, _ = http.NewRequest(http.MethodGet, "/?ordering=id", nil)
+	queryDriver.WithOrdering(filters.NewOrdering[FilteredModel]("name"))
+
+	// when
+	applyErr := queryDriver.Order().Apply(ctx)
+
+	// then
+	var validationErr *serializers.ValidationError
+	assert.ErrorAs(t, applyErr, &validationErr)
+	assert.Equal(t, map[string][]string{"ordering": {"Ordering by `id` is not allowed"}}, validationErr.FieldErrors)
+}
@@ -1,15 +1,16 @@
 package gormq
 
 import (
+	"errors"
 	"fmt"
 	"reflect"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/glothriel/grf/pkg/detectors"
 	"github.com/glothriel/grf/pkg/models"
 	"github.com/glothriel/grf/pkg/queries/common"
 	"github.com/glothriel/grf/pkg/queries/crud"
-	"github.com/sirupsen/logrus"
 	"gorm.io/gorm"
 )
 
@@ -19,43 +20,70 @@ type gormQueryMod[Model any] struct {
 	modFunc GormFilterFunc
 }
 
-func (g gormQueryMod[Model]) Apply(ctx *gin.Context) {
-	CtxSetQuery(ctx, g.modFunc(ctx, CtxQuery(ctx)))
+// Apply returns the error of the modified query, so GormFilterFunc can abort the request using db.AddError
+func (g gormQueryMod[Model]) Apply(ctx *gin.Context) error {
+	db := g.modFunc(ctx, CtxQuery(ctx))
+	CtxSetQuery(ctx, db)
+	return db.Error
 }
 
 type gormPagination[Model any] struct {
 	child Pagination
 }
 
-func (g gormPagination[Model]) Apply(ctx *gin.Context) {
-	CtxSetQuery(ctx, g.child.Apply(ctx, CtxQuery(ctx)))
+func (g gormPagination[Model]) Apply(ctx *gin.Context) error {
+	var empty Model
+	db := g.child.Apply(ctx, CtxQuery(ctx).Model(&empty))
+	CtxSetQuery(ctx, db)
+	return db.Error
 }
 
 func (g gormPagination[Model]) Format(ctx *gin.Context, elems []any) (any, error) {
 	return g.child.Format(ctx, elems)
 }
 
+func (g gormPagination[Model]) ProcessList(ctx *gin.Context, intVals []models.InternalValue) ([]models.InternalValue, error) {
+	if processor, ok := g.child.(common.ListProcessor); ok {
+		return processor.ProcessList(ctx, intVals)
+	}
+	return intVals, nil
+}
+
+func (g gormPagination[Model]) Envelope() *common.PaginationEnvelope {
+	if describer, ok := g.child.(common.EnvelopeDescriber); ok {
+		return describer.Envelope()
+	}
+	return nil
+}
+
 type GormQueryDriver[Model any] struct {
+	scope            *gormQueryMod[Model]
+	filterSet        *gormQueryMod[Model]
 	filter           *gormQueryMod[Model]
 	preloads         *gormQueryMod[Model]
+	preloadList      []preload
 	fieldNames       map[string]string
 	preloadedQueries []string
+	ordering         *gormQueryMod[Model]
 	order            *gormQueryMod[Model]
 	pagination       *gormPagination[Model]
+	q                *crud.CRUD[Model]
 
 	middleware []gin.HandlerFunc
 }
 
+// CRUD returns the queries used by the views. The driver always returns the same instance, so the queries
+// can be replaced or wrapped (for example using CreateTx) before the ViewSet is registered.
 func (g GormQueryDriver[Model]) CRUD() *crud.CRUD[Model] {
-	return GormQueries[Model](g.preloadedQueries)
+	return g.q
 }
 
 func (g GormQueryDriver[Model]) Filter() common.QueryMod {
-	return common.NewCompositeQueryMod(g.filter, g.preloads)
+	return common.NewCompositeQueryMod(g.scope, g.filterSet, g.filter, g.preloads)
 }
 
 func (g GormQueryDriver[Model]) Order() common.QueryMod {
-	return g.order
+	return common.NewCompositeQueryMod(g.ordering, g.order)
 }
 
 func (g GormQueryDriver[Model]) Pagination() common.Pagination {
@@ -71,20 +99,6 @@ func (g *GormQueryDriver[Model]) WithFilter(filterFunc GormFilterFunc) *GormQuer
 	return g
 }
 
-func (g *GormQueryDriver[Model]) WithPreload(query string, args ...any) *GormQueryDriver[Model] {
-	g.preloads.modFunc = func(ctx *gin.Context, db *gorm.DB) *gorm.DB {
-		fieldName, ok := g.fieldNames[query]
-		if !ok {
-			logrus.Errorf("Could not find field name for query %s, skipping preload", query)
-		} else {
-			db = db.Preload(fieldName, args...)
-		}
-		return db
-	}
-	g.preloadedQueries = append(g.preloadedQueries, query)
-	return g
-}
-
 func (g *GormQueryDriver[Model]) WithPagination(pagination Pagination) *GormQueryDriver[Model] {
 	g.pagination.child = pagination
 	return g
@@ -98,15 +112,25 @@ func (g *GormQueryDriver[Model]) WithOrderBy(orderClause any) *GormQueryDriver[M
 }
 
 func Gorm[Model any](factory GormORMFactory) *GormQueryDriver[Model] {
-	return &GormQueryDriver[Model]{
+	driver := &GormQueryDriver[Model]{
 		preloadedQueries: []string{},
 		fieldNames:       detectors.FieldNames[Model](),
+		scope: &gormQueryMod[Model]{
+			modFunc: func(ctx *gin.Context, db *gorm.DB) *gorm.DB {
+				return db
+			},
+		},
+		filterSet: &gormQueryMod[Model]{
+			modFunc: func(ctx *gin.Context, db *gorm.DB) *gorm.DB {
+				return db
+			},
+		},
 		filter: &gormQueryMod[Model]{
 			modFunc: func(ctx *gin.Context, db *gorm.DB) *gorm.DB {
 				return db
 			},
 		},
-		preloads: &gormQueryMod[Model]{
+		ordering: &gormQueryMod[Model]{
 			modFunc: func(ctx *gin.Context, db *gorm.DB) *gorm.DB {
 				return db
 			},
@@ -127,18 +151,24 @@ func Gorm[Model any](factory GormORMFactory) *GormQueryDriver[Model] {
 			},
 		},
 	}
+	driver.preloads = &gormQueryMod[Model]{modFunc: driver.applyPreloads}
+	driver.q = gormQueries[Model](func() []string { return driver.preloadedQueries })
+	return driver
 }
 
 // GormQueries returns default queries providing basic CRUD functionality
 func GormQueries[Model any](preloadedQueries []string) *crud.CRUD[Model] {
-	ConvertFromDBToInternalValue := FromDBConverter[Model]()
+	return gormQueries[Model](func() []string { return preloadedQueries })
+}
+
+// gormQueries takes a function returning the preloaded queries, so the preloads added to the driver after
+// the queries are created are taken into account
+func gormQueries[Model any](preloadedQueries func() []string) *crud.CRUD[Model] {
+	updatableFields := updatableFieldNames[Model]()
 	var empty Model
-	var preloadedQueriesMap = make(map[string]bool)
-	for _, query := range preloadedQueries {
-		preloadedQueriesMap[query] = true
-	}
 	return &crud.CRUD[Model]{
 		List: func(ctx *gin.Context) ([]models.InternalValue, error) {
+			tree := newPreloadTree(preloadedQueries())
 			rawEntities := []models.InternalValue{}
 			typedEntities := []Model{}
 			findErr := CtxQuery(ctx).Model(&empty).Find(&typedEntities).Error
@@ -146,40 +176,22 @@ func GormQueries[Model any](preloadedQueries []string) *crud.CRUD[Model] {
 				return nil, findErr
 			}
 			for _, entity := range typedEntities {
-				iv := models.AsInternalValue(entity)
-				for k, v := range iv {
-					if _, ok := preloadedQueriesMap[k]; ok {
-						vValue := reflect.ValueOf(v)
-
-						if vValue.Kind() == reflect.Slice {
-							newSlice := make([]any, vValue.Len())
-
-							for i := 0; i < vValue.Len(); i++ {
-								newSlice[i] = models.AsInternalValue(vValue.Index(i).Interface())
-							}
-
-							iv[k] = newSlice
-						} else {
-							iv[k] = models.AsInternalValue(v)
-						}
-					} else {
-						iv[k] = v
-					}
-				}
-				rawEntities = append(rawEntities, iv)
+				rawEntities = append(rawEntities, entityAsInternalValue(entity, tree))
 			}
-			return rawEntities, findErr
+			return rawEntities, nil
 		},
 		Retrieve: func(ctx *gin.Context, id any) (models.InternalValue, error) {
-			var rawEntity map[string]any
-			retrieveErr := CtxQuery(ctx).Model(&empty).First(&rawEntity, "id = ?", id).Error
+			var entity Model
+			// The views retrieve the instance before updating or destroying it using the same query, a new
+			// session keeps the conditions of the queries separate
+			retrieveErr := CtxQuery(ctx).Session(&gorm.Session{}).Model(&empty).First(&entity, "id = ?", id).Error
 			if retrieveErr != nil {
 				if retrieveErr == gorm.ErrRecordNotFound {
 					return nil, common.ErrorNotFound
 				}
 				return nil, retrieveErr
 			}
-			return ConvertFromDBToInternalValue(rawEntity)
+			return entityAsInternalValue(entity, newPreloadTree(preloadedQueries())), nil
 		},
 		Create: func(ctx *gin.Context, m models.InternalValue) (models.InternalValue, error) {
 			entity, asModelErr := models.AsModel[Model](m)
@@ -187,7 +199,10 @@ func GormQueries[Model any](preloadedQueries []string) *crud.CRUD[Model] {
 				return nil, asModelErr
 			}
 			createErr := CtxQuery(ctx).Model(&empty).Create(&entity).Error
-			return models.AsInternalValue(entity), createErr
+			if createErr != nil {
+				return nil, TranslateError[Model](CtxQuery(ctx), createErr)
+			}
+			return models.AsInternalValue(entity), nil
 		},
 		Update: func(ctx *gin.Context, old models.InternalValue, new models.InternalValue, id any) (
 			models.InternalValue, error,
@@ -196,17 +211,30 @@ func GormQueries[Model any](preloadedQueries []string) *crud.CRUD[Model] {
 			if asModelErr != nil {
 				return nil, asModelErr
 			}
-			updateErr := CtxQuery(ctx).Model(&entity).Updates(&entity).Error
+			// Selecting the columns explicitly makes GORM write zero values too, which it skips
+			// when updating with a struct
+			selectedFields := []string{}
+			for k := range new {
+				if fieldName, ok := updatableFields[k]; ok {
+					selectedFields = append(selectedFields, fieldName)
+				}
+			}
+			updateErr := CtxQuery(ctx).Session(&gorm.Session{}).Model(&entity).Select(selectedFields).Updates(&entity).Error
 			if updateErr != nil {
-				return nil, updateErr
+				return nil, TranslateError[Model](CtxQuery(ctx), updateErr)
 			}
 			return models.AsInternalValue(entity), nil
 		},
 		Destroy: func(ctx *gin.Context, id any) error {
 			var m Model
 			errWrapMsg := "could not delete entity"
-			queryResult := CtxQuery(ctx).Model(&empty).Delete(&m, "id = ?", id)
+			queryResult := CtxQuery(ctx).Session(&gorm.Session{}).Model(&empty).Delete(&m, "id = ?", id)
 			if queryResult.Error != nil {
+				var constraintErr *common.ConstraintError
+				translatedErr := TranslateDestroyError[Model](CtxQuery(ctx), queryResult.Error)
+				if errors.As(translatedErr, &constraintErr) {
+					return constraintErr
+				}
 				return fmt.Errorf(
 					"%s: query error: %w", errWrapMsg, queryResult.Error,
 				)
@@ -219,6 +247,24 @@ func GormQueries[Model any](preloadedQueries []string) *crud.CRUD[Model] {
 	}
 }
 
+// updatableFieldNames maps JSON names of the model fields, that can be set during update, to struct field
+// names. Primary key and relations are excluded.
+func updatableFieldNames[Model any]() map[string]string {
+	var m Model
+	ret := map[string]string{}
+	for _, field := range reflect.VisibleFields(reflect.TypeOf(m)) {
+		jsonTag := strings.Split(field.Tag.Get("json"), ",")[0]
+		if field.Anonymous || jsonTag == "" || jsonTag == "-" || jsonTag == "id" {
+			continue
+		}
+		if _, isRelation := models.ParseTag(field)[models.TagIsRelation]; isRelation {
+			continue
+		}
+		ret[jsonTag] = field.Name
+	}
+	return ret
+}
+
 // FromDBConverter internally uses *sql.Scanner to convert a map[string]any to an InternalValue
 // as GORM does this only for structs
 func FromDBConverter[Model any]() func(map[string]any) (models.InternalValue, error) {
@@ -7,6 +7,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/glothriel/grf/pkg/models"
+	"github.com/glothriel/grf/pkg/queries/common"
 	"github.com/stretchr/testify/assert"
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
@@ -152,6 +153,24 @@ func TestGormDBUpdateQuery(t *testing.T) {
 	assert.Equal(t, models.InternalValue{"foo": "baz", "id": intVal["id"]}, item)
 }
 
+func TestGormDBUpdateQueryWritesZeroValues(t *testing.T) {
+	// given
+	ctx, queryDriver := prepareCtx[MockModel](t)
+
+	// when
+	intVal, createErr := queryDriver.CRUD().Create(ctx, models.InternalValue{"foo": "bar"})
+	_, updateErr := queryDriver.CRUD().Update(
+		ctx, intVal, models.InternalValue{"id": intVal["id"], "foo": ""}, intVal["id"],
+	)
+	item, retrieveErr := queryDriver.CRUD().Retrieve(ctx, intVal["id"])
+
+	// then
+	assert.NoError(t, createErr)
+	assert.NoError(t, updateErr)
+	assert.NoError(t, retrieveErr)
+	assert.Equal(t, "", item["foo"])
+}
+
 func TestGormDBDestroyQuery(t *testing.T) {
 	// given
 	ctx, queryDriver := prepareCtx[MockModel](t)
@@ -191,6 +210,43 @@ func TestGormPagination(t *testing.T) {
 	assert.Equal(t, []any{1, 2, 3}, formatted)
 }
 
+func TestGormPaginationCountsFilteredResults(t *testing.T) {
+	// given
+	ctx, queryDriver := prepareCtx[MockModel](t)
+	ctx.Request = httptest.NewRequest("GET", "/mocks?page=2&page_size=1", nil)
+	for _, model := range []models.InternalValue{
+		{"foo": "bob"},
+		{"foo": "alice"},
+		{"foo": "david"},
+		{"foo": "anna"},
+	} {
+		var localM = model
+		_, createErr := queryDriver.CRUD().Create(ctx, localM)
+		assert.NoError(t, createErr)
+	}
+	queryDriver.WithFilter(func(ctx *gin.Context, db *gorm.DB) *gorm.DB {
+		return db.Where("foo LIKE ?", "a%")
+	}).WithOrderBy("foo ASC").WithPagination(&PageNumberPagination{})
+
+	// when
+	for _, queryMod := range []common.QueryMod{
+		queryDriver.Filter(), queryDriver.Order(), queryDriver.Pagination(),
+	} {
+		assert.NoError(t, queryMod.Apply(ctx))
+	}
+	list, listErr := queryDriver.CRUD().List(ctx)
+	formatted, formatErr := queryDriver.Pagination().Format(ctx, []any{list[0]})
+
+	// then
+	assert.NoError(t, listErr)
+	assert.NoError(t, formatErr)
+	assert.Equal(t, PaginatedResponse{
+		Count:    2,
+		Previous: strPtr("http://example.com/mocks?page=1&page_size=1"),
+		Results:  []any{models.InternalValue{"id": uint(4), "foo": "anna"}},
+	}, formatted)
+}
+
 func TestGormOrder(t *testing.T) {
 	// given
 	ctx, queryDriver := prepareCtx[MockModel](t)
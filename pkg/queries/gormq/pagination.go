@@ -1,18 +1,31 @@
 package gormq
 
 import (
+	"fmt"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
-	"github.com/sirupsen/logrus"
+	"github.com/glothriel/grf/pkg/queries/common"
+	"github.com/glothriel/grf/pkg/serializers"
 	"gorm.io/gorm"
 )
 
+// Pagination limits the results of the List query and formats the response. The query passed to Apply
+// already has the filters applied and the Model set. Apply can abort the request using db.AddError. The
+// paginations implementing common.ListProcessor get the listed instances before they are serialized.
 type Pagination interface {
 	Apply(*gin.Context, *gorm.DB) *gorm.DB
 	Format(*gin.Context, []any) (any, error)
 }
 
+// PaginatedResponse is the envelope returned by the paginations that know the total count of the results
+type PaginatedResponse struct {
+	Count    int64   `json:"count"`
+	Next     *string `json:"next"`
+	Previous *string `json:"previous"`
+	Results  []any   `json:"results"`
+}
+
 type NoPagination struct{}
 
 func (p *NoPagination) Apply(_ *gin.Context, db *gorm.DB) *gorm.DB {
@@ -23,30 +36,179 @@ func (p *NoPagination) Format(_ *gin.Context, entities []any) (any, error) {
 	return entities, nil
 }
 
+// LimitOffsetPagination uses `?limit=` and `?offset=` query params
 type LimitOffsetPagination struct {
+	// DefaultLimit is used when the limit param is absent, 0 means no limit
+	DefaultLimit int
+	// MaxLimit caps the limit requested by the client, 0 means no cap
+	MaxLimit int
 }
 
 func (p *LimitOffsetPagination) Apply(c *gin.Context, db *gorm.DB) *gorm.DB {
-	if c.Query("limit") != "" {
-		limit, conversionErr := strconv.Atoi(c.Query("limit"))
-		if conversionErr == nil {
-			db = db.Limit(limit)
-		} else {
-			logrus.Debug("Failed to convert limit to int in LimitOffsetPagination")
+	limit, limitErr := queryParamInt(c, "limit", p.DefaultLimit, 1)
+	if limitErr != nil {
+		_ = db.AddError(limitErr)
+		return db
+	}
+	if p.MaxLimit > 0 && (limit > p.MaxLimit || limit == 0) {
+		limit = p.MaxLimit
+	}
+	offset, offsetErr := queryParamInt(c, "offset", 0, 0)
+	if offsetErr != nil {
+		_ = db.AddError(offsetErr)
+		return db
+	}
+	count, countErr := countResults(db)
+	if countErr != nil {
+		_ = db.AddError(countErr)
+		return db
+	}
+
+	state := &paginationState{count: count}
+	if limit > 0 {
+		db = db.Limit(limit)
+		if int64(offset+limit) < count {
+			state.next = pageURL(c, map[string]string{"limit": strconv.Itoa(limit), "offset": strconv.Itoa(offset + limit)})
 		}
 	}
-	if c.Query("offset") != "" {
-		offset, conversionErr := strconv.Atoi(c.Query("offset"))
-		if conversionErr == nil {
-			db = db.Offset(offset)
-		} else {
-			logrus.Debug("Failed to convert offset to int in LimitOffsetPagination")
+	if offset > 0 {
+		db = db.Offset(offset)
+		// Without a limit the current page holds all the remaining results, so the previous one holds all the
+		// skipped ones
+		previousParams := map[string]string{"offset": "0", "limit": strconv.Itoa(offset)}
+		if limit > 0 {
+			previousParams = map[string]string{"offset": strconv.Itoa(max(offset-limit, 0)), "limit": strconv.Itoa(limit)}
 		}
+		state.previous = pageURL(c, previousParams)
 	}
-
+	c.Set(ctxPaginationKey, state)
 	return db
 }
 
+func (p *LimitOffsetPagination) Envelope() *common.PaginationEnvelope {
+	return &common.PaginationEnvelope{Count: true}
+}
+
 func (p *LimitOffsetPagination) Format(c *gin.Context, entities []any) (any, error) {
-	return entities, nil
+	return formatPaginatedResponse(c, entities), nil
+}
+
+// DefaultPageSize is used by PageNumberPagination if PageSize is not set
+const DefaultPageSize = 100
+
+// PageNumberPagination uses `?page=` (starting from 1) and `?page_size=` query params
+type PageNumberPagination struct {
+	// PageSize is used when the page_size param is absent, DefaultPageSize if not set
+	PageSize int
+	// MaxPageSize caps the page size requested by the client, 0 means no cap
+	MaxPageSize int
+}
+
+func (p *PageNumberPagination) Apply(c *gin.Context, db *gorm.DB) *gorm.DB {
+	defaultPageSize := p.PageSize
+	if defaultPageSize <= 0 {
+		defaultPageSize = DefaultPageSize
+	}
+	pageSize, pageSizeErr := queryParamInt(c, "page_size", defaultPageSize, 1)
+	if pageSizeErr != nil {
+		_ = db.AddError(pageSizeErr)
+		return db
+	}
+	if p.MaxPageSize > 0 && pageSize > p.MaxPageSize {
+		pageSize = p.MaxPageSize
+	}
+	page, pageErr := queryParamInt(c, "page", 1, 1)
+	if pageErr != nil {
+		_ = db.AddError(pageErr)
+		return db
+	}
+	count, countErr := countResults(db)
+	if countErr != nil {
+		_ = db.AddError(countErr)
+		return db
+	}
+
+	state := &paginationState{count: count}
+	if int64(page*pageSize) < count {
+		state.next = pageURL(c, map[string]string{"page": strconv.Itoa(page + 1)})
+	}
+	if page > 1 {
+		state.previous = pageURL(c, map[string]string{"page": strconv.Itoa(page - 1)})
+	}
+	c.Set(ctxPaginationKey, state)
+	return db.Limit(pageSize).Offset((page - 1) * pageSize)
+}
+
+func (p *PageNumberPagination) Envelope() *common.PaginationEnvelope {
+	return &common.PaginationEnvelope{Count: true}
+}
+
+func (p *PageNumberPagination) Format(c *gin.Context, entities []any) (any, error) {
+	return formatPaginatedResponse(c, entities), nil
+}
+
+const ctxPaginationKey = "db:gorm:pagination"
+
+// paginationState is passed from Apply to Format using the request context
+type paginationState struct {
+	count    int64
+	next     *string
+	previous *string
+}
+
+func formatPaginatedResponse(c *gin.Context, entities []any) PaginatedResponse {
+	response := PaginatedResponse{Count: int64(len(entities)), Results: entities}
+	if anyVal, ok := c.Get(ctxPaginationKey); ok {
+		if state, ok := anyVal.(*paginationState); ok {
+			response.Count = state.count
+			response.Next = state.next
+			response.Previous = state.previous
+		}
+	}
+	return response
+}
+
+// countResults counts the rows matching the query, GORM drops the ORDER BY clause when counting
+func countResults(db *gorm.DB) (int64, error) {
+	var count int64
+	countErr := db.Session(&gorm.Session{}).Count(&count).Error
+	return count, countErr
+}
+
+// queryParamInt parses an integer query param, returning a *serializers.ValidationError if it's not a number
+// or is lower than minValue
+func queryParamInt(c *gin.Context, param string, defaultValue, minValue int) (int, error) {
+	raw := c.Query(param)
+	if raw == "" {
+		return defaultValue, nil
+	}
+	value, conversionErr := strconv.Atoi(raw)
+	if conversionErr != nil || value < minValue {
+		return 0, &serializers.ValidationError{FieldErrors: map[string][]string{
+			param: {fmt.Sprintf("Must be an integer greater than or equal to %d", minValue)},
+		}}
+	}
+	return value, nil
+}
+
+// pageURL returns the URL of the current request with the given query params replaced
+func pageURL(c *gin.Context, params map[string]string) *string {
+	if c.Request == nil || c.Request.URL == nil {
+		return nil
+	}
+	pageURL := *c.Request.URL
+	query := pageURL.Query()
+	for k, v := range params {
+		query.Set(k, v)
+	}
+	pageURL.RawQuery = query.Encode()
+	if pageURL.Host == "" && c.Request.Host != "" {
+		pageURL.Host = c.Request.Host
+		pageURL.Scheme = "http"
+		if c.Request.TLS != nil {
+			pageURL.Scheme = "https"
+		}
+	}
+	ret := pageURL.String()
+	return &ret
 }
@@ -5,77 +5,271 @@ import (
 	"testing"
 
 	"github.com/gin-gonic/gin"
+	"github.com/glothriel/grf/pkg/serializers"
 	"github.com/stretchr/testify/assert"
-	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
 )
 
+type paginatedModel struct {
+	ID   uint   `json:"id" gorm:"primaryKey"`
+	Name string `json:"name"`
+}
+
+func preparePaginationDB(t *testing.T, rows int) *gorm.DB {
+	db := prepareGorm(t)
+	assert.NoError(t, db.AutoMigrate(&paginatedModel{}))
+	for i := 0; i < rows; i++ {
+		assert.NoError(t, db.Create(&paginatedModel{Name: "foo"}).Error)
+	}
+	return db.Model(&paginatedModel{})
+}
+
+func paginationCtx(url string) *gin.Context {
+	ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+	ctx.Request = httptest.NewRequest("GET", url, nil)
+	return ctx
+}
+
+func strPtr(s string) *string {
+	return &s
+}
+
 func TestLimitOffsetPagination_Apply(t *testing.T) {
 	// given
 	p := &LimitOffsetPagination{}
-	ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
-	ctx.Request = httptest.NewRequest("GET", "/test?limit=20&offset=10", nil)
-
-	db, openErr := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	ctx := paginationCtx("/test?limit=20&offset=10")
+	db := preparePaginationDB(t, 0)
 
 	// when
 	newDb := p.Apply(ctx, db)
 	limitClause := newDb.Statement.Clauses["LIMIT"].Expression.(clause.Limit)
 
 	// then
-	assert.NoError(t, openErr)
+	assert.NoError(t, newDb.Error)
 	assert.Equal(t, 10, limitClause.Offset)
 	assert.Equal(t, 20, *limitClause.Limit)
 }
 
-func TestLimitOffsetPaginationApplyInvalidLimit(t *testing.T) {
-	// given
-	p := &LimitOffsetPagination{}
-	ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
-	ctx.Request = httptest.NewRequest("GET", "/test?limit=invalid&offset=10", nil)
+func TestLimitOffsetPaginationApplyLimits(t *testing.T) {
+	tests := []struct {
+		name       string
+		pagination *LimitOffsetPagination
+		query      string
+		wantLimit  *int
+	}{
+		{"no limit by default", &LimitOffsetPagination{}, "", nil},
+		{"default limit", &LimitOffsetPagination{DefaultLimit: 5}, "", intPtr(5)},
+		{"requested limit", &LimitOffsetPagination{DefaultLimit: 5}, "limit=7", intPtr(7)},
+		{"max limit caps requested limit", &LimitOffsetPagination{MaxLimit: 10}, "limit=50", intPtr(10)},
+		{"max limit is used without limit", &LimitOffsetPagination{MaxLimit: 10}, "", intPtr(10)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// given
+			ctx := paginationCtx("/test?" + tt.query)
+			db := preparePaginationDB(t, 0)
 
-	db, openErr := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+			// when
+			newDb := tt.pagination.Apply(ctx, db)
 
-	// when
-	newDb := p.Apply(ctx, db)
-	limitClause := newDb.Statement.Clauses["LIMIT"].Expression.(clause.Limit)
+			// then
+			assert.NoError(t, newDb.Error)
+			limitClause, hasLimit := newDb.Statement.Clauses["LIMIT"]
+			if tt.wantLimit == nil {
+				assert.False(t, hasLimit)
+				return
+			}
+			assert.Equal(t, *tt.wantLimit, *limitClause.Expression.(clause.Limit).Limit)
+		})
+	}
+}
 
-	// then
-	assert.NoError(t, openErr)
-	assert.Equal(t, 10, limitClause.Offset)
-	assert.Nil(t, limitClause.Limit)
+func intPtr(i int) *int {
+	return &i
 }
 
-func TestLimitOffsetPaginationApplyInvalidOffset(t *testing.T) {
-	// given
-	p := &LimitOffsetPagination{}
-	ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
-	ctx.Request = httptest.NewRequest("GET", "/test?limit=20&offset=invalid", nil)
+func TestLimitOffsetPaginationApplyInvalidParams(t *testing.T) {
+	tests := []struct {
+		name      string
+		query     string
+		wantError map[string][]string
+	}{
+		{"invalid limit", "limit=invalid&offset=10", map[string][]string{
+			"limit": {"Must be an integer greater than or equal to 1"},
+		}},
+		{"zero limit", "limit=0", map[string][]string{
+			"limit": {"Must be an integer greater than or equal to 1"},
+		}},
+		{"invalid offset", "limit=20&offset=invalid", map[string][]string{
+			"offset": {"Must be an integer greater than or equal to 0"},
+		}},
+		{"negative offset", "offset=-1", map[string][]string{
+			"offset": {"Must be an integer greater than or equal to 0"},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// given
+			p := &LimitOffsetPagination{}
+			ctx := paginationCtx("/test?" + tt.query)
+			db := preparePaginationDB(t, 0)
 
-	db, openErr := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+			// when
+			newDb := p.Apply(ctx, db)
 
-	// when
-	newDb := p.Apply(ctx, db)
-	limitClause := newDb.Statement.Clauses["LIMIT"].Expression.(clause.Limit)
-
-	// then
-	assert.NoError(t, openErr)
-	assert.Equal(t, 0, limitClause.Offset)
-	assert.Equal(t, 20, *limitClause.Limit)
+			// then
+			assert.Equal(t, &serializers.ValidationError{FieldErrors: tt.wantError}, newDb.Error)
+		})
+	}
 }
 
 func TestLimitOffsetPaginationFormat(t *testing.T) {
-	// given
-	p := &LimitOffsetPagination{}
-	entities := []any{"test"}
+	tests := []struct {
+		name         string
+		query        string
+		wantNext     *string
+		wantPrevious *string
+	}{
+		{"first page", "limit=2", strPtr("http://example.com/test?limit=2&offset=2"), nil},
+		{
+			"middle page",
+			"limit=2&offset=2&name=foo",
+			strPtr("http://example.com/test?limit=2&name=foo&offset=4"),
+			strPtr("http://example.com/test?limit=2&name=foo&offset=0"),
+		},
+		{"last page", "limit=2&offset=4", nil, strPtr("http://example.com/test?limit=2&offset=2")},
+		{"previous does not go below zero", "limit=2&offset=1", strPtr("http://example.com/test?limit=2&offset=3"), strPtr("http://example.com/test?limit=2&offset=0")},
+		{"no limit", "", nil, nil},
+		{"offset without limit", "offset=3", nil, strPtr("http://example.com/test?limit=3&offset=0")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// given
+			p := &LimitOffsetPagination{}
+			ctx := paginationCtx("http://example.com/test?" + tt.query)
+			db := preparePaginationDB(t, 5)
+			entities := []any{"test"}
 
-	// when
-	formattedEntities, err := p.Format(&gin.Context{}, entities)
+			// when
+			applyErr := p.Apply(ctx, db).Error
+			formatted, formatErr := p.Format(ctx, entities)
 
-	// then
-	assert.NoError(t, err)
-	assert.Equal(t, entities, formattedEntities)
+			// then
+			assert.NoError(t, applyErr)
+			assert.NoError(t, formatErr)
+			assert.Equal(t, PaginatedResponse{
+				Count:    5,
+				Next:     tt.wantNext,
+				Previous: tt.wantPrevious,
+				Results:  entities,
+			}, formatted)
+		})
+	}
+}
+
+func TestPageNumberPaginationApply(t *testing.T) {
+	tests := []struct {
+		name       string
+		pagination *PageNumberPagination
+		query      string
+		wantLimit  int
+		wantOffset int
+	}{
+		{"default page size", &PageNumberPagination{}, "", DefaultPageSize, 0},
+		{"configured page size", &PageNumberPagination{PageSize: 10}, "page=3", 10, 20},
+		{"requested page size", &PageNumberPagination{PageSize: 10}, "page=2&page_size=5", 5, 5},
+		{"max page size", &PageNumberPagination{PageSize: 10, MaxPageSize: 20}, "page_size=50", 20, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// given
+			ctx := paginationCtx("/test?" + tt.query)
+			db := preparePaginationDB(t, 0)
+
+			// when
+			newDb := tt.pagination.Apply(ctx, db)
+			limitClause := newDb.Statement.Clauses["LIMIT"].Expression.(clause.Limit)
+
+			// then
+			assert.NoError(t, newDb.Error)
+			assert.Equal(t, tt.wantLimit, *limitClause.Limit)
+			assert.Equal(t, tt.wantOffset, limitClause.Offset)
+		})
+	}
+}
+
+func TestPageNumberPaginationApplyInvalidParams(t *testing.T) {
+	tests := []struct {
+		name      string
+		query     string
+		wantError map[string][]string
+	}{
+		{"invalid page", "page=abc", map[string][]string{
+			"page": {"Must be an integer greater than or equal to 1"},
+		}},
+		{"zero page", "page=0", map[string][]string{
+			"page": {"Must be an integer greater than or equal to 1"},
+		}},
+		{"invalid page size", "page_size=-5", map[string][]string{
+			"page_size": {"Must be an integer greater than or equal to 1"},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// given
+			p := &PageNumberPagination{}
+			ctx := paginationCtx("/test?" + tt.query)
+			db := preparePaginationDB(t, 0)
+
+			// when
+			newDb := p.Apply(ctx, db)
+
+			// then
+			assert.Equal(t, &serializers.ValidationError{FieldErrors: tt.wantError}, newDb.Error)
+		})
+	}
+}
+
+func TestPageNumberPaginationFormat(t *testing.T) {
+	tests := []struct {
+		name         string
+		query        string
+		wantNext     *string
+		wantPrevious *string
+	}{
+		{"first page", "", strPtr("http://example.com/test?page=2"), nil},
+		{
+			"middle page",
+			"page=2&name=foo",
+			strPtr("http://example.com/test?name=foo&page=3"),
+			strPtr("http://example.com/test?name=foo&page=1"),
+		},
+		{"last page", "page=3", nil, strPtr("http://example.com/test?page=2")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// given
+			p := &PageNumberPagination{PageSize: 2}
+			ctx := paginationCtx("http://example.com/test?" + tt.query)
+			db := preparePaginationDB(t, 5)
+			entities := []any{"test"}
+
+			// when
+			applyErr := p.Apply(ctx, db).Error
+			formatted, formatErr := p.Format(ctx, entities)
+
+			// then
+			assert.NoError(t, applyErr)
+			assert.NoError(t, formatErr)
+			assert.Equal(t, PaginatedResponse{
+				Count:    5,
+				Next:     tt.wantNext,
+				Previous: tt.wantPrevious,
+				Results:  entities,
+			}, formatted)
+		})
+	}
 }
 
 func TestNoPagination_Apply(t *testing.T) {
@@ -0,0 +1,148 @@
+package gormq
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/glothriel/grf/pkg/models"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type preload struct {
+	// fieldPath is the path of Go field names passed to GORM, for example `Photos.Tags`
+	fieldPath string
+	args      []any
+}
+
+// WithPreload preloads the relation with the given JSON name in the List and Retrieve queries. Nested
+// relations can be preloaded using dotted paths of JSON names, for example `photos.tags`. The args are passed
+// to gorm's Preload, so they can be used to add conditions to the preload query, for example
+// `WithPreload("photos", "published = ?", true)` or `WithPreload("photos", func(db *gorm.DB) *gorm.DB {...})`.
+// It can be called multiple times, all the relations are preloaded.
+func (g *GormQueryDriver[Model]) WithPreload(query string, args ...any) *GormQueryDriver[Model] {
+	var m Model
+	fieldPath, pathErr := preloadFieldPath(reflect.TypeOf(m), query)
+	if pathErr != nil {
+		logrus.Panicf("Could not preload `%s`: %s", query, pathErr)
+	}
+	g.preloadList = append(g.preloadList, preload{fieldPath: fieldPath, args: args})
+	g.preloadedQueries = append(g.preloadedQueries, query)
+	return g
+}
+
+func (g *GormQueryDriver[Model]) applyPreloads(_ *gin.Context, db *gorm.DB) *gorm.DB {
+	for _, p := range g.preloadList {
+		db = db.Preload(p.fieldPath, p.args...)
+	}
+	return db
+}
+
+// preloadFieldPath translates a dotted path of JSON field names, like `photos.tags`, to the path of Go field
+// names used by GORM, like `Photos.Tags`
+func preloadFieldPath(modelType reflect.Type, jsonPath string) (string, error) {
+	fieldPath := []string{}
+	currentType := modelType
+	for _, jsonName := range strings.Split(jsonPath, ".") {
+		currentType = relatedType(currentType)
+		if currentType.Kind() != reflect.Struct {
+			return "", fmt.Errorf("`%s` is not a relation", strings.Join(fieldPath, "."))
+		}
+		field, ok := fieldByJSONName(currentType, jsonName)
+		if !ok {
+			return "", fmt.Errorf("field `%s` does not exist on `%s`", jsonName, currentType.Name())
+		}
+		fieldPath = append(fieldPath, field.Name)
+		currentType = field.Type
+	}
+	return strings.Join(fieldPath, "."), nil
+}
+
+// relatedType returns the struct type of a relation, which can be a struct, a pointer or a slice
+func relatedType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Pointer || t.Kind() == reflect.Slice {
+		t = t.Elem()
+	}
+	return t
+}
+
+func fieldByJSONName(structType reflect.Type, jsonName string) (reflect.StructField, bool) {
+	for _, field := range reflect.VisibleFields(structType) {
+		if !field.Anonymous && strings.Split(field.Tag.Get("json"), ",")[0] == jsonName {
+			return field, true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+// preloadTree holds the preloaded JSON paths split into segments, for example `photos` and `photos.tags`
+// result in {"photos": {"tags": {}}}
+type preloadTree map[string]preloadTree
+
+func newPreloadTree(paths []string) preloadTree {
+	tree := preloadTree{}
+	for _, path := range paths {
+		node := tree
+		for _, segment := range strings.Split(path, ".") {
+			if _, ok := node[segment]; !ok {
+				node[segment] = preloadTree{}
+			}
+			node = node[segment]
+		}
+	}
+	return tree
+}
+
+// entityAsInternalValue converts the entity to InternalValue, converting the preloaded relations and the fields
+// tagged as relations to InternalValues (or slices of them) too, so they can be handled by the nested
+// serializers. The relations that were not preloaded are converted the same way, so the serializers get the
+// same types whether the relation was loaded or not.
+func entityAsInternalValue(entity any, tree preloadTree) models.InternalValue {
+	intVal := models.AsInternalValue(entity)
+	names := relationNames(reflect.TypeOf(entity))
+	for name := range tree {
+		names = append(names, name)
+	}
+	for _, name := range names {
+		if value, ok := intVal[name]; ok {
+			intVal[name] = relationAsInternalValue(value, tree[name])
+		}
+	}
+	return intVal
+}
+
+// relationNames returns the JSON names of the fields tagged with `grf:"relation"`
+func relationNames(entityType reflect.Type) []string {
+	names := []string{}
+	for _, field := range reflect.VisibleFields(relatedType(entityType)) {
+		if _, isRelation := models.ParseTag(field)[models.TagIsRelation]; isRelation && !field.Anonymous {
+			names = append(names, strings.Split(field.Tag.Get("json"), ",")[0])
+		}
+	}
+	return names
+}
+
+func relationAsInternalValue(value any, tree preloadTree) any {
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Slice:
+		if v.IsNil() {
+			return []any(nil)
+		}
+		items := make([]any, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			items[i] = relationAsInternalValue(v.Index(i).Interface(), tree)
+		}
+		return items
+	case reflect.Pointer:
+		if v.IsNil() {
+			return nil
+		}
+		return relationAsInternalValue(v.Elem().Interface(), tree)
+	case reflect.Struct:
+		return entityAsInternalValue(value, tree)
+	}
+	return value
+}
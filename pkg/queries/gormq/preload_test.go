@@ -0,0 +1,101 @@
+package gormq
+
+import (
+	"testing"
+
+	"github.com/glothriel/grf/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+type Gallery struct {
+	ID      uint     `gorm:"primaryKey" json:"id"`
+	Name    string   `json:"name"`
+	CoverID *uint    `json:"cover_id"`
+	Cover   *Picture `json:"cover" gorm:"foreignKey:CoverID" grf:"relation"`
+	// Pictures are preloaded using the JSON name, which is different from the field name
+	Pictures []Picture `json:"photos" gorm:"foreignKey:GalleryID" grf:"relation"`
+}
+
+type Picture struct {
+	ID        uint   `gorm:"primaryKey" json:"id"`
+	GalleryID uint   `json:"gallery_id"`
+	URL       string `json:"url"`
+	Published bool   `json:"published"`
+	Tags      []Tag  `json:"tags" gorm:"foreignKey:PictureID" grf:"relation"`
+}
+
+type Tag struct {
+	ID        uint   `gorm:"primaryKey" json:"id"`
+	PictureID uint   `json:"picture_id"`
+	Name      string `json:"name"`
+}
+
+func prepareGalleries(t *testing.T) (*gorm.DB, *GormQueryDriver[Gallery]) {
+	db := prepareGorm(t)
+	assert.NoError(t, db.AutoMigrate(&Gallery{}, &Picture{}, &Tag{}))
+	assert.NoError(t, db.Create(&Gallery{Name: "Holidays"}).Error)
+	assert.NoError(t, db.Create(&[]Picture{
+		{GalleryID: 1, URL: "beach.jpg", Published: true, Tags: []Tag{{Name: "sea"}, {Name: "sun"}}},
+		{GalleryID: 1, URL: "draft.jpg"},
+	}).Error)
+	assert.NoError(t, db.Model(&Gallery{ID: 1}).Update("cover_id", 1).Error)
+	return db, Gorm[Gallery](Static(db))
+}
+
+func TestGormMultipleAndNestedPreloads(t *testing.T) {
+	// given
+	db, driver := prepareGalleries(t)
+	driver.WithPreload("cover").WithPreload("photos", "published = ?", true).WithPreload("photos.tags")
+	ctx, _ := prepareCtx[Gallery](t, db)
+	for _, middleware := range driver.Middleware() {
+		middleware(ctx)
+	}
+
+	// when
+	applyErr := driver.Filter().Apply(ctx)
+	list, listErr := driver.CRUD().List(ctx)
+	retrieved, retrieveErr := driver.CRUD().Retrieve(ctx, 1)
+
+	// then
+	coverID := uint(1)
+	beach := models.InternalValue{
+		"id": uint(1), "gallery_id": uint(1), "url": "beach.jpg", "published": true,
+		"tags": []any{
+			models.InternalValue{"id": uint(1), "picture_id": uint(1), "name": "sea"},
+			models.InternalValue{"id": uint(2), "picture_id": uint(1), "name": "sun"},
+		},
+	}
+	expected := models.InternalValue{
+		"id":       uint(1),
+		"name":     "Holidays",
+		"cover_id": &coverID,
+		"cover":    models.InternalValue{"id": uint(1), "gallery_id": uint(1), "url": "beach.jpg", "published": true, "tags": []any(nil)},
+		"photos":   []any{beach},
+	}
+	assert.NoError(t, applyErr)
+	assert.NoError(t, listErr)
+	assert.NoError(t, retrieveErr)
+	assert.Equal(t, []models.InternalValue{expected}, list)
+	assert.Equal(t, expected, retrieved)
+}
+
+func TestGormRetrieveWithoutPreloads(t *testing.T) {
+	// given
+	db, driver := prepareGalleries(t)
+	ctx, _ := prepareCtx[Gallery](t, db)
+
+	// when
+	retrieved, retrieveErr := driver.CRUD().Retrieve(ctx, 1)
+
+	// then
+	assert.NoError(t, retrieveErr)
+	assert.Nil(t, retrieved["cover"])
+	assert.Equal(t, []any(nil), retrieved["photos"])
+}
+
+func TestGormPreloadPanicsForUnknownPaths(t *testing.T) {
+	for _, path := range []string{"pictures", "photos.unknown", "name.first"} {
+		assert.Panics(t, func() { Gorm[Gallery](Static(prepareGorm(t))).WithPreload(path) }, path)
+	}
+}
@@ -0,0 +1,96 @@
+package gormq
+
+import (
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+	"github.com/glothriel/grf/pkg/authentication"
+	"github.com/glothriel/grf/pkg/models"
+	"github.com/glothriel/grf/pkg/queries/crud"
+	"github.com/glothriel/grf/pkg/queries/filters"
+	"github.com/glothriel/grf/pkg/serializers"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// GormScopeFunc narrows the query to the rows the user is allowed to access
+type GormScopeFunc func(ctx *gin.Context, user authentication.User, db *gorm.DB) *gorm.DB
+
+// WithScope narrows the List, Retrieve, Update and Destroy queries to the rows of the current user. Rows
+// outside of the scope result in 404 Not Found. It can be called multiple times, all the scopes are applied.
+func (g *GormQueryDriver[Model]) WithScope(scopeFunc GormScopeFunc) *GormQueryDriver[Model] {
+	previous := g.scope.modFunc
+	g.scope.modFunc = func(ctx *gin.Context, db *gorm.DB) *gorm.DB {
+		db = previous(ctx, db)
+		user, userErr := authentication.CurrentUser(ctx)
+		if userErr != nil {
+			_ = db.AddError(userErr)
+			return db
+		}
+		return scopeFunc(ctx, user, db)
+	}
+	return g
+}
+
+// WithOwnerField scopes the queries to the rows, where the field (JSON name) is equal to the ID of the
+// authenticated user, and sets the field to the ID of the user on Create and Update. Anonymous users
+// don't see any rows. The ID is converted to the type of the field, so a string ID, like the `sub` claim
+// of a JWT, can be used with an integer column.
+func (g *GormQueryDriver[Model]) WithOwnerField(field string) *GormQueryDriver[Model] {
+	goField, ok := g.fieldNames[field]
+	if !ok {
+		logrus.Panicf("Owner field `%s` does not exist", field)
+	}
+	assignOwner(g.q, field)
+	return g.WithScope(func(ctx *gin.Context, user authentication.User, db *gorm.DB) *gorm.DB {
+		if !user.IsAuthenticated() {
+			return db.Where("1 = 0")
+		}
+		ownerID, convertErr := filters.ConvertToField[Model](serializers.CtxFieldTypeMapper(ctx), field, user.ID())
+		if convertErr != nil {
+			_ = db.AddError(convertErr)
+			return db
+		}
+		return ApplyConditions[Model](db, []filters.Condition{
+			{Field: field, GoField: goField, Lookup: filters.Exact, Value: ownerID},
+		})
+	})
+}
+
+// assignOwner wraps Create and Update, so they set the field to the ID of the authenticated user, and the
+// clients can't assign the rows to other users
+func assignOwner[Model any](q *crud.CRUD[Model], field string) {
+	q.WrapCreate(func(create crud.CreateQueryFunc) crud.CreateQueryFunc {
+		return func(ctx *gin.Context, new models.InternalValue) (models.InternalValue, error) {
+			if ownerErr := setOwner[Model](ctx, field, new); ownerErr != nil {
+				return nil, ownerErr
+			}
+			return create(ctx, new)
+		}
+	}).WrapUpdate(func(update crud.UpdateQueryFunc) crud.UpdateQueryFunc {
+		return func(ctx *gin.Context, old models.InternalValue, new models.InternalValue, id any) (
+			models.InternalValue, error,
+		) {
+			if ownerErr := setOwner[Model](ctx, field, new); ownerErr != nil {
+				return nil, ownerErr
+			}
+			return update(ctx, old, new, id)
+		}
+	})
+}
+
+func setOwner[Model any](ctx *gin.Context, field string, intVal models.InternalValue) error {
+	user, userErr := authentication.CurrentUser(ctx)
+	if userErr != nil {
+		return userErr
+	}
+	if !user.IsAuthenticated() {
+		return authentication.ErrorNotAuthenticated
+	}
+	ownerID, convertErr := filters.ConvertToField[Model](serializers.CtxFieldTypeMapper(ctx), field, user.ID())
+	if convertErr != nil {
+		return fmt.Errorf("Could not use the ID of the user as the owner: %w", convertErr)
+	}
+	intVal[field] = ownerID
+	return nil
+}
@@ -0,0 +1,162 @@
+package gormq
+
+import (
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/glothriel/grf/pkg/authentication"
+	"github.com/glothriel/grf/pkg/models"
+	"github.com/glothriel/grf/pkg/queries/common"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+type OwnedModel struct {
+	ID      uint   `gorm:"primaryKey" json:"id"`
+	Name    string `json:"name"`
+	OwnerID string `json:"owner_id"`
+}
+
+func prepareOwnedModels(t *testing.T, user authentication.User) (*gin.Context, *GormQueryDriver[OwnedModel]) {
+	ctx, driver := prepareCtx[OwnedModel](t)
+	assert.NoError(t, CtxQuery(ctx).Create(&[]OwnedModel{
+		{Name: "first", OwnerID: "alice"},
+		{Name: "second", OwnerID: "bob"},
+		{Name: "third", OwnerID: "alice"},
+	}).Error)
+	authentication.CtxSetUser(ctx, user)
+	driver.WithOwnerField("owner_id")
+	assert.NoError(t, driver.Filter().Apply(ctx))
+	return ctx, driver
+}
+
+func TestGormOwnerFieldScopesQueries(t *testing.T) {
+	// given
+	ctx, driver := prepareOwnedModels(t, &authentication.SimpleUser{Identifier: "alice"})
+
+	// when
+	list, listErr := driver.CRUD().List(ctx)
+	own, ownErr := driver.CRUD().Retrieve(ctx, 1)
+	_, otherErr := driver.CRUD().Retrieve(ctx, 2)
+	_, updateErr := driver.CRUD().Update(
+		ctx, models.InternalValue{}, models.InternalValue{"id": uint(2), "name": "changed"}, 2,
+	)
+	destroyErr := driver.CRUD().Destroy(ctx, 2)
+
+	// then
+	assert.NoError(t, listErr)
+	assert.Equal(t, []models.InternalValue{
+		{"id": uint(1), "name": "first", "owner_id": "alice"},
+		{"id": uint(3), "name": "third", "owner_id": "alice"},
+	}, list)
+	assert.NoError(t, ownErr)
+	assert.Equal(t, "first", own["name"])
+	assert.Equal(t, common.ErrorNotFound, otherErr)
+	assert.NoError(t, updateErr)
+	assert.Equal(t, common.ErrorNotFound, destroyErr)
+	var other OwnedModel
+	assert.NoError(t, New(ctx).First(&other, 2).Error)
+	assert.Equal(t, OwnedModel{ID: 2, Name: "second", OwnerID: "bob"}, other)
+}
+
+func TestGormOwnerFieldIsSetOnCreate(t *testing.T) {
+	// given
+	ctx, driver := prepareOwnedModels(t, &authentication.SimpleUser{Identifier: "alice"})
+
+	// when
+	created, createErr := driver.CRUD().Create(ctx, models.InternalValue{"name": "fourth", "owner_id": "bob"})
+
+	// then
+	assert.NoError(t, createErr)
+	assert.Equal(t, "alice", created["owner_id"])
+}
+
+type NumericallyOwnedModel struct {
+	ID      uint   `gorm:"primaryKey" json:"id"`
+	Name    string `json:"name"`
+	OwnerID uint   `json:"owner_id"`
+}
+
+func TestGormOwnerFieldConvertsStringIDs(t *testing.T) {
+	// given
+	ctx, driver := prepareCtx[NumericallyOwnedModel](t)
+	assert.NoError(t, CtxQuery(ctx).Create(&[]NumericallyOwnedModel{
+		{Name: "first", OwnerID: 7},
+		{Name: "second", OwnerID: 8},
+	}).Error)
+	// Users created from JWTs have the `sub` claim as their ID
+	authentication.CtxSetUser(ctx, &authentication.SimpleUser{Identifier: "7"})
+	driver.WithOwnerField("owner_id")
+	assert.NoError(t, driver.Filter().Apply(ctx))
+
+	// when
+	list, listErr := driver.CRUD().List(ctx)
+	created, createErr := driver.CRUD().Create(ctx, models.InternalValue{"name": "third", "owner_id": uint(8)})
+
+	// then
+	assert.NoError(t, listErr)
+	assert.Equal(t, []models.InternalValue{{"id": uint(1), "name": "first", "owner_id": uint(7)}}, list)
+	assert.NoError(t, createErr)
+	assert.Equal(t, uint(7), created["owner_id"])
+	var stored NumericallyOwnedModel
+	assert.NoError(t, New(ctx).First(&stored, 3).Error)
+	assert.Equal(t, uint(7), stored.OwnerID)
+}
+
+func TestGormOwnerFieldRejectsIDsOfOtherTypes(t *testing.T) {
+	// given
+	ctx, driver := prepareCtx[NumericallyOwnedModel](t)
+	authentication.CtxSetUser(ctx, &authentication.SimpleUser{Identifier: "alice"})
+	driver.WithOwnerField("owner_id")
+
+	// when
+	applyErr := driver.Filter().Apply(ctx)
+	_, createErr := driver.CRUD().Create(ctx, models.InternalValue{"name": "first"})
+
+	// then
+	assert.ErrorContains(t, applyErr, "`alice` is not a valid value")
+	assert.ErrorContains(t, createErr, "`alice` is not a valid value")
+}
+
+func TestGormOwnerFieldHidesEverythingFromAnonymousUsers(t *testing.T) {
+	// given
+	ctx, driver := prepareOwnedModels(t, authentication.AnonymousUser{})
+
+	// when
+	list, listErr := driver.CRUD().List(ctx)
+	_, createErr := driver.CRUD().Create(ctx, models.InternalValue{"name": "fourth"})
+
+	// then
+	assert.NoError(t, listErr)
+	assert.Empty(t, list)
+	assert.ErrorIs(t, createErr, authentication.ErrorNotAuthenticated)
+}
+
+func TestGormScopesAreCombined(t *testing.T) {
+	// given
+	ctx, driver := prepareCtx[OwnedModel](t)
+	assert.NoError(t, CtxQuery(ctx).Create(&[]OwnedModel{
+		{Name: "first", OwnerID: "alice"},
+		{Name: "second", OwnerID: "alice"},
+	}).Error)
+	authentication.CtxSetUser(ctx, &authentication.SimpleUser{Identifier: "alice"})
+	driver.WithOwnerField("owner_id").WithScope(
+		func(_ *gin.Context, _ authentication.User, db *gorm.DB) *gorm.DB {
+			return db.Where("name = ?", "second")
+		},
+	)
+
+	// when
+	applyErr := driver.Filter().Apply(ctx)
+	list, listErr := driver.CRUD().List(ctx)
+
+	// then
+	assert.NoError(t, applyErr)
+	assert.NoError(t, listErr)
+	assert.Equal(t, []models.InternalValue{{"id": uint(2), "name": "second", "owner_id": "alice"}}, list)
+}
+
+func TestGormOwnerFieldPanicsForUnknownField(t *testing.T) {
+	_, driver := prepareCtx[OwnedModel](t)
+	assert.Panics(t, func() { driver.WithOwnerField("owner") })
+}
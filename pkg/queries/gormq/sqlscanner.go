@@ -27,12 +27,15 @@ func SQLScannerOrPassthrough[Model any]() func(map[string]any, string, *gin.Cont
 	}
 
 	return func(reprModel map[string]any, name string, ctx *gin.Context) (any, error) {
-		_, hasBlueprint := fieldBlueprints[name]
+		blueprint, hasBlueprint := fieldBlueprints[name]
 		if !hasBlueprint {
 			logrus.Debugf("Field `%s` has no blueprint, returning value as is", name)
 			return reprModel[name], nil
 		}
-		reflectedInstance := reflect.New(fieldBlueprints[name]).Interface()
+		if blueprint.Kind() == reflect.Pointer {
+			return scanPointer(reprModel[name], name, blueprint)
+		}
+		reflectedInstance := reflect.New(blueprint).Interface()
 
 		scanner, ok := reflectedInstance.(sql.Scanner)
 		if !ok {
@@ -48,3 +51,44 @@ func SQLScannerOrPassthrough[Model any]() func(map[string]any, string, *gin.Cont
 		return reflect.ValueOf(scanner).Elem().Interface(), nil
 	}
 }
+
+// scanPointer converts a value read from the DB to a pointer field. NULL becomes a nil pointer, other values
+// are scanned if the underlying type is a sql.Scanner, or converted to the underlying type otherwise.
+func scanPointer(dbValue any, name string, blueprint reflect.Type) (any, error) {
+	if dbValue == nil {
+		return reflect.Zero(blueprint).Interface(), nil
+	}
+	ptr := reflect.New(blueprint.Elem())
+	if scanner, ok := ptr.Interface().(sql.Scanner); ok {
+		if scanErr := scanner.Scan(dbValue); scanErr != nil {
+			return nil, fmt.Errorf("could not convert field from db `%s`: %s", name, scanErr)
+		}
+		return ptr.Interface(), nil
+	}
+	value := reflect.ValueOf(dbValue)
+	if value.Type() == blueprint {
+		return dbValue, nil
+	}
+	if !isSafeDBConversion(value.Type(), blueprint.Elem()) {
+		logrus.Debugf("Field `%s` can't be converted to %s, returning value as is", name, blueprint)
+		return dbValue, nil
+	}
+	ptr.Elem().Set(value.Convert(blueprint.Elem()))
+	return ptr.Interface(), nil
+}
+
+// isSafeDBConversion tells if the value read from the DB can be converted to the type without changing its
+// meaning, reflect would for example happily convert an int64 to a string containing a single rune
+func isSafeDBConversion(from, to reflect.Type) bool {
+	if from.AssignableTo(to) {
+		return true
+	}
+	numeric := func(k reflect.Kind) bool {
+		return (k >= reflect.Int && k <= reflect.Uint64) || k == reflect.Float32 || k == reflect.Float64
+	}
+	if numeric(from.Kind()) && numeric(to.Kind()) {
+		return true
+	}
+	isBytes := from.Kind() == reflect.Slice && from.Elem().Kind() == reflect.Uint8
+	return (isBytes && to.Kind() == reflect.String) || (from.Kind() == reflect.String && to.Kind() == reflect.String)
+}
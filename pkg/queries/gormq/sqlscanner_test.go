@@ -66,3 +66,40 @@ func TestSQLScannerOrPassthroughWhenIsNotSqlScanner(t *testing.T) {
 	assert.Equal(t, "foo", value)
 	assert.Nil(t, valueErr)
 }
+
+type mockPointerModel struct {
+	Name    *string              `json:"name"`
+	Count   *int                 `json:"count"`
+	Scanned *mockSQLScannerField `json:"scanned"`
+}
+
+func TestSQLScannerOrPassthroughWhenIsPointer(t *testing.T) {
+	name := "foo"
+	count := 42
+	tests := []struct {
+		name     string
+		field    string
+		dbValue  any
+		expected any
+	}{
+		{name: "NULL string", field: "name", dbValue: nil, expected: (*string)(nil)},
+		{name: "string", field: "name", dbValue: "foo", expected: &name},
+		{name: "bytes to string", field: "name", dbValue: []byte("foo"), expected: &name},
+		{name: "NULL int", field: "count", dbValue: nil, expected: (*int)(nil)},
+		{name: "int64 to int", field: "count", dbValue: int64(42), expected: &count},
+		{name: "scanner", field: "scanned", dbValue: []byte(`{"foo": "bar"}`), expected: &mockSQLScannerField{"foo": "bar"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// given
+			fromDBFunc := SQLScannerOrPassthrough[mockPointerModel]()
+
+			// when
+			value, valueErr := fromDBFunc(map[string]any{tt.field: tt.dbValue}, tt.field, nil)
+
+			// then
+			assert.Nil(t, valueErr)
+			assert.Equal(t, tt.expected, value)
+		})
+	}
+}
@@ -0,0 +1,42 @@
+package gormq
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/gin-gonic/gin"
+	"github.com/glothriel/grf/pkg/queries/filters"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Exists implements serializers.UniquenessChecker. The fields are identified by their JSON names. The scope
+// and the filters of the driver are not applied, as the database constraints don't take them into account
+// either.
+func (g *GormQueryDriver[Model]) Exists(ctx *gin.Context, values map[string]any, excludeID any) (bool, error) {
+	fieldNames := make([]string, 0, len(values))
+	for field := range values {
+		fieldNames = append(fieldNames, field)
+	}
+	sort.Strings(fieldNames)
+	conditions := make([]filters.Condition, 0, len(values))
+	for _, field := range fieldNames {
+		goField, ok := g.fieldNames[field]
+		if !ok {
+			return false, fmt.Errorf("Field `%s` does not exist", field)
+		}
+		conditions = append(conditions, filters.Condition{
+			Field: field, GoField: goField, Lookup: filters.Exact, Value: values[field],
+		})
+	}
+	var m Model
+	db := ApplyConditions[Model](CtxQuery(ctx).Session(&gorm.Session{NewDB: true}).Model(&m), conditions)
+	if excludeID != nil {
+		db = db.Where(clause.Neq{Column: clause.Column{Table: clause.CurrentTable, Name: "id"}, Value: excludeID})
+	}
+	var count int64
+	if countErr := db.Count(&count).Error; countErr != nil {
+		return false, countErr
+	}
+	return count > 0, nil
+}
@@ -29,6 +29,9 @@ func (s *SerializerField[Model]) ToRepresentation(iv models.InternalValue, c *gi
 		}
 		return result, nil
 	}
+	if related, isInternalValue := fieldValue.(models.InternalValue); isInternalValue {
+		return s.serializer.ToRepresentation(related, c)
+	}
 	return s.serializer.ToRepresentation(iv, c)
 }
 
@@ -3,56 +3,129 @@ package serializers
 import (
 	"fmt"
 	"reflect"
+	"sort"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 	"github.com/glothriel/grf/pkg/detectors"
 	"github.com/glothriel/grf/pkg/fields"
 	"github.com/glothriel/grf/pkg/models"
+	"github.com/glothriel/grf/pkg/types"
 	"github.com/sirupsen/logrus"
 )
 
 type ModelSerializer[Model any] struct {
 	Fields map[string]fields.Field
 
+	// lenient serializers silently drop the unknown and read-only fields sent by the client
+	lenient bool
+
 	toRepresentationDetector detectors.ToRepresentationDetector[Model]
 	toInternalValueDetector  detectors.ToInternalValueDetector
 }
 
+// ToInternalValue converts the payload using all the writable fields. The errors of all the fields are
+// collected and returned together as a single ValidationError, along with the fields that were converted,
+// so the validators can still check them.
 func (s *ModelSerializer[Model]) ToInternalValue(raw map[string]any, ctx *gin.Context) (models.InternalValue, error) {
+	validationErr := NewValidationError()
+	if !s.lenient {
+		s.rejectNotWritableFields(raw, ctx, validationErr)
+	}
 	intVMap := make(map[string]any)
-	superfluousFields := make([]string, 0)
 
-	for k := range s.Fields {
-		field, ok := s.Fields[k]
-		if !ok {
-			superfluousFields = append(superfluousFields, k)
-			continue
-		}
+	for k, field := range s.Fields {
 		if !field.IsWritable() {
 			continue
 		}
+		if rawV, ok := raw[k]; ok {
+			if rawV == nil && !fields.AllowsNull(field) {
+				validationErr.Add(k, fmt.Sprintf("Field `%s` may not be null", k))
+				continue
+			}
+			if rawV == "" && !fields.AllowsBlank(field) {
+				validationErr.Add(k, fmt.Sprintf("Field `%s` may not be blank", k))
+				continue
+			}
+		}
 		// Please remember, that `ToInteralValue` doesn't necessarily extract the value from the `raw` map.
 		// In theory it could use request headers, cookies, external APIs, queries or anything else.
 		intV, err := field.ToInternalValue(raw, ctx)
 		if err != nil {
 			_, isMissingFieldErr := err.(fields.ErrorFieldIsNotPresentInPayload)
 			if isMissingFieldErr {
+				s.handleMissingField(field, ctx, intVMap, validationErr)
 				continue
 			}
-			return nil, &ValidationError{FieldErrors: map[string][]string{k: {err.Error()}}}
+			// Fields holding nested values can report errors with their own paths, eg. `photos.2.url`
+			if validationErr.Merge(k, err) != nil {
+				validationErr.Add(k, err.Error())
+			}
+			continue
 		}
 		intVMap[k] = intV
 	}
-	if len(superfluousFields) > 0 {
-		errMap := map[string][]string{}
-		for _, field := range superfluousFields {
-			errMap[field] = []string{fmt.Sprintf("Field `%s` is not accepted by this endpoint", field)}
-		}
-		return nil, &ValidationError{FieldErrors: errMap}
+	if err := validationErr.OrNil(); err != nil {
+		return intVMap, err
 	}
 	return intVMap, nil
 }
 
+// handleMissingField uses the default of the field missing from the payload, or reports it if it's required.
+// Partial updates change only the supplied fields, so neither defaults nor required fields apply to them.
+func (s *ModelSerializer[Model]) handleMissingField(
+	field fields.Field, ctx *gin.Context, intVMap map[string]any, validationErr *ValidationError,
+) {
+	op := CtxOperation(ctx)
+	if op != OperationCreate && op != OperationUpdate {
+		return
+	}
+	if fields.HasDefault(field) {
+		defaultV, defaultErr := fields.Default(field, ctx)
+		if defaultErr != nil {
+			validationErr.Add(field.Name(), defaultErr.Error())
+			return
+		}
+		intVMap[field.Name()] = defaultV
+		return
+	}
+	if fields.IsRequired(field) {
+		validationErr.Add(field.Name(), fmt.Sprintf("Field `%s` is required", field.Name()))
+		return
+	}
+	// PUT is a full replacement, so the missing fields that accept null are cleared, and the other writable
+	// fields without a default have to be supplied
+	if op == OperationUpdate && fields.AllowsNull(field) {
+		intVMap[field.Name()] = nil
+		return
+	}
+	if op == OperationUpdate {
+		validationErr.Add(field.Name(), fmt.Sprintf("Field `%s` is required", field.Name()))
+	}
+}
+
+// rejectNotWritableFields adds an error for every key of the payload, that is either unknown to the serializer
+// or read-only
+func (s *ModelSerializer[Model]) rejectNotWritableFields(raw map[string]any, ctx *gin.Context, validationErr *ValidationError) {
+	op := CtxOperation(ctx)
+	for k := range raw {
+		field, ok := s.Fields[k]
+		if !ok {
+			validationErr.Add(k, fmt.Sprintf("Field `%s` is not accepted by this endpoint", k))
+			continue
+		}
+		if field.IsWritable() {
+			continue
+		}
+		// The update views put the id from the URL into the payload, after checking that it matches the id sent
+		// by the client
+		if k == "id" && (op == OperationUpdate || op == OperationPartialUpdate) {
+			continue
+		}
+		validationErr.Add(k, fmt.Sprintf("Field `%s` is read-only", k))
+	}
+}
+
 func (s *ModelSerializer[Model]) ToRepresentation(intVal models.InternalValue, ctx *gin.Context) (Representation, error) {
 	raw := make(map[string]any)
 	for _, field := range s.Fields {
@@ -83,6 +156,30 @@ func (s *ModelSerializer[Model]) Validate(intVal models.InternalValue, ctx *gin.
 	return nil
 }
 
+// ListFields returns the fields of the serializer sorted by name
+func (s *ModelSerializer[Model]) ListFields() []fields.Field {
+	ret := make([]fields.Field, 0, len(s.Fields))
+	for _, field := range s.Fields {
+		ret = append(ret, field)
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].Name() < ret[j].Name()
+	})
+	return ret
+}
+
+// WithStrict makes the serializer reject payloads containing unknown or read-only fields, it's the default
+func (s *ModelSerializer[Model]) WithStrict() *ModelSerializer[Model] {
+	s.lenient = false
+	return s
+}
+
+// WithLenient makes the serializer silently ignore unknown and read-only fields in the payload
+func (s *ModelSerializer[Model]) WithLenient() *ModelSerializer[Model] {
+	s.lenient = true
+	return s
+}
+
 func (s *ModelSerializer[Model]) WithNewField(field fields.Field) *ModelSerializer[Model] {
 	s.Fields[field.Name()] = field
 	return s
@@ -120,27 +217,133 @@ func (s *ModelSerializer[Model]) WithModelFields(passedFields []string) *ModelSe
 			}
 			logrus.Panicf("WithModelFields: Failed to register model `%s` fields: %s", reflect.TypeOf(m), toInternalValueErr)
 		}
-		s.Fields[field] = fields.NewField[Model](
+		s.Fields[field] = applyModelFieldOptions[Model](fields.NewField[Model](
 			field,
 		).WithRepresentationFunc(
 			toRepresentation,
 		).WithInternalValueFunc(
 			toInternalValue,
-		)
+		))
 	}
 	return s
 }
 
-func NewModelSerializer[Model any]() *ModelSerializer[Model] {
+// applyModelFieldOptions infers the options of the field from the model struct: `null` is accepted only if
+// the Go type can represent it, and the `grf` tag can mark the field as required or override the defaults,
+// eg. `grf:"required;allow_blank:false"`
+func applyModelFieldOptions[Model any](field fields.Field) fields.Field {
+	options, ok := field.(fields.OptionsField)
+	if !ok {
+		return field
+	}
+	var m Model
+	for _, structField := range reflect.VisibleFields(reflect.TypeOf(m)) {
+		if structField.Anonymous || structField.Tag.Get("json") != field.Name() {
+			continue
+		}
+		options.WithAllowNull(isNullableType(structField.Type))
+		tagSettings := models.ParseTag(structField)
+		if _, ok := tagSettings[models.TagRequired]; ok {
+			options.WithRequired(parseBoolTagSetting[Model](field.Name(), models.TagRequired, tagSettings[models.TagRequired]))
+		}
+		if value, ok := tagSettings[models.TagAllowNull]; ok {
+			options.WithAllowNull(parseBoolTagSetting[Model](field.Name(), models.TagAllowNull, value))
+		}
+		if value, ok := tagSettings[models.TagAllowBlank]; ok {
+			options.WithAllowBlank(parseBoolTagSetting[Model](field.Name(), models.TagAllowBlank, value))
+		}
+	}
+	return field
+}
+
+// isNullableType tells if the type can hold `null`, which is true for pointers, slices, maps, interfaces and
+// the types like sql.NullString, that have a `Valid` flag
+func isNullableType(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Pointer, reflect.Slice, reflect.Map, reflect.Interface:
+		return true
+	case reflect.Struct:
+		valid, ok := t.FieldByName("Valid")
+		return ok && valid.Type.Kind() == reflect.Bool
+	}
+	return false
+}
+
+// parseBoolTagSetting parses the value of a tag setting, a setting without a value (`grf:"required"`) is true
+func parseBoolTagSetting[Model any](fieldName, setting, value string) bool {
+	if value == "" {
+		return true
+	}
+	parsed, parseErr := strconv.ParseBool(value)
+	if parseErr != nil {
+		var m Model
+		logrus.Panicf("Invalid value `%s` of `%s` in the grf tag of field `%s` on model `%s`", value, setting, fieldName, reflect.TypeOf(m))
+	}
+	return parsed
+}
+
+// ModelSerializerOption configures the serializer created by NewModelSerializer or NewModelSerializerWithFields
+type ModelSerializerOption func(*modelSerializerOptions)
+
+type modelSerializerOptions struct {
+	fieldTypeMapper *types.FieldTypeMapper
+}
+
+// UsingFieldTypeMapper makes the model fields convert their values using the mapper instead of the default
+// one, see types.NewFieldTypeMapper
+func UsingFieldTypeMapper(mapper *types.FieldTypeMapper) ModelSerializerOption {
+	return func(o *modelSerializerOptions) {
+		o.fieldTypeMapper = mapper
+	}
+}
+
+const ctxFieldTypeMapperKey = "serializers:field_type_mapper"
+
+// CtxSetFieldTypeMapper stores the mapper used by the view in the request context, so the values converted
+// outside of the serializers, like the query params of the filters, use the same types as the payloads
+func CtxSetFieldTypeMapper(ctx *gin.Context, mapper *types.FieldTypeMapper) {
+	ctx.Set(ctxFieldTypeMapperKey, mapper)
+}
+
+// CtxFieldTypeMapper returns the mapper stored in the request context, or the default one if none was set
+func CtxFieldTypeMapper(ctx *gin.Context) *types.FieldTypeMapper {
+	if ctx == nil {
+		return types.Mapper()
+	}
+	anyVal, ok := ctx.Get(ctxFieldTypeMapperKey)
+	if !ok {
+		return types.Mapper()
+	}
+	mapper, ok := anyVal.(*types.FieldTypeMapper)
+	if !ok {
+		return types.Mapper()
+	}
+	return mapper
+}
+
+func NewModelSerializer[Model any](options ...ModelSerializerOption) *ModelSerializer[Model] {
 	// Includes all the fields
-	return NewModelSerializerWithFields[Model](detectors.Fields[Model]())
+	return NewModelSerializerWithFields[Model](detectors.Fields[Model](), options...)
 }
 
-func NewModelSerializerWithFields[Model any](fieldList []string) *ModelSerializer[Model] {
-	return (&ModelSerializer[Model]{
-		toRepresentationDetector: detectors.DefaultToRepresentationDetector[Model](),
-		toInternalValueDetector:  detectors.DefaultToInternalValueDetector[Model](),
+// NewModelSerializerWithFields creates a serializer with the given model fields. `id`, as well as `created_at`
+// and `updated_at` managed by the ORM, are read-only.
+func NewModelSerializerWithFields[Model any](fieldList []string, options ...ModelSerializerOption) *ModelSerializer[Model] {
+	settings := modelSerializerOptions{fieldTypeMapper: types.Mapper()}
+	for _, option := range options {
+		option(&settings)
+	}
+	s := (&ModelSerializer[Model]{
+		toRepresentationDetector: detectors.NewToRepresentationDetector[Model](settings.fieldTypeMapper),
+		toInternalValueDetector:  detectors.NewToInternalValueDetector[Model](settings.fieldTypeMapper),
 	}).WithModelFields(
 		fieldList,
 	).WithField("id", func(oldField fields.Field) { oldField.WithReadOnly() })
+	// Timestamps are managed by the ORM, the clients should not be required to send them
+	for _, name := range []string{"created_at", "updated_at"} {
+		if field, ok := s.Fields[name]; ok {
+			field.WithReadOnly()
+		}
+	}
+	return s
 }
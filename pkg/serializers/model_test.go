@@ -1,13 +1,20 @@
 package serializers
 
 import (
+	"database/sql"
 	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/glothriel/grf/pkg/detectors"
 	"github.com/glothriel/grf/pkg/fields"
 	"github.com/glothriel/grf/pkg/models"
+	"github.com/glothriel/grf/pkg/types"
+	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -36,7 +43,7 @@ type anotherMockModel struct {
 
 func TestModelSerializerToInternalValueNonWritableField(t *testing.T) {
 	// given
-	serializer := NewModelSerializer[anotherMockModel]().WithField(
+	serializer := NewModelSerializer[anotherMockModel]().WithLenient().WithField(
 		"bar",
 		func(oldField fields.Field) {
 			oldField.WithReadOnly()
@@ -51,6 +58,47 @@ func TestModelSerializerToInternalValueNonWritableField(t *testing.T) {
 	assert.Equal(t, models.InternalValue{"foo": "bar"}, intVal)
 }
 
+func TestModelSerializerToInternalValueRejectsNotWritableFields(t *testing.T) {
+	// given
+	serializer := NewModelSerializer[anotherMockModel]().WithField(
+		"bar",
+		func(oldField fields.Field) {
+			oldField.WithReadOnly()
+		},
+	)
+
+	// when
+	_, err := serializer.ToInternalValue(map[string]any{"foo": "bar", "bar": "baz", "baz": 1, "qux": 2}, nil)
+
+	// then
+	assert.Equal(t, &ValidationError{FieldErrors: map[string][]string{
+		"bar": {"Field `bar` is read-only"},
+		"baz": {"Field `baz` is not accepted by this endpoint"},
+		"qux": {"Field `qux` is not accepted by this endpoint"},
+	}}, err)
+}
+
+func TestModelSerializerToInternalValueAcceptsIDOnUpdate(t *testing.T) {
+	for _, tt := range []struct {
+		op      Operation
+		wantErr bool
+	}{
+		{OperationCreate, true},
+		{OperationUpdate, false},
+		{OperationPartialUpdate, false},
+	} {
+		// given
+		ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+		CtxSetOperation(ctx, tt.op)
+
+		// when
+		_, err := NewModelSerializer[mockModel]().ToInternalValue(map[string]any{"id": "1", "foo": "bar"}, ctx)
+
+		// then
+		assert.Equal(t, tt.wantErr, err != nil)
+	}
+}
+
 func TestModelSerializerToInternalValueFieldErr(t *testing.T) {
 	// given
 	serializer := NewModelSerializer[anotherMockModel]().WithNewField(
@@ -68,6 +116,146 @@ func TestModelSerializerToInternalValueFieldErr(t *testing.T) {
 	assert.ErrorContains(t, err, "foo err")
 }
 
+func TestModelSerializerToInternalValueCollectsAllErrors(t *testing.T) {
+	// given
+	ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+	CtxSetOperation(ctx, OperationUpdate)
+	failingField := func(name string, err error) fields.Field {
+		return fields.NewField[anotherMockModel](name).WithInternalValueFunc(
+			func(map[string]any, string, *gin.Context) (any, error) {
+				return nil, err
+			},
+		)
+	}
+	serializer := NewModelSerializer[anotherMockModel]().WithNewField(
+		failingField("foo", errors.New("foo err")),
+	).WithNewField(
+		failingField("photos", NewValidationError().Add("2.url", "invalid url")),
+	)
+
+	// when
+	_, err := serializer.ToInternalValue(map[string]any{"foo": "bar", "photos": []any{}, "baz": 1}, ctx)
+
+	// then
+	assert.Equal(t, &ValidationError{FieldErrors: map[string][]string{
+		"foo":          {"foo err"},
+		"photos.2.url": {"invalid url"},
+		"bar":          {"Field `bar` is required"},
+		"baz":          {"Field `baz` is not accepted by this endpoint"},
+	}}, err)
+}
+
+type mockScoredModel struct {
+	ID     uint                   `json:"id"`
+	Scores models.SliceField[int] `json:"scores"`
+}
+
+func TestModelSerializerReportsSliceElementErrors(t *testing.T) {
+	// given
+	ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+	CtxSetOperation(ctx, OperationCreate)
+	serializer := NewModelSerializer[mockScoredModel]()
+
+	// when
+	intVal, validErr := serializer.ToInternalValue(map[string]any{"scores": []any{1.0, 2.0}}, ctx)
+	_, invalidErr := serializer.ToInternalValue(map[string]any{"scores": []any{1.0, 2.0, 3.5}}, ctx)
+
+	// then
+	assert.NoError(t, validErr)
+	assert.Equal(t, &models.SliceField[int]{1, 2}, intVal["scores"])
+	assert.Equal(t, &ValidationError{FieldErrors: map[string][]string{
+		"scores.2": {"Error converting request value to internal value for type `int`: Value 3.500000 is not an integer"},
+	}}, invalidErr)
+}
+
+type mockTicket struct {
+	ID     uint           `json:"id"`
+	Title  string         `json:"title" grf:"required;allow_blank:false"`
+	Note   sql.NullString `json:"note"`
+	Status string         `json:"status"`
+	Owner  string         `json:"owner"`
+}
+
+func TestModelSerializerFieldOptions(t *testing.T) {
+	serializer := NewModelSerializer[mockTicket]().WithField("status", func(oldField fields.Field) {
+		oldField.(fields.OptionsField).WithDefault("open")
+	}).WithField("owner", func(oldField fields.Field) {
+		oldField.(fields.OptionsField).WithDefaultFunc(func(ctx *gin.Context) (any, error) {
+			return ctx.GetString("user"), nil
+		})
+	})
+	tests := []struct {
+		name       string
+		op         Operation
+		payload    map[string]any
+		wantIntVal models.InternalValue
+		wantErrors map[string][]string
+	}{
+		{
+			name:       "create applies defaults",
+			op:         OperationCreate,
+			payload:    map[string]any{"title": "Broken"},
+			wantIntVal: models.InternalValue{"title": "Broken", "status": "open", "owner": "alice"},
+		},
+		{
+			name:    "create requires fields and rejects null and blank values",
+			op:      OperationCreate,
+			payload: map[string]any{"status": nil, "owner": "", "note": nil},
+			wantErrors: map[string][]string{
+				"title":  {"Field `title` is required"},
+				"status": {"Field `status` may not be null"},
+			},
+		},
+		{
+			name:       "blank values are rejected only if disallowed",
+			op:         OperationCreate,
+			payload:    map[string]any{"title": "", "owner": ""},
+			wantErrors: map[string][]string{"title": {"Field `title` may not be blank"}},
+		},
+		{
+			name:    "full update applies defaults",
+			op:      OperationUpdate,
+			payload: map[string]any{"title": "Broken", "note": "n/a"},
+			wantIntVal: models.InternalValue{
+				"title": "Broken", "note": sql.NullString{String: "n/a", Valid: true}, "status": "open", "owner": "alice",
+			},
+		},
+		{
+			name:       "partial update ignores defaults and required fields",
+			op:         OperationPartialUpdate,
+			payload:    map[string]any{"note": nil},
+			wantIntVal: models.InternalValue{"note": sql.NullString{}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// given
+			ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+			ctx.Set("user", "alice")
+			CtxSetOperation(ctx, tt.op)
+
+			// when
+			intVal, err := serializer.ToInternalValue(tt.payload, ctx)
+
+			// then
+			if tt.wantErrors != nil {
+				assert.Equal(t, &ValidationError{FieldErrors: tt.wantErrors}, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantIntVal, intVal)
+		})
+	}
+}
+
+func TestModelSerializerInvalidTagPanics(t *testing.T) {
+	type invalidTag struct {
+		ID   uint   `json:"id"`
+		Name string `json:"name" grf:"required:maybe"`
+	}
+	assert.Panics(t, func() { NewModelSerializer[invalidTag]() })
+}
+
 func TestModelSerializerToRepresentation(t *testing.T) {
 	// given
 	serializer := NewModelSerializer[mockModel]()
@@ -149,6 +337,75 @@ func TestModelSerializerWithNewField(t *testing.T) {
 	assert.True(t, barFieldExists)
 }
 
+type mockScheduledModel struct {
+	ID       string    `json:"id"`
+	StartsAt time.Time `json:"starts_at"`
+}
+
+func unixTimeMapper() *types.FieldTypeMapper {
+	mapper := types.NewFieldTypeMapper()
+	mapper.Register("time.Time", types.FieldType{
+		InternalToResponse: func(v any) (any, error) {
+			return v.(time.Time).Unix(), nil
+		},
+		RequestToInternal: func(v any) (any, error) {
+			seconds, ok := v.(float64)
+			if !ok {
+				return nil, errors.New("Expected a unix timestamp")
+			}
+			return time.Unix(int64(seconds), 0).UTC(), nil
+		},
+	})
+	return mapper
+}
+
+func TestModelSerializerWithFieldTypeMapper(t *testing.T) {
+	// given
+	startsAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	defaultSerializer := NewModelSerializer[mockScheduledModel]()
+	unixSerializer := NewModelSerializer[mockScheduledModel](UsingFieldTypeMapper(unixTimeMapper()))
+
+	// when
+	defaultRepr, defaultReprErr := defaultSerializer.ToRepresentation(
+		models.InternalValue{"id": "1", "starts_at": startsAt}, nil,
+	)
+	unixRepr, unixReprErr := unixSerializer.ToRepresentation(models.InternalValue{"id": "1", "starts_at": startsAt}, nil)
+	unixIntVal, unixIntValErr := unixSerializer.ToInternalValue(map[string]any{"starts_at": float64(startsAt.Unix())}, nil)
+	_, isoErr := unixSerializer.ToInternalValue(map[string]any{"starts_at": "2024-01-02T03:04:05Z"}, nil)
+
+	// then
+	assert.NoError(t, defaultReprErr)
+	assert.Equal(t, "2024-01-02T03:04:05Z", defaultRepr["starts_at"])
+	assert.NoError(t, unixReprErr)
+	assert.Equal(t, startsAt.Unix(), unixRepr["starts_at"])
+	assert.NoError(t, unixIntValErr)
+	assert.Equal(t, startsAt, unixIntVal["starts_at"])
+	assert.Error(t, isoErr)
+	assert.False(t, unixSerializer.Fields["id"].IsWritable(), "the options of the fields should be kept")
+	assert.False(t, types.Mapper().Overrides("time.Time"), "the default mapper should not be modified")
+}
+
+type mockTaggedModel struct {
+	ID   string                       `json:"id"`
+	Tags models.SliceField[time.Time] `json:"tags"`
+}
+
+func TestModelSerializerWithFieldTypeMapperConvertsSliceElements(t *testing.T) {
+	// given
+	serializer := NewModelSerializer[mockTaggedModel](UsingFieldTypeMapper(unixTimeMapper()))
+	tags := models.SliceField[time.Time]{time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)}
+
+	// when
+	intVal, intValErr := serializer.ToInternalValue(map[string]any{"tags": []any{float64(tags[0].Unix())}}, nil)
+	repr, reprErr := serializer.ToRepresentation(models.InternalValue{"id": "1", "tags": tags}, nil)
+
+	// then
+	assert.NoError(t, intValErr)
+	assert.Equal(t, &tags, intVal["tags"])
+	assert.NoError(t, reprErr)
+	assert.Equal(t, []any{tags[0].Unix()}, repr["tags"])
+}
+
 func TestModelSerializerWithField(t *testing.T) {
 	// given
 	serializer := NewModelSerializer[mockModel]().WithField(
@@ -258,3 +515,26 @@ func TestWithModelFieldsPanicsWhenRepresentationDetectorErr(t *testing.T) {
 		serializer.WithModelFields([]string{"foo"})
 	})
 }
+
+type orderLineMockModel struct {
+	ID    uint            `json:"id"`
+	Price decimal.Decimal `json:"price" grf:"decimal_format:number"`
+}
+
+func TestParseBodyKeepsExactNumbersOfNestedSerializers(t *testing.T) {
+	// given
+	ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+	ctx.Request = httptest.NewRequest(
+		http.MethodPost, "/orders", strings.NewReader(`{"price": 1, "line": {"price": 0.30000000000000000001}}`),
+	)
+	serializer := NewModelSerializer[orderLineMockModel]()
+
+	// when
+	body, parseErr := ParseBody(ctx)
+	assert.NoError(t, parseErr)
+	intVal, intValErr := serializer.ToInternalValue(body["line"].(map[string]any), ctx)
+
+	// then
+	assert.NoError(t, intValErr)
+	assert.Equal(t, "0.30000000000000000001", intVal["price"].(decimal.Decimal).String())
+}
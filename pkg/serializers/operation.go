@@ -0,0 +1,69 @@
+package serializers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/glothriel/grf/pkg/models"
+)
+
+// Operation tells the serializer what kind of write the incoming payload is used for. Views
+// set it in the request context before calling ToInternalValue, so the serializer can decide
+// how to treat fields missing from the payload.
+type Operation int
+
+const (
+	// OperationUnknown is used when the serializer is called outside of the built-in views
+	OperationUnknown Operation = iota
+	// OperationCreate is a POST request creating a new entity
+	OperationCreate
+	// OperationUpdate is a PUT request, a full replacement of an existing entity
+	OperationUpdate
+	// OperationPartialUpdate is a PATCH request, only the supplied fields are changed
+	OperationPartialUpdate
+)
+
+const ctxOperationKey = "serializers:operation"
+const ctxInstanceKey = "serializers:instance"
+
+// CtxSetOperation stores the operation in the request context
+func CtxSetOperation(ctx *gin.Context, op Operation) {
+	ctx.Set(ctxOperationKey, op)
+}
+
+// CtxOperation returns the operation stored in the request context, or OperationUnknown
+// if none was set
+func CtxOperation(ctx *gin.Context) Operation {
+	if ctx == nil {
+		return OperationUnknown
+	}
+	anyVal, ok := ctx.Get(ctxOperationKey)
+	if !ok {
+		return OperationUnknown
+	}
+	op, ok := anyVal.(Operation)
+	if !ok {
+		return OperationUnknown
+	}
+	return op
+}
+
+// CtxSetInstance stores the existing instance the payload is applied to in the request context
+func CtxSetInstance(ctx *gin.Context, instance models.InternalValue) {
+	ctx.Set(ctxInstanceKey, instance)
+}
+
+// CtxInstance returns the existing instance stored in the request context, or nil if none was set, for example
+// when creating a new entity
+func CtxInstance(ctx *gin.Context) models.InternalValue {
+	if ctx == nil {
+		return nil
+	}
+	anyVal, ok := ctx.Get(ctxInstanceKey)
+	if !ok {
+		return nil
+	}
+	instance, ok := anyVal.(models.InternalValue)
+	if !ok {
+		return nil
+	}
+	return instance
+}
@@ -1,7 +1,11 @@
 package serializers
 
 import (
+	"encoding/json"
+	"io"
+
 	"github.com/gin-gonic/gin"
+	"github.com/glothriel/grf/pkg/fields"
 	"github.com/glothriel/grf/pkg/models"
 )
 
@@ -11,3 +15,25 @@ type Serializer interface {
 	ToInternalValue(map[string]any, *gin.Context) (models.InternalValue, error)
 	ToRepresentation(models.InternalValue, *gin.Context) (Representation, error)
 }
+
+// FieldLister is implemented by serializers that can describe their fields, it's used for
+// example to generate the API schema
+type FieldLister interface {
+	ListFields() []fields.Field
+}
+
+// ParseBody decodes the JSON object sent in the request body. The numbers are decoded as json.Number, so
+// the fields can read their exact values, for example the ones of decimal.Decimal, also when they are
+// nested. The fields converting the numbers to float64 should use types.AsFloat64.
+func ParseBody(ctx *gin.Context) (map[string]any, error) {
+	if ctx.Request == nil || ctx.Request.Body == nil {
+		return nil, io.EOF
+	}
+	decoder := json.NewDecoder(ctx.Request.Body)
+	decoder.UseNumber()
+	var body map[string]any
+	if decodeErr := decoder.Decode(&body); decodeErr != nil {
+		return nil, decodeErr
+	}
+	return body, nil
+}
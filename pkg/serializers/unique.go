@@ -0,0 +1,111 @@
+package serializers
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/glothriel/grf/pkg/models"
+)
+
+// UniquenessChecker is implemented by the query drivers able to look for conflicting entities. The built-in
+// views store the driver in the request context, so it can be used by UniqueValidator and
+// UniqueTogetherValidator.
+type UniquenessChecker interface {
+	// Exists tells if an entity with the given field values exists, the entity with excludeID is ignored
+	Exists(ctx *gin.Context, values map[string]any, excludeID any) (bool, error)
+}
+
+// ErrorNoUniquenessChecker is returned by the uniqueness validators if the query driver can't check for
+// conflicting entities, or the validators are used outside of the built-in views
+var ErrorNoUniquenessChecker = errors.New("uniqueness checker not found in the context")
+
+const ctxUniquenessCheckerKey = "serializers:uniqueness_checker"
+
+// CtxSetUniquenessChecker stores the uniqueness checker in the request context
+func CtxSetUniquenessChecker(ctx *gin.Context, checker UniquenessChecker) {
+	ctx.Set(ctxUniquenessCheckerKey, checker)
+}
+
+// CtxUniquenessChecker returns the uniqueness checker stored in the request context, or nil if none was set
+func CtxUniquenessChecker(ctx *gin.Context) UniquenessChecker {
+	if ctx == nil {
+		return nil
+	}
+	anyVal, ok := ctx.Get(ctxUniquenessCheckerKey)
+	if !ok {
+		return nil
+	}
+	checker, ok := anyVal.(UniquenessChecker)
+	if !ok {
+		return nil
+	}
+	return checker
+}
+
+type uniqueValidator struct {
+	fields  []string
+	message string
+}
+
+func (v *uniqueValidator) ValidateWithContext(intVal models.InternalValue, validationCtx ValidationContext) error {
+	values := map[string]any{}
+	changed := false
+	for _, field := range v.fields {
+		value, ok := intVal[field]
+		if ok {
+			changed = true
+		} else {
+			// Partial updates don't have to contain all the fields, the stored ones are used instead
+			value, ok = validationCtx.Instance[field]
+		}
+		// Like in databases, missing or null values never conflict
+		if !ok || value == nil {
+			return nil
+		}
+		values[field] = value
+	}
+	if !changed {
+		return nil
+	}
+	checker := CtxUniquenessChecker(validationCtx.Ctx)
+	if checker == nil {
+		return ErrorNoUniquenessChecker
+	}
+	var excludeID any
+	if validationCtx.Instance != nil {
+		excludeID = validationCtx.Instance["id"]
+	}
+	exists, existsErr := checker.Exists(validationCtx.Ctx, values, excludeID)
+	if existsErr != nil {
+		return existsErr
+	}
+	if !exists {
+		return nil
+	}
+	validationErr := NewValidationError()
+	for _, field := range v.fields {
+		validationErr.Add(field, v.message)
+	}
+	return validationErr
+}
+
+// UniqueValidator rejects the payload if another entity with the same value of the field already exists. The
+// entity being updated is not taken into account.
+func UniqueValidator(field string) ContextValidator {
+	return &uniqueValidator{fields: []string{field}, message: "already exists"}
+}
+
+// UniqueTogetherValidator rejects the payload if another entity with the same values of all the fields already
+// exists. The entity being updated is not taken into account.
+func UniqueTogetherValidator(fields ...string) ContextValidator {
+	quoted := make([]string, len(fields))
+	for i, field := range fields {
+		quoted[i] = fmt.Sprintf("`%s`", field)
+	}
+	return &uniqueValidator{
+		fields:  fields,
+		message: fmt.Sprintf("The fields %s must make a unique set", strings.Join(quoted, ", ")),
+	}
+}
@@ -0,0 +1,49 @@
+package serializers
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/glothriel/grf/pkg/models"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockUniquenessChecker struct {
+	calls []map[string]any
+}
+
+func (c *mockUniquenessChecker) Exists(_ *gin.Context, values map[string]any, excludeID any) (bool, error) {
+	c.calls = append(c.calls, map[string]any{"values": values, "exclude": excludeID})
+	return false, nil
+}
+
+func TestUniqueValidatorSkipsUnchangedAndNullValues(t *testing.T) {
+	// given
+	ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+	checker := &mockUniquenessChecker{}
+	CtxSetUniquenessChecker(ctx, checker)
+	instance := models.InternalValue{"id": uint(7), "tenant": "t1", "slug": nil}
+	validationCtx := ValidationContext{Ctx: ctx, Operation: OperationPartialUpdate, Instance: instance}
+
+	// when
+	unchangedErr := UniqueTogetherValidator("tenant", "email").ValidateWithContext(models.InternalValue{}, validationCtx)
+	nullErr := UniqueTogetherValidator("tenant", "slug").ValidateWithContext(models.InternalValue{"tenant": "t2"}, validationCtx)
+	checkedErr := UniqueTogetherValidator("tenant", "id").ValidateWithContext(models.InternalValue{"tenant": "t2"}, validationCtx)
+
+	// then
+	assert.NoError(t, unchangedErr)
+	assert.NoError(t, nullErr)
+	assert.NoError(t, checkedErr)
+	assert.Equal(t, []map[string]any{
+		{"values": map[string]any{"tenant": "t2", "id": uint(7)}, "exclude": uint(7)},
+	}, checker.calls)
+}
+
+func TestUniqueValidatorRequiresChecker(t *testing.T) {
+	// when
+	err := UniqueValidator("email").ValidateWithContext(models.InternalValue{"email": "a"}, ValidationContext{})
+
+	// then
+	assert.ErrorIs(t, err, ErrorNoUniquenessChecker)
+}
@@ -2,10 +2,13 @@ package serializers
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"strconv"
 	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/glothriel/grf/pkg/fields"
 	"github.com/glothriel/grf/pkg/models"
 	playgroundValidate "github.com/go-playground/validator/v10"
 	"github.com/santhosh-tekuri/jsonschema/v5"
@@ -14,36 +17,102 @@ import (
 
 type ValidatingSerializer[Model any] struct {
 	child      Serializer
-	validators []Validator
+	validators []ContextValidator
 }
 
+// ToInternalValue converts the payload using the child and validates it. If some fields can't be converted,
+// the validators still run on the ones that were, and all the errors are returned as a single ValidationError.
 func (s *ValidatingSerializer[Model]) ToInternalValue(raw map[string]any, ctx *gin.Context) (models.InternalValue, error) {
 	intVal, err := s.child.ToInternalValue(raw, ctx)
-	if err != nil {
-		return intVal, err
+	if err == nil {
+		return intVal, s.validate(intVal, ctx)
 	}
-	return intVal, s.validate(intVal, ctx)
+	var fieldErr *ValidationError
+	if intVal == nil || !errors.As(err, &fieldErr) {
+		return nil, err
+	}
+	validateErr := s.validate(intVal, ctx)
+	var validatorErr *ValidationError
+	if !errors.As(validateErr, &validatorErr) {
+		if validateErr != nil {
+			return nil, validateErr
+		}
+		return nil, err
+	}
+	merged := NewValidationError()
+	_ = merged.Merge("", fieldErr)
+	// The fields that could not be converted are missing from the validated value, so the validators may
+	// complain about them too, eg. that they're required. Only the conversion errors are kept for such fields.
+	for field, messages := range validatorErr.FieldErrors {
+		if !hasErrorsOf(fieldErr, field) {
+			merged.Add(field, messages...)
+		}
+	}
+	return nil, merged
+}
+
+// hasErrorsOf tells if the error holds messages of the field or of any field it's nested in, eg. `photos` for
+// `photos.2.url`
+func hasErrorsOf(validationErr *ValidationError, field string) bool {
+	for failed := range validationErr.FieldErrors {
+		if failed != NonFieldErrors && (field == failed || strings.HasPrefix(field, failed+".")) {
+			return true
+		}
+	}
+	return false
 }
 
 func (s *ValidatingSerializer[Model]) ToRepresentation(intVal models.InternalValue, ctx *gin.Context) (Representation, error) {
 	return s.child.ToRepresentation(intVal, ctx)
 }
 
+// validate runs all the validators and merges their ValidationErrors, any other error is returned immediately
 func (s *ValidatingSerializer[Model]) validate(intVal models.InternalValue, ctx *gin.Context) error {
-	errors := make([]error, 0)
+	validationCtx := ValidationContext{Ctx: ctx, Operation: CtxOperation(ctx), Instance: CtxInstance(ctx)}
+	validationErr := NewValidationError()
 	for _, validator := range s.validators {
-		err := validator.Validate(intVal)
-		if err != nil {
-			errors = append(errors, err)
+		err := validator.ValidateWithContext(intVal, validationCtx)
+		if err == nil {
+			continue
+		}
+		if mergeErr := validationErr.Merge("", err); mergeErr != nil {
+			return mergeErr
 		}
 	}
-	if len(errors) > 0 {
-		return errors[0]
+	return validationErr.OrNil()
+}
+
+// ListFields returns the fields of the wrapped serializer, or nil if it does not expose them
+func (s *ValidatingSerializer[Model]) ListFields() []fields.Field {
+	lister, ok := s.child.(FieldLister)
+	if !ok {
+		return nil
 	}
-	return nil
+	return lister.ListFields()
+}
+
+// Validators returns the validators used by the serializer that implement Validator, context validators
+// added using AddContextValidator are included only if they implement it too
+func (s *ValidatingSerializer[Model]) Validators() []Validator {
+	validators := make([]Validator, 0, len(s.validators))
+	for _, validator := range s.validators {
+		switch v := validator.(type) {
+		case *validatorAdapter:
+			validators = append(validators, v.validator)
+		case Validator:
+			validators = append(validators, v)
+		}
+	}
+	return validators
 }
 
 func (s *ValidatingSerializer[Model]) AddValidator(validator Validator) *ValidatingSerializer[Model] {
+	s.validators = append(s.validators, AdaptValidator(validator))
+	return s
+}
+
+// AddContextValidator adds a validator, that receives the request, the operation and the existing instance
+func (s *ValidatingSerializer[Model]) AddContextValidator(validator ContextValidator) *ValidatingSerializer[Model] {
 	s.validators = append(s.validators, validator)
 	return s
 }
@@ -62,6 +131,50 @@ type Validator interface {
 	Validate(models.InternalValue) error
 }
 
+// ValidationContext describes the request the validated payload comes from
+type ValidationContext struct {
+	Ctx       *gin.Context
+	Operation Operation
+	// Instance is the stored entity the payload is applied to, it's nil when creating a new one. For partial
+	// updates it allows validating the supplied fields against the ones that are not sent by the client.
+	Instance models.InternalValue
+}
+
+// ContextValidator is a Validator, that is also aware of the request, the operation and the existing instance
+type ContextValidator interface {
+	ValidateWithContext(intVal models.InternalValue, validationCtx ValidationContext) error
+}
+
+type validatorAdapter struct {
+	validator Validator
+}
+
+func (v *validatorAdapter) ValidateWithContext(intVal models.InternalValue, _ ValidationContext) error {
+	return v.validator.Validate(intVal)
+}
+
+// AdaptValidator allows using a Validator where a ContextValidator is expected, the context is ignored.
+// Validators that also implement ContextValidator, like the built-in ones, are returned as they are.
+func AdaptValidator(validator Validator) ContextValidator {
+	if contextValidator, ok := validator.(ContextValidator); ok {
+		return contextValidator
+	}
+	return &validatorAdapter{validator: validator}
+}
+
+type contextValidatorFunc struct {
+	validateFunc func(models.InternalValue, ValidationContext) error
+}
+
+func (v *contextValidatorFunc) ValidateWithContext(intVal models.InternalValue, validationCtx ValidationContext) error {
+	return v.validateFunc(intVal, validationCtx)
+}
+
+// NewContextValidator creates a ContextValidator from a function
+func NewContextValidator(validateFunc func(models.InternalValue, ValidationContext) error) ContextValidator {
+	return &contextValidatorFunc{validateFunc: validateFunc}
+}
+
 type goPlaygroundValidator[Model any] struct {
 	rules map[string]any
 }
@@ -88,6 +201,28 @@ func (v *goPlaygroundValidator[Model]) Validate(intVal models.InternalValue) (er
 	return validationErr
 }
 
+// ValidateWithContext validates only the fields present in the payload of partial updates, so the `required`
+// rules of the fields the client did not send, and thus did not change, are not checked
+func (v *goPlaygroundValidator[Model]) ValidateWithContext(
+	intVal models.InternalValue, validationCtx ValidationContext,
+) error {
+	if validationCtx.Operation != OperationPartialUpdate {
+		return v.Validate(intVal)
+	}
+	rules := map[string]any{}
+	for field, rule := range v.rules {
+		if _, ok := intVal[field]; ok {
+			rules[field] = rule
+		}
+	}
+	return (&goPlaygroundValidator[Model]{rules: rules}).Validate(intVal)
+}
+
+// Rules returns the go-playground/validator rules keyed by field name
+func (v *goPlaygroundValidator[Model]) Rules() map[string]any {
+	return v.rules
+}
+
 func NewGoPlaygroundValidator[Model any](
 	rules map[string]any,
 ) *goPlaygroundValidator[Model] {
@@ -96,10 +231,68 @@ func NewGoPlaygroundValidator[Model any](
 	}
 }
 
+// NonFieldErrors is the key of the errors that are not related to any particular field
+const NonFieldErrors = "non_field_errors"
+
+// ValidationError holds the messages describing why the payload is invalid, keyed by the field names. Nested
+// fields use dotted paths, for example `photos.2.url`.
 type ValidationError struct {
 	FieldErrors map[string][]string
 }
 
+// NewValidationError creates an empty ValidationError, that can be used to collect the errors
+func NewValidationError() *ValidationError {
+	return &ValidationError{FieldErrors: map[string][]string{}}
+}
+
+// Add appends the messages to the errors of the field, an empty field adds them to NonFieldErrors
+func (e *ValidationError) Add(field string, messages ...string) *ValidationError {
+	if field == "" {
+		field = NonFieldErrors
+	}
+	e.FieldErrors[field] = append(e.FieldErrors[field], messages...)
+	return e
+}
+
+// Merge adds the messages of another ValidationError, prefixing its fields with the path. The errors of the
+// non-field bucket of the other error are attached to the path itself. models.ElementErrors are attached to
+// the paths of the elements, eg. `tags.2`. Any other error is returned as is.
+func (e *ValidationError) Merge(path string, err error) error {
+	var elementErrs models.ElementErrors
+	if errors.As(err, &elementErrs) {
+		for index, message := range elementErrs {
+			e.Add(joinFieldPath(path, strconv.Itoa(index)), message)
+		}
+		return nil
+	}
+	var other *ValidationError
+	if !errors.As(err, &other) {
+		return err
+	}
+	for field, messages := range other.FieldErrors {
+		e.Add(joinFieldPath(path, field), messages...)
+	}
+	return nil
+}
+
+// OrNil returns nil if no errors were collected, so that the result can be returned directly
+func (e *ValidationError) OrNil() error {
+	if len(e.FieldErrors) == 0 {
+		return nil
+	}
+	return e
+}
+
+func joinFieldPath(path, field string) string {
+	if path == "" {
+		return field
+	}
+	if field == NonFieldErrors || field == "" {
+		return path
+	}
+	return path + "." + field
+}
+
 // Uses string builder to build error message
 func (e *ValidationError) Error() string {
 	var sb strings.Builder
@@ -129,6 +322,14 @@ func NewSimpleValidator(validateFunc func(models.InternalValue) error) Validator
 
 type jsonSchemaValidator struct {
 	schema *jsonschema.Schema
+	// partialSchema is used for partial updates, it doesn't require any of the properties
+	partialSchema *jsonschema.Schema
+	rawSchema     map[string]any
+}
+
+// RawSchema returns the JSON Schema the validator was created with
+func (v *jsonSchemaValidator) RawSchema() map[string]any {
+	return v.rawSchema
 }
 
 // TransformError recursively transforms a ValidationError into a map[string][]string.
@@ -151,7 +352,7 @@ func TransformError(err *jsonschema.ValidationError) map[string][]string {
 			// It's a leaf error, add its message to the map.
 			location := err.InstanceLocation
 			if location == "" {
-				location = "all"
+				location = NonFieldErrors
 			} else {
 				location = strings.Replace(location, "/", ".", -1)
 				location = location[1:]
@@ -170,15 +371,27 @@ func TransformError(err *jsonschema.ValidationError) map[string][]string {
 }
 
 func (v *jsonSchemaValidator) Validate(intVal models.InternalValue) error {
+	return validateWithJSONSchema(v.schema, intVal)
+}
+
+// ValidateWithContext skips the `required` keyword of the schema for partial updates, as the payload
+// contains only the changed properties
+func (v *jsonSchemaValidator) ValidateWithContext(intVal models.InternalValue, validationCtx ValidationContext) error {
+	if validationCtx.Operation == OperationPartialUpdate {
+		return validateWithJSONSchema(v.partialSchema, intVal)
+	}
+	return validateWithJSONSchema(v.schema, intVal)
+}
 
-	if validateErr := v.schema.Validate(
+func validateWithJSONSchema(schema *jsonschema.Schema, intVal models.InternalValue) error {
+	if validateErr := schema.Validate(
 		map[string]any(intVal),
 	); validateErr != nil {
 		jsonSchemaValidationErr, ok := validateErr.(*jsonschema.ValidationError)
 		if !ok {
 			return &ValidationError{
 				FieldErrors: map[string][]string{
-					"all": {validateErr.Error()},
+					NonFieldErrors: {validateErr.Error()},
 				},
 			}
 		}
@@ -194,6 +407,20 @@ func (v *jsonSchemaValidator) Validate(intVal models.InternalValue) error {
 }
 
 func NewJSONSchemaValidator(rawSchema map[string]any) Validator {
+	partialRawSchema := map[string]any{}
+	for k, v := range rawSchema {
+		if k != "required" {
+			partialRawSchema[k] = v
+		}
+	}
+	return &jsonSchemaValidator{
+		schema:        compileJSONSchema(rawSchema),
+		partialSchema: compileJSONSchema(partialRawSchema),
+		rawSchema:     rawSchema,
+	}
+}
+
+func compileJSONSchema(rawSchema map[string]any) *jsonschema.Schema {
 	rawSchema["$schema"] = "https://json-schema.org/draft/2020-12/schema"
 	rawSchema["$id"] = "https://glothriel.github.io/grf/schema.json"
 	encodedSchema, marshalErr := json.Marshal(rawSchema)
@@ -204,5 +431,59 @@ func NewJSONSchemaValidator(rawSchema map[string]any) Validator {
 	if compileErr != nil {
 		logrus.Panicf("Error compiling JSONSchema: %s", compileErr)
 	}
-	return &jsonSchemaValidator{schema: compiledSchema}
+	return compiledSchema
+}
+
+type jsonSchemaFieldValidator struct {
+	field     string
+	schema    *jsonschema.Schema
+	rawSchema map[string]any
+}
+
+// RawSchema returns a JSON Schema of the whole payload, with the schema of the field as its property
+func (v *jsonSchemaFieldValidator) RawSchema() map[string]any {
+	return map[string]any{"properties": map[string]any{v.field: v.rawSchema}}
+}
+
+func (v *jsonSchemaFieldValidator) Validate(intVal models.InternalValue) error {
+	value, ok := intVal[v.field]
+	if !ok || value == nil {
+		return nil
+	}
+	if document, isDocument := value.(models.JSONDocument); isDocument {
+		value = document.JSONData()
+	}
+	// The validator expects values decoded from JSON, and not Go types, like structs
+	encoded, marshalErr := json.Marshal(value)
+	if marshalErr != nil {
+		return NewValidationError().Add(v.field, marshalErr.Error())
+	}
+	var decoded any
+	if unmarshalErr := json.Unmarshal(encoded, &decoded); unmarshalErr != nil {
+		return NewValidationError().Add(v.field, unmarshalErr.Error())
+	}
+	if validateErr := v.schema.Validate(decoded); validateErr != nil {
+		jsonSchemaValidationErr, ok := validateErr.(*jsonschema.ValidationError)
+		if !ok {
+			return NewValidationError().Add(v.field, validateErr.Error())
+		}
+		validationErr := NewValidationError()
+		_ = validationErr.Merge(v.field, &ValidationError{FieldErrors: TransformError(jsonSchemaValidationErr)})
+		return validationErr
+	}
+	return nil
+}
+
+// NewJSONSchemaFieldValidator validates the value of a single field, usually a models.JSONField, using the
+// JSON Schema. The errors are reported under the path of the invalid value, for example `attributes.color`.
+func NewJSONSchemaFieldValidator(field string, rawSchema map[string]any) Validator {
+	schemaToCompile := map[string]any{}
+	for k, v := range rawSchema {
+		schemaToCompile[k] = v
+	}
+	return &jsonSchemaFieldValidator{
+		field:     field,
+		schema:    compileJSONSchema(schemaToCompile),
+		rawSchema: rawSchema,
+	}
 }
@@ -2,8 +2,10 @@ package serializers
 
 import (
 	"errors"
+	"net/http/httptest"
 	"testing"
 
+	"github.com/gin-gonic/gin"
 	"github.com/glothriel/grf/pkg/models"
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
@@ -57,6 +59,47 @@ func TestGoPlaygroundValidatorErr(t *testing.T) {
 	assert.Error(t, err)
 }
 
+func TestValidatorsSkipRequiredRulesOfFieldsNotSentInPartialUpdate(t *testing.T) {
+	validators := map[string]Validator{
+		"go-playground": NewGoPlaygroundValidator[mockValidatedModel](
+			map[string]any{"name": "required", "age": "required,gt=0,lt=130"},
+		),
+		"json schema": NewJSONSchemaValidator(map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"age": map[string]any{"type": "number", "exclusiveMaximum": 130},
+			},
+			"required": []string{"name", "age"},
+		}),
+	}
+	for name, validator := range validators {
+		t.Run(name, func(t *testing.T) {
+			// given
+			serializer := NewValidatingSerializer[mockValidatedModel](
+				NewModelSerializer[mockValidatedModel](), validator,
+			)
+			ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+			CtxSetOperation(ctx, OperationPartialUpdate)
+
+			// when
+			_, validErr := serializer.ToInternalValue(map[string]any{"age": 20.0}, ctx)
+			_, invalidErr := serializer.ToInternalValue(map[string]any{"age": 2000.0}, ctx)
+			CtxSetOperation(ctx, OperationUpdate)
+			_, fullUpdateErr := serializer.ToInternalValue(map[string]any{
+				"id": "1", "age": 20.0, "surname": "Doe", "is_married": false,
+			}, ctx)
+
+			// then
+			assert.NoError(t, validErr)
+			var validationErr *ValidationError
+			assert.ErrorAs(t, invalidErr, &validationErr)
+			assert.Contains(t, validationErr.FieldErrors, "age")
+			assert.ErrorAs(t, fullUpdateErr, &validationErr)
+			assert.Contains(t, validationErr.FieldErrors, "name")
+		})
+	}
+}
+
 func TestValidatingSerializerToInternalValue(t *testing.T) {
 	// given
 	serializer := NewValidatingSerializer[mockValidatedModel](
@@ -99,6 +142,36 @@ func TestValidatingSerializerToInternalValueErr(t *testing.T) {
 	assert.Error(t, err)
 }
 
+func TestValidatingSerializerToInternalValueReturnsFieldAndValidatorErrors(t *testing.T) {
+	// given
+	serializer := NewValidatingSerializer[mockValidatedModel](
+		NewModelSerializer[mockValidatedModel](),
+		NewSimpleValidator(func(intVal models.InternalValue) error {
+			if intVal["name"] == intVal["surname"] {
+				return NewValidationError().Add("", "name and surname must differ")
+			}
+			return nil
+		}),
+		NewGoPlaygroundValidator[mockValidatedModel](map[string]any{"age": "required"}),
+	)
+
+	// when
+	_, err := serializer.ToInternalValue(map[string]any{
+		"name":       "John",
+		"surname":    "John",
+		"age":        "foo",
+		"is_married": true,
+	}, nil)
+
+	// then
+	validationErr, ok := err.(*ValidationError)
+	assert.True(t, ok)
+	assert.Equal(t, []string{"name and surname must differ"}, validationErr.FieldErrors[NonFieldErrors])
+	assert.Len(t, validationErr.FieldErrors["age"], 1)
+	assert.NotContains(t, validationErr.FieldErrors["age"][0], "required")
+	assert.Len(t, validationErr.FieldErrors, 2)
+}
+
 func TestValidatingSerializerToRepresentation(t *testing.T) {
 	// given
 	serializer := NewValidatingSerializer[mockValidatedModel](
@@ -162,6 +235,88 @@ func TestValidatingSerializerValidateErr(t *testing.T) {
 	assert.Error(t, err)
 }
 
+func TestValidatingSerializerValidateMergesAllErrors(t *testing.T) {
+	// given
+	fieldValidator := func(field, message string) Validator {
+		return NewSimpleValidator(func(models.InternalValue) error {
+			return NewValidationError().Add(field, message)
+		})
+	}
+	serializer := NewValidatingSerializer[mockValidatedModel](
+		NewModelSerializer[mockValidatedModel](),
+		fieldValidator("name", "too short"),
+		fieldValidator("name", "must be capitalized"),
+		fieldValidator("", "name and surname must differ"),
+		&mockValidator{},
+	)
+
+	// when
+	err := serializer.validate(models.InternalValue{}, nil)
+
+	// then
+	assert.Equal(t, &ValidationError{FieldErrors: map[string][]string{
+		"name":         {"too short", "must be capitalized"},
+		NonFieldErrors: {"name and surname must differ"},
+	}}, err)
+}
+
+func TestValidatingSerializerContextValidator(t *testing.T) {
+	// given
+	ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+	CtxSetOperation(ctx, OperationUpdate)
+	CtxSetInstance(ctx, models.InternalValue{"name": "John"})
+	var received ValidationContext
+	plain := &mockValidator{}
+	serializer := NewValidatingSerializer[mockValidatedModel](
+		NewModelSerializer[mockValidatedModel](), plain,
+	).AddContextValidator(NewContextValidator(func(_ models.InternalValue, validationCtx ValidationContext) error {
+		received = validationCtx
+		return nil
+	}))
+
+	// when
+	err := serializer.validate(models.InternalValue{"surname": "Doe"}, ctx)
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, ValidationContext{Ctx: ctx, Operation: OperationUpdate, Instance: models.InternalValue{"name": "John"}}, received)
+	assert.Equal(t, []Validator{plain}, serializer.Validators())
+}
+
+func TestValidationErrorMerge(t *testing.T) {
+	// given
+	validationErr := NewValidationError().Add("photos", "too many photos")
+	nested := NewValidationError().Add("2.url", "invalid url").Add("", "duplicated photo")
+
+	// when
+	mergeErr := validationErr.Merge("photos", nested)
+	otherErr := validationErr.Merge("photos", errors.New("boom"))
+
+	// then
+	assert.NoError(t, mergeErr)
+	assert.EqualError(t, otherErr, "boom")
+	assert.Equal(t, map[string][]string{
+		"photos":       {"too many photos", "duplicated photo"},
+		"photos.2.url": {"invalid url"},
+	}, validationErr.FieldErrors)
+	assert.Nil(t, NewValidationError().OrNil())
+}
+
+func TestValidationErrorMergeElementErrors(t *testing.T) {
+	// given
+	validationErr := NewValidationError()
+
+	// when
+	mergeErr := validationErr.Merge("tags", models.ElementErrors{0: "too long", 2: "invalid"})
+
+	// then
+	assert.NoError(t, mergeErr)
+	assert.Equal(t, map[string][]string{
+		"tags.0": {"too long"},
+		"tags.2": {"invalid"},
+	}, validationErr.FieldErrors)
+}
+
 func TestValidatingSerializerAddValidator(t *testing.T) {
 	// given
 	serializer := NewValidatingSerializer[mockValidatedModel](
@@ -230,7 +385,7 @@ func TestJSONSchemaValidationSchemaLvlErr(t *testing.T) {
 
 	// then
 	validationErr := err.(*ValidationError)
-	assert.Equal(t, validationErr.FieldErrors["all"], []string{
+	assert.Equal(t, validationErr.FieldErrors[NonFieldErrors], []string{
 		"missing properties: 'name'",
 	})
 }
@@ -283,6 +438,50 @@ func TestJSONSchemaValidationAttributeLvlErr(t *testing.T) {
 	})
 }
 
+func TestJSONSchemaFieldValidator(t *testing.T) {
+	// given
+	validator := NewJSONSchemaFieldValidator("attributes", map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"color": map[string]any{"type": "string"},
+			"size": map[string]any{
+				"type":       "object",
+				"properties": map[string]any{"width": map[string]any{"type": "number"}},
+			},
+		},
+		"required": []string{"color"},
+	})
+
+	// when
+	validErr := validator.Validate(models.InternalValue{
+		"attributes": models.NewJSONField(map[string]any{"color": "red"}),
+	})
+	missingErr := validator.Validate(models.InternalValue{"name": "John"})
+	invalidErr := validator.Validate(models.InternalValue{
+		"attributes": models.NewJSONField(map[string]any{"size": map[string]any{"width": "wide"}}),
+	})
+
+	// then
+	assert.NoError(t, validErr)
+	assert.NoError(t, missingErr)
+	assert.Equal(t, map[string][]string{
+		"attributes":            {"missing properties: 'color'"},
+		"attributes.size.width": {"expected number, but got string"},
+	}, invalidErr.(*ValidationError).FieldErrors)
+}
+
+func TestJSONSchemaFieldValidatorRawSchema(t *testing.T) {
+	// given
+	rawSchema := map[string]any{"type": "object"}
+
+	// when
+	validator := NewJSONSchemaFieldValidator("attributes", rawSchema)
+
+	// then
+	assert.Equal(t, map[string]any{"properties": map[string]any{"attributes": map[string]any{"type": "object"}}},
+		validator.(interface{ RawSchema() map[string]any }).RawSchema())
+}
+
 func TestSimpleValidator(t *testing.T) {
 	// given
 	validator := NewSimpleValidator(
@@ -0,0 +1,65 @@
+package types
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/shopspring/decimal"
+)
+
+// ConvertToDecimal parses decimal.Decimal from a string or a json.Number. float64 is rejected, as it might
+// have already lost the precision.
+func ConvertToDecimal(in any) (any, error) {
+	var literal string
+	switch v := in.(type) {
+	case string:
+		literal = v
+	case json.Number:
+		literal = v.String()
+	case decimal.Decimal:
+		return v, nil
+	default:
+		return nil, fmt.Errorf("Expected a string or json.Number, got `%T`", in)
+	}
+	d, parseErr := decimal.NewFromString(literal)
+	if parseErr != nil {
+		return nil, fmt.Errorf("`%s` is not a valid decimal number", literal)
+	}
+	return d, nil
+}
+
+// ConvertDecimalToString formats decimal.Decimal as a string, without losing the precision
+func ConvertDecimalToString(in any) (any, error) {
+	switch v := in.(type) {
+	case decimal.Decimal:
+		return v.String(), nil
+	case *decimal.Decimal:
+		return v.String(), nil
+	}
+	return nil, fmt.Errorf("Expected type `decimal.Decimal`, got `%T`", in)
+}
+
+// ValidateDecimal checks the number of digits of the decimal, like the DECIMAL(maxDigits, decimalPlaces) SQL
+// type does. Trailing zeros of the fractional part are not counted. Negative limits are not checked.
+func ValidateDecimal(d decimal.Decimal, maxDigits, decimalPlaces int) error {
+	wholeDigits, fractionalDigits := countDigits(d)
+	if maxDigits >= 0 && wholeDigits+fractionalDigits > maxDigits {
+		return fmt.Errorf("Ensure that there are no more than %d digits in total", maxDigits)
+	}
+	if decimalPlaces >= 0 && fractionalDigits > decimalPlaces {
+		return fmt.Errorf("Ensure that there are no more than %d decimal places", decimalPlaces)
+	}
+	if maxDigits >= 0 && decimalPlaces >= 0 && wholeDigits > maxDigits-decimalPlaces {
+		return fmt.Errorf("Ensure that there are no more than %d digits before the decimal point", maxDigits-decimalPlaces)
+	}
+	return nil
+}
+
+// countDigits returns the number of significant digits before and after the decimal point
+func countDigits(d decimal.Decimal) (int, int) {
+	whole, fractional, _ := strings.Cut(d.Abs().String(), ".")
+	whole = strings.TrimLeft(whole, "0")
+	fractional = strings.TrimRight(fractional, "0")
+	return len(whole), len(fractional)
+}
@@ -1,8 +1,10 @@
 package types
 
 import (
+	"encoding/json"
 	"fmt"
 	"math"
+	"reflect"
 	"time"
 )
 
@@ -26,10 +28,46 @@ type FieldType struct {
 
 type FieldTypeMapper struct {
 	Registered map[string]FieldType
+
+	// parent is asked about the types not registered in this mapper
+	parent *FieldTypeMapper
 }
 
-func (s *FieldTypeMapper) ToRepresentation(typeString string) (ConvertFunc, error) {
+// NewFieldTypeMapper creates an empty mapper inheriting all the types of the default mapper, returned by
+// Mapper(). Types registered in the new mapper override the inherited ones, without affecting the default
+// mapper, so for example two ViewSets can represent the same type differently.
+func NewFieldTypeMapper() *FieldTypeMapper {
+	return &FieldTypeMapper{
+		Registered: make(map[string]FieldType),
+		parent:     Mapper(),
+	}
+}
+
+func (s *FieldTypeMapper) lookup(typeString string) (FieldType, bool) {
 	if fieldType, ok := s.Registered[typeString]; ok {
+		return fieldType, true
+	}
+	if s.parent != nil {
+		return s.parent.lookup(typeString)
+	}
+	return FieldType{}, false
+}
+
+// IsRegistered tells if the mapper, or any mapper it inherits from, knows how to convert the type
+func (s *FieldTypeMapper) IsRegistered(typeString string) bool {
+	_, ok := s.lookup(typeString)
+	return ok
+}
+
+// Overrides tells if the type is registered directly in a mapper inheriting from another one. Overridden
+// types take precedence over the built-in handling of the types, like parsing time.Time from ISO 8601.
+func (s *FieldTypeMapper) Overrides(typeString string) bool {
+	_, ok := s.Registered[typeString]
+	return ok && s.parent != nil
+}
+
+func (s *FieldTypeMapper) ToRepresentation(typeString string) (ConvertFunc, error) {
+	if fieldType, ok := s.lookup(typeString); ok {
 		return func(i any) (any, error) {
 			result, resultErr := fieldType.InternalToResponse(i)
 			if resultErr != nil {
@@ -45,7 +83,7 @@ func (s *FieldTypeMapper) ToRepresentation(typeString string) (ConvertFunc, erro
 }
 
 func (s *FieldTypeMapper) ToInternalValue(typeString string) (ConvertFunc, error) {
-	if fieldType, ok := s.Registered[typeString]; ok {
+	if fieldType, ok := s.lookup(typeString); ok {
 		return func(i any) (any, error) {
 			result, resultErr := fieldType.RequestToInternal(i)
 			if resultErr != nil {
@@ -73,7 +111,7 @@ func DefaultFieldTypeMapper() *FieldTypeMapper {
 	}
 	registered["float64"] = FieldType{
 		InternalToResponse: ConvertPassThroughWithTypeValidation[float64],
-		RequestToInternal:  ConvertPassThroughWithTypeValidation[float64],
+		RequestToInternal:  ConvertToFloat64,
 	}
 	registered["bool"] = FieldType{
 		InternalToResponse: ConvertPassThroughWithTypeValidation[bool],
@@ -83,6 +121,10 @@ func DefaultFieldTypeMapper() *FieldTypeMapper {
 		InternalToResponse: ConvertPassThroughWithTypeValidation[time.Time],
 		RequestToInternal:  ConvertPassThroughWithTypeValidation[time.Time],
 	}
+	registered["decimal.Decimal"] = FieldType{
+		InternalToResponse: ConvertDecimalToString,
+		RequestToInternal:  ConvertToDecimal,
+	}
 	registered["int"] = FieldType{
 		InternalToResponse: ConvertPassThrough,
 		RequestToInternal: ConvertFloatDynamic(
@@ -194,8 +236,30 @@ func ConvertPassThroughWithTypeValidation[T any](in any) (any, error) {
 	return in, nil
 }
 
+// AsFloat64 returns the value of a JSON number. The request bodies are decoded with the numbers kept as
+// json.Number, so the types that can't lose precision, like decimal.Decimal, get the exact values, but the
+// numbers decoded as float64 are accepted too.
+func AsFloat64(in any) (float64, bool) {
+	switch v := in.(type) {
+	case float64:
+		return v, true
+	case json.Number:
+		f, parseErr := v.Float64()
+		return f, parseErr == nil
+	}
+	return 0, false
+}
+
+// ConvertToFloat64 converts a JSON number to float64
+func ConvertToFloat64(in any) (any, error) {
+	if f, ok := AsFloat64(in); ok {
+		return f, nil
+	}
+	return nil, fmt.Errorf("Expected type `float64`, got `%T`", in)
+}
+
 func ConvertFloatToInt(in any) (any, error) {
-	if f, ok := in.(float64); ok {
+	if f, ok := AsFloat64(in); ok {
 		if math.Mod(f, 1) == 0 {
 			i := int(f)
 			return i, nil
@@ -207,7 +271,7 @@ func ConvertFloatToInt(in any) (any, error) {
 }
 
 func ConvertFloatToUint(in any) (any, error) {
-	if f, ok := in.(float64); ok {
+	if f, ok := AsFloat64(in); ok {
 		if math.Mod(f, 1) == 0 && f >= 0 {
 			i := int(f)
 			return i, nil
@@ -220,9 +284,13 @@ func ConvertFloatToUint(in any) (any, error) {
 
 func ConvertFloatDynamic(convert func(float64) any, canBeBelowZero bool) func(any) (any, error) {
 	return func(in any) (any, error) {
-		if f, ok := in.(float64); ok {
+		if f, ok := AsFloat64(in); ok {
 			if math.Mod(f, 1) == 0 && ((!canBeBelowZero && f >= 0) || canBeBelowZero) {
-				return convert(f), nil
+				converted := convert(f)
+				if !fitsInto(f, converted) {
+					return 0, fmt.Errorf("Value %f is out of range for type `%T`", f, converted)
+				}
+				return converted, nil
 			} else {
 				return 0, fmt.Errorf("Value %f is not an integer", f)
 			}
@@ -230,3 +298,15 @@ func ConvertFloatDynamic(convert func(float64) any, canBeBelowZero bool) func(an
 		return nil, fmt.Errorf("Expected type `float64`, got `%T`", in)
 	}
 }
+
+// fitsInto tells if the integer converted from the float holds the same value, so it didn't overflow
+func fitsInto(f float64, converted any) bool {
+	value := reflect.ValueOf(converted)
+	switch value.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(value.Int()) == f
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(value.Uint()) == f
+	}
+	return true
+}
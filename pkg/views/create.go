@@ -12,11 +12,13 @@ import (
 // CreateModelFunc is a function that creates a new model
 func CreateModelViewSetFunc[Model any](idf IDFunc, qd queries.Driver[Model], serializer serializers.Serializer) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
-		var rawElement map[string]any
-		if parseErr := ctx.ShouldBindJSON(&rawElement); parseErr != nil {
+		rawElement, parseErr := serializers.ParseBody(ctx)
+		if parseErr != nil {
 			WriteError(ctx, parseErr)
 			return
 		}
+		serializers.CtxSetOperation(ctx, serializers.OperationCreate)
+		ctxSetUniquenessChecker(ctx, qd)
 		internalValue, fromRawErr := serializer.ToInternalValue(rawElement, ctx)
 		if fromRawErr != nil {
 			WriteError(ctx, fromRawErr)
@@ -35,3 +37,10 @@ func CreateModelViewSetFunc[Model any](idf IDFunc, qd queries.Driver[Model], ser
 		ctx.JSON(http.StatusCreated, representation)
 	}
 }
+
+// ctxSetUniquenessChecker makes the query driver available to the uniqueness validators, if it supports them
+func ctxSetUniquenessChecker[Model any](ctx *gin.Context, qd queries.Driver[Model]) {
+	if checker, ok := qd.(serializers.UniquenessChecker); ok {
+		serializers.CtxSetUniquenessChecker(ctx, checker)
+	}
+}
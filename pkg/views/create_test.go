@@ -58,20 +58,42 @@ var createModelViewTests = []struct {
 		wantStatus: 400,
 		wantBodyJSONEquals: map[string]any{
 			"errors": map[string]any{
-				"all": []any{"could not parse request body"},
+				serializers.NonFieldErrors: []any{"could not parse request body"},
 			},
 		},
 	},
 	{
-		name:               "Superfluous Fields",
+		name:       "Superfluous Fields",
+		json:       `{"foo": "bar", "bar": "baz"}`,
+		serializer: serializers.NewModelSerializer[MockModel](),
+		wantStatus: 400,
+		wantBodyJSONEquals: map[string]any{
+			"errors": map[string]any{
+				"bar": []any{"Field `bar` is not accepted by this endpoint"},
+			},
+		},
+	},
+	{
+		name:       "Read-only Fields",
+		json:       `{"id": 5, "foo": "bar"}`,
+		serializer: serializers.NewModelSerializer[MockModel](),
+		wantStatus: 400,
+		wantBodyJSONEquals: map[string]any{
+			"errors": map[string]any{
+				"id": []any{"Field `id` is read-only"},
+			},
+		},
+	},
+	{
+		name:               "Superfluous Fields with lenient serializer",
 		json:               `{"bar": "baz"}`,
-		serializer:         serializers.NewModelSerializer[MockModel](),
+		serializer:         serializers.NewModelSerializer[MockModel]().WithLenient(),
 		wantStatus:         201,
 		wantBodyJSONEquals: map[string]any{"id": float64(2)},
 	},
 	{
 		name: "Invalid to internal value",
-		json: `{"bar": "baz"}`,
+		json: `{"huehue": "baz"}`,
 		serializer: serializers.NewModelSerializer[MockModel]().WithNewField(
 			fields.NewField[MockModel]("huehue").WithInternalValueFunc(
 				func(m map[string]any, s string, ctx *gin.Context) (any, error) {
@@ -84,7 +106,7 @@ var createModelViewTests = []struct {
 	},
 	{
 		name: "Invalid to representation",
-		json: `{"bar": "baz"}`,
+		json: `{"huehue": "baz"}`,
 		serializer: serializers.NewModelSerializer[MockModel]().WithNewField(
 			fields.NewField[MockModel]("huehue").WithInternalValueFunc(
 				func(m map[string]any, s string, ctx *gin.Context) (any, error) {
@@ -10,6 +10,19 @@ import (
 
 func DestroyModelViewSetFunc[Model any](idf IDFunc, qd queries.Driver[Model], serializer serializers.Serializer) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
+		if filterErr := qd.Filter().Apply(ctx); filterErr != nil {
+			WriteError(ctx, filterErr)
+			return
+		}
+		intVal, retrieveErr := qd.CRUD().Retrieve(ctx, idf(ctx))
+		if retrieveErr != nil {
+			WriteError(ctx, retrieveErr)
+			return
+		}
+		if permissionErr := CheckObjectPermissions(ctx, intVal); permissionErr != nil {
+			WriteError(ctx, permissionErr)
+			return
+		}
 		deleteErr := qd.CRUD().Destroy(ctx, idf(ctx))
 		if deleteErr != nil {
 			WriteError(ctx, deleteErr)
@@ -6,6 +6,7 @@ import (
 	"io"
 
 	"github.com/gin-gonic/gin"
+	"github.com/glothriel/grf/pkg/authentication"
 	"github.com/glothriel/grf/pkg/queries/common"
 	"github.com/glothriel/grf/pkg/serializers"
 	"github.com/sirupsen/logrus"
@@ -14,13 +15,25 @@ import (
 // WriteError checks for common error types and maps them to correct HTTP status codes
 func WriteError(ctx *gin.Context, err error) {
 	// Serializers validation
-	ve, isValidationErr := err.(*serializers.ValidationError)
-	if isValidationErr {
+	var ve *serializers.ValidationError
+	if errors.As(err, &ve) {
 		ctx.JSON(400, gin.H{
 			"errors": ve.FieldErrors,
 		})
 		return
 	}
+	// Database constraint violations, translated by the QueryDriver
+	var ce *common.ConstraintError
+	if errors.As(err, &ce) {
+		status := 400
+		if ce.IsConflict() {
+			status = 409
+		}
+		ctx.JSON(status, gin.H{
+			"errors": constraintFieldErrors(ce),
+		})
+		return
+	}
 	// QueryDriver returns common.ErrorNotFound when no entity is found
 	if errors.Is(err, common.ErrorNotFound) {
 		ctx.JSON(404, gin.H{
@@ -28,12 +41,28 @@ func WriteError(ctx *gin.Context, err error) {
 		})
 		return
 	}
+	// Invalid credentials or permissions deny the request
+	if errors.Is(err, ErrorNotAuthenticated) || errors.Is(err, authentication.ErrorInvalidCredentials) {
+		if challenge := authentication.CtxChallenge(ctx); challenge != "" {
+			ctx.Header("WWW-Authenticate", challenge)
+		}
+		ctx.JSON(401, gin.H{
+			"message": err.Error(),
+		})
+		return
+	}
+	if errors.Is(err, ErrorPermissionDenied) {
+		ctx.JSON(403, gin.H{
+			"message": err.Error(),
+		})
+		return
+	}
 	// Empty JSON body or JSON syntax error
 	_, isSyntaxErr := err.(*json.SyntaxError)
 	if errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF) || isSyntaxErr {
 		ctx.JSON(400, gin.H{
 			"errors": map[string][]string{
-				"all": {"could not parse request body"},
+				serializers.NonFieldErrors: {"could not parse request body"},
 			},
 		})
 		return
@@ -43,3 +72,31 @@ func WriteError(ctx *gin.Context, err error) {
 		"message": "internal server error",
 	})
 }
+
+// constraintFieldErrors returns the messages describing the violation keyed by the fields, or by
+// serializers.NonFieldErrors if the fields are unknown. The messages are generic, so they don't reveal the
+// database schema, like the names of the constraints.
+func constraintFieldErrors(ce *common.ConstraintError) map[string][]string {
+	if ce.Referenced {
+		return map[string][]string{serializers.NonFieldErrors: {"instance is still referenced by other instances"}}
+	}
+	if len(ce.Fields) == 0 {
+		return map[string][]string{serializers.NonFieldErrors: {map[common.Constraint]string{
+			common.ConstraintUnique:     "instance already exists",
+			common.ConstraintForeignKey: "related instance does not exist",
+			common.ConstraintNotNull:    "required value is missing",
+			common.ConstraintCheck:      "instance is invalid",
+		}[ce.Constraint]}}
+	}
+	message := map[common.Constraint]string{
+		common.ConstraintUnique:     "already exists",
+		common.ConstraintForeignKey: "does not exist",
+		common.ConstraintNotNull:    "may not be null",
+		common.ConstraintCheck:      "is invalid",
+	}[ce.Constraint]
+	fieldErrors := map[string][]string{}
+	for _, field := range ce.Fields {
+		fieldErrors[field] = []string{message}
+	}
+	return fieldErrors
+}
@@ -3,6 +3,7 @@ package views
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
@@ -47,6 +48,31 @@ func TestWriteError(t *testing.T) {
 			err:      io.EOF,
 			expected: http.StatusBadRequest,
 		},
+		{
+			name:     "not authenticated error",
+			err:      ErrorNotAuthenticated,
+			expected: http.StatusUnauthorized,
+		},
+		{
+			name:     "permission denied error",
+			err:      fmt.Errorf("wrapped: %w", ErrorPermissionDenied),
+			expected: http.StatusForbidden,
+		},
+		{
+			name:     "unique constraint error",
+			err:      &common.ConstraintError{Constraint: common.ConstraintUnique, Fields: []string{"email"}},
+			expected: http.StatusConflict,
+		},
+		{
+			name:     "foreign key constraint error",
+			err:      &common.ConstraintError{Constraint: common.ConstraintForeignKey},
+			expected: http.StatusBadRequest,
+		},
+		{
+			name:     "referenced instance constraint error",
+			err:      &common.ConstraintError{Constraint: common.ConstraintForeignKey, Referenced: true},
+			expected: http.StatusConflict,
+		},
 		{
 			name:     "generic error",
 			err:      errors.New("Some generic unknown error"),
@@ -70,3 +96,53 @@ func TestWriteError(t *testing.T) {
 		})
 	}
 }
+
+func TestWriteErrorDescribesConstraintErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      *common.ConstraintError
+		wantBody string
+	}{
+		{
+			name:     "unique fields",
+			err:      &common.ConstraintError{Constraint: common.ConstraintUnique, Fields: []string{"email"}},
+			wantBody: `{"errors": {"email": ["already exists"]}}`,
+		},
+		{
+			name:     "not null field",
+			err:      &common.ConstraintError{Constraint: common.ConstraintNotNull, Fields: []string{"nickname"}},
+			wantBody: `{"errors": {"nickname": ["may not be null"]}}`,
+		},
+		{
+			name:     "unknown fields",
+			err:      &common.ConstraintError{Constraint: common.ConstraintForeignKey, Name: "fk_orders_user"},
+			wantBody: `{"errors": {"non_field_errors": ["related instance does not exist"]}}`,
+		},
+		{
+			name:     "named check constraint",
+			err:      &common.ConstraintError{Constraint: common.ConstraintCheck, Name: "age_not_negative"},
+			wantBody: `{"errors": {"non_field_errors": ["instance is invalid"]}}`,
+		},
+		{
+			name: "referenced instance",
+			err: &common.ConstraintError{
+				Constraint: common.ConstraintForeignKey, Name: "fk_orders_user", Referenced: true,
+			},
+			wantBody: `{"errors": {"non_field_errors": ["instance is still referenced by other instances"]}}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// given
+			w := httptest.NewRecorder()
+			ctx, _ := gin.CreateTestContext(w)
+
+			// when
+			WriteError(ctx, tt.err)
+
+			// then
+			assert.JSONEq(t, tt.wantBody, w.Body.String())
+			assert.NotContains(t, w.Body.String(), tt.err.Name+"`")
+		})
+	}
+}
@@ -5,20 +5,32 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/glothriel/grf/pkg/queries"
+	"github.com/glothriel/grf/pkg/queries/common"
 	"github.com/glothriel/grf/pkg/serializers"
 )
 
 // ListModelFunc is a gin handler function that lists model instances
 func ListModelViewSetFunc[Model any](idf IDFunc, qd queries.Driver[Model], serializer serializers.Serializer) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
-		qd.Filter().Apply(ctx)
-		qd.Order().Apply(ctx)
-		qd.Pagination().Apply(ctx)
+		for _, queryMod := range []common.QueryMod{qd.Filter(), qd.Order(), qd.Pagination()} {
+			if applyErr := queryMod.Apply(ctx); applyErr != nil {
+				WriteError(ctx, applyErr)
+				return
+			}
+		}
 		internalValues, listErr := qd.CRUD().List(ctx)
 		if listErr != nil {
 			WriteError(ctx, listErr)
 			return
 		}
+		if processor, ok := qd.Pagination().(common.ListProcessor); ok {
+			processed, processErr := processor.ProcessList(ctx, internalValues)
+			if processErr != nil {
+				WriteError(ctx, processErr)
+				return
+			}
+			internalValues = processed
+		}
 		representationItems := []any{}
 		for _, internalValue := range internalValues {
 			rawElement, toRawErr := serializer.ToRepresentation(
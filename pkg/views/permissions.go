@@ -0,0 +1,254 @@
+package views
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/glothriel/grf/pkg/authentication"
+	"github.com/glothriel/grf/pkg/models"
+)
+
+// ErrorNotAuthenticated is returned when the action requires an authenticated user, it's rendered as
+// 401 Unauthorized
+var ErrorNotAuthenticated = authentication.ErrorNotAuthenticated
+
+// ErrorPermissionDenied is returned when the user is not allowed to perform the action, it's rendered as
+// 403 Forbidden
+var ErrorPermissionDenied = errors.New("you do not have permission to perform this action")
+
+// Permission decides if the current request is allowed. HasPermission is checked before the action
+// is performed, HasObjectPermission is additionally checked for the actions operating on a single model
+// instance (Retrieve, Update, PartialUpdate, Destroy), before anything is written.
+type Permission interface {
+	HasPermission(ctx *gin.Context, action ActionID) bool
+	HasObjectPermission(ctx *gin.Context, action ActionID, intVal models.InternalValue) bool
+}
+
+// AllowAny allows all the requests, it's the default permission of the ViewSets
+type AllowAny struct{}
+
+func (p AllowAny) HasPermission(*gin.Context, ActionID) bool {
+	return true
+}
+
+func (p AllowAny) HasObjectPermission(*gin.Context, ActionID, models.InternalValue) bool {
+	return true
+}
+
+// IsAuthenticated allows only the requests of the authenticated users
+type IsAuthenticated struct{}
+
+func (p IsAuthenticated) HasPermission(ctx *gin.Context, _ ActionID) bool {
+	return authentication.IsAuthenticated(ctx)
+}
+
+func (p IsAuthenticated) HasObjectPermission(*gin.Context, ActionID, models.InternalValue) bool {
+	return true
+}
+
+// IsAuthenticatedOrReadOnly allows the safe methods (GET, HEAD, OPTIONS) for everyone and other methods only
+// for the authenticated users
+type IsAuthenticatedOrReadOnly struct{}
+
+func (p IsAuthenticatedOrReadOnly) HasPermission(ctx *gin.Context, _ ActionID) bool {
+	return isSafeMethod(ctx) || authentication.IsAuthenticated(ctx)
+}
+
+func (p IsAuthenticatedOrReadOnly) HasObjectPermission(*gin.Context, ActionID, models.InternalValue) bool {
+	return true
+}
+
+func isSafeMethod(ctx *gin.Context) bool {
+	switch ctx.Request.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	}
+	return false
+}
+
+type andPermission struct {
+	permissions []Permission
+}
+
+func (p andPermission) HasPermission(ctx *gin.Context, action ActionID) bool {
+	for _, permission := range p.permissions {
+		if !permission.HasPermission(ctx, action) {
+			return false
+		}
+	}
+	return true
+}
+
+func (p andPermission) HasObjectPermission(ctx *gin.Context, action ActionID, intVal models.InternalValue) bool {
+	for _, permission := range p.permissions {
+		if !permission.HasObjectPermission(ctx, action, intVal) {
+			return false
+		}
+	}
+	return true
+}
+
+// And allows the request only if all the permissions allow it
+func And(permissions ...Permission) Permission {
+	return andPermission{permissions: permissions}
+}
+
+type orPermission struct {
+	permissions []Permission
+}
+
+func (p orPermission) HasPermission(ctx *gin.Context, action ActionID) bool {
+	for _, permission := range p.permissions {
+		if permission.HasPermission(ctx, action) {
+			return true
+		}
+	}
+	return false
+}
+
+// HasObjectPermission uses only the permissions that allowed the request in HasPermission, so the object
+// check of a permission that denied the request can't grant the access
+func (p orPermission) HasObjectPermission(ctx *gin.Context, action ActionID, intVal models.InternalValue) bool {
+	for _, permission := range p.permissions {
+		if permission.HasPermission(ctx, action) && permission.HasObjectPermission(ctx, action, intVal) {
+			return true
+		}
+	}
+	return false
+}
+
+// Or allows the request if any of the permissions allows it
+func Or(permissions ...Permission) Permission {
+	return orPermission{permissions: permissions}
+}
+
+type notPermission struct {
+	permission Permission
+}
+
+func (p notPermission) HasPermission(ctx *gin.Context, action ActionID) bool {
+	return !p.permission.HasPermission(ctx, action)
+}
+
+// HasObjectPermission always allows the access, like in DRF. Most permissions allow every object, so negating
+// the object check would deny every action operating on a single instance.
+func (p notPermission) HasObjectPermission(*gin.Context, ActionID, models.InternalValue) bool {
+	return true
+}
+
+// Not negates the permission, only HasPermission is negated
+func Not(permission Permission) Permission {
+	return notPermission{permission: permission}
+}
+
+// Prefixes of the model permission codenames
+const (
+	PermissionView   = "view"
+	PermissionAdd    = "add"
+	PermissionChange = "change"
+	PermissionDelete = "delete"
+)
+
+// ModelPermissions allows the action only if the authenticated user has the Django-style model permission
+// matching it: `view_<model>` for List and Retrieve, `add_<model>` for Create, `change_<model>` for Update and
+// PartialUpdate and `delete_<model>` for Destroy. The model name is lowercased, the same way as in the ID
+// param of the ViewSet, so the ViewSet of `Product` requires for example `view_product`. Extra actions are
+// mapped using the HTTP method. The permissions are checked using authentication.HasPermission.
+type ModelPermissions[Model any] struct{}
+
+func (p ModelPermissions[Model]) HasPermission(ctx *gin.Context, action ActionID) bool {
+	prefix := modelPermissionPrefix(ctx, action)
+	if prefix == "" {
+		return false
+	}
+	user, userErr := authentication.CurrentUser(ctx)
+	if userErr != nil || !user.IsAuthenticated() {
+		return false
+	}
+	return authentication.HasPermission(user, permissionCodename(prefix, lowercaseModelName[Model]()))
+}
+
+func (p ModelPermissions[Model]) HasObjectPermission(*gin.Context, ActionID, models.InternalValue) bool {
+	return true
+}
+
+func modelPermissionPrefix(ctx *gin.Context, action ActionID) string {
+	switch action {
+	case ActionList, ActionRetrieve:
+		return PermissionView
+	case ActionCreate:
+		return PermissionAdd
+	case ActionUpdate, ActionPartialUpdate:
+		return PermissionChange
+	case ActionDestroy:
+		return PermissionDelete
+	}
+	switch ctx.Request.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return PermissionView
+	case http.MethodPost:
+		return PermissionAdd
+	case http.MethodPut, http.MethodPatch:
+		return PermissionChange
+	case http.MethodDelete:
+		return PermissionDelete
+	}
+	return ""
+}
+
+func permissionCodename(prefix, modelName string) string {
+	return prefix + "_" + strings.ToLower(modelName)
+}
+
+// ModelPermissionCodenames returns the codenames of all the model permissions of the model with the given
+// name, for example `view_product`, `add_product`, `change_product` and `delete_product`
+func ModelPermissionCodenames(modelName string) []string {
+	codenames := []string{}
+	for _, prefix := range []string{PermissionView, PermissionAdd, PermissionChange, PermissionDelete} {
+		codenames = append(codenames, permissionCodename(prefix, modelName))
+	}
+	return codenames
+}
+
+type ctxPermission struct {
+	action     ActionID
+	permission Permission
+}
+
+// PermissionRequired wraps the handler, so it's called only if the permission allows the action. The
+// permission is stored in the context, so the handler can check the object permissions using
+// CheckObjectPermissions.
+func PermissionRequired(action ActionID, permission Permission, handler gin.HandlerFunc) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		ctx.Set("views:permission", ctxPermission{action: action, permission: permission})
+		if !permission.HasPermission(ctx, action) {
+			WriteError(ctx, permissionDeniedError(ctx))
+			return
+		}
+		handler(ctx)
+	}
+}
+
+// CheckObjectPermissions returns ErrorNotAuthenticated or ErrorPermissionDenied if the permission set using
+// PermissionRequired does not allow the action on the model instance. Custom handlers operating on a single
+// instance should call it before modifying it.
+func CheckObjectPermissions(ctx *gin.Context, intVal models.InternalValue) error {
+	anyVal, ok := ctx.Get("views:permission")
+	if !ok {
+		return nil
+	}
+	checked := anyVal.(ctxPermission)
+	if !checked.permission.HasObjectPermission(ctx, checked.action, intVal) {
+		return permissionDeniedError(ctx)
+	}
+	return nil
+}
+
+func permissionDeniedError(ctx *gin.Context) error {
+	if !authentication.IsAuthenticated(ctx) {
+		return ErrorNotAuthenticated
+	}
+	return ErrorPermissionDenied
+}
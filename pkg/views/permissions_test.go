@@ -0,0 +1,395 @@
+package views
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/glothriel/grf/pkg/authentication"
+	"github.com/glothriel/grf/pkg/models"
+	"github.com/glothriel/grf/pkg/queries"
+	"github.com/stretchr/testify/assert"
+)
+
+type allowPermission bool
+
+func (p allowPermission) HasPermission(*gin.Context, ActionID) bool {
+	return bool(p)
+}
+
+func (p allowPermission) HasObjectPermission(*gin.Context, ActionID, models.InternalValue) bool {
+	return bool(p)
+}
+
+// notSecretPermission denies access to the instances named `Secret`
+type notSecretPermission struct{}
+
+func (p notSecretPermission) HasPermission(*gin.Context, ActionID) bool {
+	return true
+}
+
+func (p notSecretPermission) HasObjectPermission(_ *gin.Context, _ ActionID, intVal models.InternalValue) bool {
+	return intVal["name"] != "Secret"
+}
+
+func authenticatedAs(name string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if name != "" {
+			authentication.CtxSetUser(ctx, &authentication.SimpleUser{Identifier: name, Name: name})
+		}
+	}
+}
+
+func permissionsRouter(user string, configure func(*ViewSet[anotherMockModel])) *gin.Engine {
+	viewset := NewModelViewSet[anotherMockModel]("/mocks", queries.InMemory[anotherMockModel](
+		anotherMockModel{Price: 1.0, Name: "Canned Beans"},
+		anotherMockModel{Price: 2.0, Name: "Secret"},
+	))
+	configure(viewset)
+	_, r := gin.CreateTestContext(httptest.NewRecorder())
+	r.Use(authenticatedAs(user))
+	viewset.Register(r)
+	return r
+}
+
+func TestViewSetPermissions(t *testing.T) {
+	tests := []struct {
+		name      string
+		user      string
+		configure func(*ViewSet[anotherMockModel])
+		params    quickReqParams
+		status    int
+	}{
+		{
+			name:      "allow any by default",
+			configure: func(v *ViewSet[anotherMockModel]) {},
+			params:    caseCreate.params,
+			status:    201,
+		},
+		{
+			name:      "is authenticated without user",
+			configure: func(v *ViewSet[anotherMockModel]) { v.WithPermissions(IsAuthenticated{}) },
+			params:    caseList.params,
+			status:    401,
+		},
+		{
+			name:      "is authenticated with user",
+			user:      "alice",
+			configure: func(v *ViewSet[anotherMockModel]) { v.WithPermissions(IsAuthenticated{}) },
+			params:    caseList.params,
+			status:    200,
+		},
+		{
+			name:      "read only allows reads",
+			configure: func(v *ViewSet[anotherMockModel]) { v.WithPermissions(IsAuthenticatedOrReadOnly{}) },
+			params:    caseRetrieve.params,
+			status:    200,
+		},
+		{
+			name:      "read only denies writes",
+			configure: func(v *ViewSet[anotherMockModel]) { v.WithPermissions(IsAuthenticatedOrReadOnly{}) },
+			params:    caseCreate.params,
+			status:    401,
+		},
+		{
+			name:      "authenticated user denied",
+			user:      "alice",
+			configure: func(v *ViewSet[anotherMockModel]) { v.WithPermissions(allowPermission(false)) },
+			params:    caseList.params,
+			status:    403,
+		},
+		{
+			name: "action permission overrides viewset permission",
+			configure: func(v *ViewSet[anotherMockModel]) {
+				v.WithPermissions(IsAuthenticated{}).WithActionPermissions(AllowAny{}, ActionList, ActionRetrieve)
+			},
+			params: caseList.params,
+			status: 200,
+		},
+		{
+			name: "other actions keep viewset permission",
+			configure: func(v *ViewSet[anotherMockModel]) {
+				v.WithPermissions(IsAuthenticated{}).WithActionPermissions(AllowAny{}, ActionList, ActionRetrieve)
+			},
+			params: caseDestroy.params,
+			status: 401,
+		},
+		{
+			name: "extra actions",
+			user: "alice",
+			configure: func(v *ViewSet[anotherMockModel]) {
+				v.WithExtraAction(
+					NewExtraAction("GET", "/custom", ListModelViewSetFunc[anotherMockModel]), nameOnlySerializer, false,
+				).WithActionPermissions(allowPermission(false), ActionExtra)
+			},
+			params: quickReqParams{method: "GET", path: "/mocks/custom", body: noBody},
+			status: 403,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// given
+			r := permissionsRouter(tt.user, tt.configure)
+
+			// when
+			w := quickReq(r, tt.params)
+
+			// then
+			assert.Equal(t, tt.status, w.Code)
+		})
+	}
+}
+
+func TestViewSetObjectPermissions(t *testing.T) {
+	tests := []struct {
+		name   string
+		params quickReqParams
+		status int
+	}{
+		{"retrieve allowed", quickReqParams{method: "GET", path: "/mocks/1", body: noBody}, 200},
+		{"retrieve denied", quickReqParams{method: "GET", path: "/mocks/2", body: noBody}, 403},
+		{"update denied", quickReqParams{method: "PUT", path: "/mocks/2", body: strBody(`{"price": 5,"name": "Public"}`)}, 403},
+		{"partial update denied", quickReqParams{method: "PATCH", path: "/mocks/2", body: strBody(`{"name": "Public"}`)}, 403},
+		{"destroy denied", quickReqParams{method: "DELETE", path: "/mocks/2", body: noBody}, 403},
+		{"destroy allowed", quickReqParams{method: "DELETE", path: "/mocks/1", body: noBody}, 204},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// given
+			r := permissionsRouter("alice", func(v *ViewSet[anotherMockModel]) {
+				v.WithPermissions(And(IsAuthenticated{}, notSecretPermission{}))
+			})
+
+			// when
+			w := quickReq(r, tt.params)
+			list := quickReq(r, caseList.params)
+
+			// then
+			assert.Equal(t, tt.status, w.Code)
+			if tt.status == 403 {
+				assert.Contains(t, list.Body.String(), `{"id":2,"name":"Secret","price":2}`)
+			}
+		})
+	}
+}
+
+func TestNotPermissionAllowsObjectActions(t *testing.T) {
+	// given
+	r := permissionsRouter("", func(v *ViewSet[anotherMockModel]) {
+		v.WithPermissions(Not(IsAuthenticated{}))
+	})
+
+	// when
+	retrieve := quickReq(r, quickReqParams{method: "GET", path: "/mocks/1", body: noBody})
+	destroy := quickReq(r, quickReqParams{method: "DELETE", path: "/mocks/1", body: noBody})
+
+	// then
+	assert.Equal(t, 200, retrieve.Code)
+	assert.Equal(t, 204, destroy.Code)
+}
+
+func TestComposedPermissions(t *testing.T) {
+	allow, deny := allowPermission(true), allowPermission(false)
+	tests := []struct {
+		name       string
+		permission Permission
+		want       bool
+		wantObject bool
+	}{
+		{"and allows", And(allow, allow), true, true},
+		{"and denies", And(allow, deny), false, false},
+		{"or allows", Or(deny, allow), true, true},
+		{"or denies", Or(deny, deny), false, false},
+		{"or ignores object permission of denying permission", Or(deny, Not(notSecretPermission{})), false, false},
+		{"not", Not(deny), true, true},
+		{"not only negates the permission check", Not(notSecretPermission{}), false, true},
+		{"object check of and", And(allow, notSecretPermission{}), true, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// given
+			ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+
+			// when
+			has := tt.permission.HasPermission(ctx, ActionRetrieve)
+			hasObject := tt.permission.HasObjectPermission(ctx, ActionRetrieve, models.InternalValue{"name": "Secret"})
+
+			// then
+			assert.Equal(t, tt.want, has)
+			assert.Equal(t, tt.wantObject, hasObject)
+		})
+	}
+}
+
+func TestViewSetAuthentication(t *testing.T) {
+	tests := []struct {
+		name          string
+		headers       map[string]string
+		status        int
+		wantChallenge string
+	}{
+		{"valid token", map[string]string{"Authorization": "Bearer valid"}, 200, ""},
+		{"invalid token", map[string]string{"Authorization": "Bearer invalid"}, 401, "Bearer"},
+		{"no credentials", map[string]string{}, 401, "Bearer"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// given
+			viewset := NewModelViewSet[anotherMockModel]("/mocks", queries.InMemory[anotherMockModel]()).
+				WithAuthentication(authentication.NewTokenAuthentication(
+					func(_ *gin.Context, token string) (authentication.User, error) {
+						if token == "valid" {
+							return &authentication.SimpleUser{Name: "alice"}, nil
+						}
+						return nil, nil
+					},
+				)).
+				WithPermissions(IsAuthenticated{})
+			_, r := gin.CreateTestContext(httptest.NewRecorder())
+			viewset.Register(r)
+			req := httptest.NewRequest("GET", "/mocks", nil)
+			for k, v := range tt.headers {
+				req.Header.Set(k, v)
+			}
+
+			// when
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			// then
+			assert.Equal(t, tt.status, w.Code)
+			assert.Equal(t, tt.wantChallenge, w.Header().Get("WWW-Authenticate"))
+		})
+	}
+}
+
+func TestViewSetAPIKeyAuthenticationChallenge(t *testing.T) {
+	// given
+	viewset := NewModelViewSet[anotherMockModel]("/mocks", queries.InMemory[anotherMockModel]()).
+		WithAuthentication(authentication.NewAPIKeyAuthentication(
+			"X-API-Key",
+			func(_ *gin.Context, _ string) (authentication.User, error) {
+				return nil, nil
+			},
+		)).
+		WithPermissions(IsAuthenticated{})
+	_, r := gin.CreateTestContext(httptest.NewRecorder())
+	viewset.Register(r)
+
+	// when
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/mocks", nil))
+
+	// then
+	assert.Equal(t, 401, w.Code)
+	assert.Equal(t, `APIKey header="X-API-Key"`, w.Header().Get("WWW-Authenticate"))
+}
+
+func TestModelPermissions(t *testing.T) {
+	tests := []struct {
+		name        string
+		permissions []string
+		params      quickReqParams
+		status      int
+	}{
+		{"list requires view", []string{"view_anothermockmodel"}, caseList.params, 200},
+		{"retrieve requires view", []string{"view_anothermockmodel"}, caseRetrieve.params, 200},
+		{"create requires add", []string{"view_anothermockmodel"}, caseCreate.params, 403},
+		{"create with add", []string{"add_anothermockmodel"}, caseCreate.params, 201},
+		{"update with change", []string{"change_anothermockmodel"}, quickReqParams{method: "PUT", path: "/mocks/1", body: strBody(`{"price": 5,"name": "Beans"}`)}, 200},
+		{"partial update with change", []string{"change_anothermockmodel"}, quickReqParams{method: "PATCH", path: "/mocks/1", body: strBody(`{"name": "Beans"}`)}, 200},
+		{"destroy requires delete", []string{"change_anothermockmodel"}, caseDestroy.params, 403},
+		{"destroy with delete", []string{"delete_anothermockmodel"}, caseDestroy.params, 204},
+		{"permission of other model", []string{"view_product"}, caseList.params, 403},
+		{"extra action mapped by method", []string{"view_anothermockmodel"}, quickReqParams{method: "GET", path: "/mocks/custom", body: noBody}, 200},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// given
+			viewset := NewModelViewSet[anotherMockModel]("/mocks", queries.InMemory[anotherMockModel](
+				anotherMockModel{Price: 1.0, Name: "Canned Beans"},
+			)).WithExtraAction(
+				NewExtraAction("GET", "/custom", ListModelViewSetFunc[anotherMockModel]), nameOnlySerializer, false,
+			).WithPermissions(ModelPermissions[anotherMockModel]{})
+			_, r := gin.CreateTestContext(httptest.NewRecorder())
+			r.Use(func(ctx *gin.Context) {
+				authentication.CtxSetUser(ctx, &authentication.SimpleUser{Name: "alice", PermissionNames: tt.permissions})
+			})
+			viewset.Register(r)
+
+			// when
+			w := quickReq(r, tt.params)
+
+			// then
+			assert.Equal(t, tt.status, w.Code)
+		})
+	}
+}
+
+func TestModelPermissionsRequireAuthentication(t *testing.T) {
+	// given
+	r := permissionsRouter("", func(v *ViewSet[anotherMockModel]) {
+		v.WithPermissions(ModelPermissions[anotherMockModel]{})
+	})
+
+	// when
+	w := quickReq(r, caseList.params)
+
+	// then
+	assert.Equal(t, 401, w.Code)
+}
+
+type ownedMockModel struct {
+	ID      uint   `json:"id"`
+	Name    string `json:"name"`
+	OwnerID string `json:"owner_id"`
+}
+
+func TestViewSetWithOwnerScope(t *testing.T) {
+	tests := []struct {
+		name     string
+		params   quickReqParams
+		status   int
+		wantBody string
+	}{
+		{"list shows own instances", caseList.params, 200, `[{"id":1,"name":"Mine","owner_id":"alice"}]`},
+		{"retrieve own instance", quickReqParams{method: "GET", path: "/mocks/1", body: noBody}, 200, ""},
+		{"retrieve other instance", quickReqParams{method: "GET", path: "/mocks/2", body: noBody}, 404, ""},
+		{"update other instance", quickReqParams{method: "PUT", path: "/mocks/2", body: strBody(`{"name": "Stolen", "owner_id": "alice"}`)}, 404, ""},
+		{"partial update other instance", quickReqParams{method: "PATCH", path: "/mocks/2", body: strBody(`{"name": "Stolen"}`)}, 404, ""},
+		{"destroy other instance", quickReqParams{method: "DELETE", path: "/mocks/2", body: noBody}, 404, ""},
+		{
+			"update can't change the owner",
+			quickReqParams{method: "PUT", path: "/mocks/1", body: strBody(`{"name": "Given", "owner_id": "bob"}`)},
+			200,
+			`{"id":1,"name":"Given","owner_id":"alice"}`,
+		},
+		{
+			"create sets the owner",
+			quickReqParams{method: "POST", path: "/mocks", body: strBody(`{"name": "New", "owner_id": "bob"}`)},
+			201,
+			`{"id":3,"name":"New","owner_id":"alice"}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// given
+			viewset := NewModelViewSet[ownedMockModel]("/mocks", queries.InMemory[ownedMockModel](
+				ownedMockModel{Name: "Mine", OwnerID: "alice"},
+				ownedMockModel{Name: "Theirs", OwnerID: "bob"},
+			).WithOwnerField("owner_id"))
+			_, r := gin.CreateTestContext(httptest.NewRecorder())
+			r.Use(authenticatedAs("alice"))
+			viewset.Register(r)
+
+			// when
+			w := quickReq(r, tt.params)
+
+			// then
+			assert.Equal(t, tt.status, w.Code)
+			if tt.wantBody != "" {
+				assert.JSONEq(t, tt.wantBody, w.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,100 @@
+package views
+
+import (
+	"sync"
+
+	"github.com/glothriel/grf/pkg/queries/common"
+	"github.com/glothriel/grf/pkg/serializers"
+)
+
+// ActionDescription describes a single endpoint exposed by a ViewSet
+type ActionDescription struct {
+	ID ActionID
+	// Name is the name of the action, for example `list` or `partial_update`
+	Name   string
+	Method string
+	// Path is the full gin path of the action, including the path params, for example `/products/:product_id`
+	Path       string
+	IsDetail   bool
+	Serializer serializers.Serializer
+	// Authenticated is true if the requests are authenticated, so invalid credentials are rejected with
+	// 401 Unauthorized
+	Authenticated bool
+	// Permission is the permission checked before handling the requests, AllowAny by default
+	Permission Permission
+}
+
+// ViewSetDescription is a non-generic summary of a registered ViewSet, that can be used to
+// generate API documentation
+type ViewSetDescription struct {
+	Path       string
+	DetailPath string
+	// IDParam is the name of the path param holding the ID of the entity on detail routes
+	IDParam   string
+	ModelName string
+	// FieldTypes maps JSON names of the model fields to their Go types, see detectors.FieldTypes
+	FieldTypes map[string]string
+	// FieldTags maps JSON names of the model fields to their parsed `grf` tags, see detectors.FieldTags
+	FieldTags map[string]map[string]string
+	// PaginationEnvelope describes the object wrapping the results of the List action, nil if they are
+	// returned as a plain array
+	PaginationEnvelope *common.PaginationEnvelope
+	// DefaultSerializer is the serializer set using ViewSet.WithSerializer or passed to NewViewSet
+	DefaultSerializer serializers.Serializer
+	Actions           []ActionDescription
+}
+
+// Describer is implemented by ViewSets, it allows the Registry to hold ViewSets of different models
+type Describer interface {
+	Describe() *ViewSetDescription
+}
+
+// Registry keeps track of all the registered ViewSets
+type Registry struct {
+	lock     sync.Mutex
+	viewSets []Describer
+}
+
+// Add appends a ViewSet to the registry, adding the same ViewSet again (eg. when it's registered on multiple
+// routers) has no effect
+func (r *Registry) Add(d Describer) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	for _, viewSet := range r.viewSets {
+		if viewSet == d {
+			return
+		}
+	}
+	r.viewSets = append(r.viewSets, d)
+}
+
+// ViewSets returns descriptions of all the ViewSets in the order they were registered
+func (r *Registry) ViewSets() []*ViewSetDescription {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	ret := make([]*ViewSetDescription, 0, len(r.viewSets))
+	for _, viewSet := range r.viewSets {
+		ret = append(ret, viewSet.Describe())
+	}
+	return ret
+}
+
+// PermissionCodenames returns the model permission codenames (see ModelPermissions) of all the registered
+// ViewSets, without duplicates, so they can be used to seed the permission tables
+func (r *Registry) PermissionCodenames() []string {
+	codenames := []string{}
+	seen := map[string]bool{}
+	for _, viewSet := range r.ViewSets() {
+		for _, codename := range ModelPermissionCodenames(viewSet.ModelName) {
+			if !seen[codename] {
+				seen[codename] = true
+				codenames = append(codenames, codename)
+			}
+		}
+	}
+	return codenames
+}
+
+func NewRegistry() *Registry {
+	return &Registry{viewSets: []Describer{}}
+}
@@ -10,12 +10,19 @@ import (
 
 func RetrieveModelViewSetFunc[Model any](idf IDFunc, qd queries.Driver[Model], serializer serializers.Serializer) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
-		qd.Filter().Apply(ctx)
+		if filterErr := qd.Filter().Apply(ctx); filterErr != nil {
+			WriteError(ctx, filterErr)
+			return
+		}
 		internalValue, retrieveErr := qd.CRUD().Retrieve(ctx, idf(ctx))
 		if retrieveErr != nil {
 			WriteError(ctx, retrieveErr)
 			return
 		}
+		if permissionErr := CheckObjectPermissions(ctx, internalValue); permissionErr != nil {
+			WriteError(ctx, permissionErr)
+			return
+		}
 		formattedElement, toRawErr := serializer.ToRepresentation(internalValue, ctx)
 		if toRawErr != nil {
 			WriteError(ctx, toRawErr)
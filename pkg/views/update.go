@@ -10,13 +10,28 @@ import (
 	"github.com/glothriel/grf/pkg/models"
 	"github.com/glothriel/grf/pkg/queries"
 	"github.com/glothriel/grf/pkg/serializers"
+	"github.com/glothriel/grf/pkg/types"
 	"github.com/sirupsen/logrus"
 )
 
+// UpdateModelViewSetFunc is a gin handler function that fully replaces a model instance (PUT). All the
+// writable fields have to be present in the payload.
 func UpdateModelViewSetFunc[Model any](idf IDFunc, qd queries.Driver[Model], serializer serializers.Serializer) gin.HandlerFunc {
+	return updateModelViewSetFunc(serializers.OperationUpdate, idf, qd, serializer)
+}
+
+// PartialUpdateModelViewSetFunc is a gin handler function that partially updates a model instance (PATCH).
+// Only the fields present in the payload are changed.
+func PartialUpdateModelViewSetFunc[Model any](idf IDFunc, qd queries.Driver[Model], serializer serializers.Serializer) gin.HandlerFunc {
+	return updateModelViewSetFunc(serializers.OperationPartialUpdate, idf, qd, serializer)
+}
+
+func updateModelViewSetFunc[Model any](
+	op serializers.Operation, idf IDFunc, qd queries.Driver[Model], serializer serializers.Serializer,
+) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
-		var parsedBody map[string]any
-		if parseErr := ctx.ShouldBindJSON(&parsedBody); parseErr != nil {
+		parsedBody, parseErr := serializers.ParseBody(ctx)
+		if parseErr != nil {
 			WriteError(ctx, parseErr)
 			return
 		}
@@ -27,10 +42,8 @@ func UpdateModelViewSetFunc[Model any](idf IDFunc, qd queries.Driver[Model], ser
 			return
 		}
 
-		effectiveSerializer := serializer
-		incomingIntVal, fromRawErr := effectiveSerializer.ToInternalValue(updates, ctx)
-		if fromRawErr != nil {
-			WriteError(ctx, fromRawErr)
+		if filterErr := qd.Filter().Apply(ctx); filterErr != nil {
+			WriteError(ctx, filterErr)
 			return
 		}
 		oldIntVal, oldErr := qd.CRUD().Retrieve(ctx, idf(ctx))
@@ -38,6 +51,20 @@ func UpdateModelViewSetFunc[Model any](idf IDFunc, qd queries.Driver[Model], ser
 			WriteError(ctx, oldErr)
 			return
 		}
+		if permissionErr := CheckObjectPermissions(ctx, oldIntVal); permissionErr != nil {
+			WriteError(ctx, permissionErr)
+			return
+		}
+
+		effectiveSerializer := serializer
+		serializers.CtxSetOperation(ctx, op)
+		serializers.CtxSetInstance(ctx, oldIntVal)
+		ctxSetUniquenessChecker(ctx, qd)
+		incomingIntVal, fromRawErr := effectiveSerializer.ToInternalValue(updates, ctx)
+		if fromRawErr != nil {
+			WriteError(ctx, fromRawErr)
+			return
+		}
 		newIntVal := models.InternalValue{}
 		for k, v := range oldIntVal {
 			newIntVal[k] = v
@@ -83,7 +110,7 @@ func enrichBodyWithID[Model any](ctx *gin.Context, isNumeric bool, idf IDFunc, b
 		return nil, convertErr
 	}
 	if idFromBody, ok := b["id"]; ok {
-		if idFromBody != idFromUrlFloat {
+		if idFromBodyFloat, isNumber := types.AsFloat64(idFromBody); !isNumber || idFromBodyFloat != idFromUrlFloat {
 			return nil, &serializers.ValidationError{
 				FieldErrors: map[string][]string{
 					"id": {"id in body does not match id in url"},
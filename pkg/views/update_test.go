@@ -10,6 +10,7 @@ import (
 	"testing"
 
 	"github.com/gin-gonic/gin"
+	"github.com/glothriel/grf/pkg/models"
 	"github.com/glothriel/grf/pkg/queries"
 	"github.com/glothriel/grf/pkg/serializers"
 	"github.com/stretchr/testify/assert"
@@ -24,9 +25,15 @@ var updateModelViewTests = []struct {
 }{
 	{
 		name:               "Valid",
+		json:               `{"foo": "baz", "bar": "baz"}`,
+		wantStatus:         http.StatusOK,
+		wantBodyJSONEquals: map[string]any{"id": float64(2), "foo": "baz", "bar": "baz"},
+	},
+	{
+		name:               "Missing nullable field",
 		json:               `{"foo": "baz"}`,
 		wantStatus:         http.StatusOK,
-		wantBodyJSONEquals: map[string]any{"id": float64(2), "foo": "baz"},
+		wantBodyJSONEquals: map[string]any{"id": float64(2), "foo": "baz", "bar": nil},
 	},
 	{
 		name:       "Invalid JSON",
@@ -34,30 +41,64 @@ var updateModelViewTests = []struct {
 		wantStatus: 400,
 		wantBodyJSONEquals: map[string]any{
 			"errors": map[string]any{
-				"all": []any{"could not parse request body"},
+				serializers.NonFieldErrors: []any{"could not parse request body"},
+			},
+		},
+	},
+	{
+		name:       "Missing Fields",
+		json:       `{}`,
+		wantStatus: 400,
+		wantBodyJSONEquals: map[string]any{
+			"errors": map[string]any{
+				"foo": []any{"Field `foo` is required"},
 			},
 		},
 	},
 	{
-		name:               "Invalid Fields",
-		json:               `{"bar": "baz"}`,
-		wantStatus:         200,
-		wantBodyJSONEquals: map[string]any{"id": float64(2), "foo": "bar"},
+		name:       "Superfluous Fields",
+		json:       `{"foo": "baz", "baz": "baz"}`,
+		wantStatus: 400,
+		wantBodyJSONEquals: map[string]any{
+			"errors": map[string]any{
+				"baz": []any{"Field `baz` is not accepted by this endpoint"},
+			},
+		},
 	},
+	{
+		name:               "Matching id in body",
+		json:               `{"id": 2, "foo": "baz", "bar": "baz"}`,
+		wantStatus:         http.StatusOK,
+		wantBodyJSONEquals: map[string]any{"id": float64(2), "foo": "baz", "bar": "baz"},
+	},
+	{
+		name:               "Zero value",
+		json:               `{"foo": "", "bar": ""}`,
+		wantStatus:         http.StatusOK,
+		wantBodyJSONEquals: map[string]any{"id": float64(2), "foo": "", "bar": ""},
+	},
+}
+
+type mockModelWithNullableField struct {
+	ID  uint    `json:"id"`
+	Foo string  `json:"foo"`
+	Bar *string `json:"bar"`
 }
 
 func TestUpdateModelView(t *testing.T) {
 	for _, tt := range updateModelViewTests {
 		t.Run(tt.name, func(t *testing.T) {
 			// given
-			qd := queries.InMemory(MockModel{Foo: "bar"})
-			serializer := serializers.NewModelSerializer[MockModel]()
+			qd := queries.InMemory(mockModelWithNullableField{Foo: "bar"})
+			serializer := serializers.NewModelSerializer[mockModelWithNullableField]()
 
 			_, r := gin.CreateTestContext(httptest.NewRecorder())
 
 			r.POST("/foos/", CreateModelViewSetFunc(IDFromQueryParamIDFunc, qd, serializer))
 			r.PUT("/foos/:id", UpdateModelViewSetFunc(IDFromQueryParamIDFunc, qd, serializer))
-			createRequest, createRequestErr := http.NewRequest(http.MethodPost, "/foos/", bytes.NewBufferString(`{"foo": "bar"}`))
+			createRequest, createRequestErr := http.NewRequest(
+				http.MethodPost, "/foos/", bytes.NewBufferString(`{"foo": "bar", "bar": "bar"}`),
+			)
 			updateRequest, updateRequestErr := http.NewRequest(http.MethodPut, "/foos/2", bytes.NewBufferString(tt.json))
 			updateRecorder := httptest.NewRecorder()
 
@@ -84,6 +125,141 @@ func TestUpdateModelView(t *testing.T) {
 	}
 }
 
+var partialUpdateModelViewTests = []struct {
+	name               string
+	json               string
+	wantStatus         int
+	wantBodyJSONEquals any
+}{
+	{
+		name:               "Valid",
+		json:               `{"foo": "baz"}`,
+		wantStatus:         http.StatusOK,
+		wantBodyJSONEquals: map[string]any{"id": float64(2), "foo": "baz", "bar": "bar"},
+	},
+	{
+		name:               "Empty payload",
+		json:               `{}`,
+		wantStatus:         http.StatusOK,
+		wantBodyJSONEquals: map[string]any{"id": float64(2), "foo": "bar", "bar": "bar"},
+	},
+	{
+		name:       "Invalid type",
+		json:       `{"bar": 1}`,
+		wantStatus: 400,
+	},
+}
+
+type mockModelWithTwoFields struct {
+	ID  uint   `json:"id"`
+	Foo string `json:"foo"`
+	Bar string `json:"bar"`
+}
+
+func TestPartialUpdateModelView(t *testing.T) {
+	for _, tt := range partialUpdateModelViewTests {
+		t.Run(tt.name, func(t *testing.T) {
+			// given
+			qd := queries.InMemory(mockModelWithTwoFields{Foo: "bar", Bar: "bar"})
+			serializer := serializers.NewModelSerializer[mockModelWithTwoFields]()
+
+			_, r := gin.CreateTestContext(httptest.NewRecorder())
+
+			r.POST("/foos/", CreateModelViewSetFunc(IDFromQueryParamIDFunc, qd, serializer))
+			r.PATCH("/foos/:id", PartialUpdateModelViewSetFunc(IDFromQueryParamIDFunc, qd, serializer))
+			createRequest, createRequestErr := http.NewRequest(
+				http.MethodPost, "/foos/", bytes.NewBufferString(`{"foo": "bar", "bar": "bar"}`),
+			)
+			updateRequest, updateRequestErr := http.NewRequest(http.MethodPatch, "/foos/2", bytes.NewBufferString(tt.json))
+			updateRecorder := httptest.NewRecorder()
+
+			// when
+			r.ServeHTTP(httptest.NewRecorder(), createRequest)
+			r.ServeHTTP(updateRecorder, updateRequest)
+
+			// then
+			assert.NoError(t, createRequestErr)
+			assert.NoError(t, updateRequestErr)
+			assert.Equal(t, tt.wantStatus, updateRecorder.Code)
+			if tt.wantBodyJSONEquals != nil {
+				var responseJSON any
+				responseJSONErr := json.Unmarshal(updateRecorder.Body.Bytes(), &responseJSON)
+				assert.NoError(t, responseJSONErr)
+				assert.Equal(t, tt.wantBodyJSONEquals, responseJSON)
+			}
+		})
+	}
+}
+
+type mockEvent struct {
+	ID       uint   `json:"id"`
+	StartsOn string `json:"starts_on"`
+	EndsOn   string `json:"ends_on"`
+}
+
+func TestPartialUpdateValidatesAgainstStoredInstance(t *testing.T) {
+	// given
+	var operations []serializers.Operation
+	endsAfterStart := serializers.NewContextValidator(
+		func(intVal models.InternalValue, validationCtx serializers.ValidationContext) error {
+			operations = append(operations, validationCtx.Operation)
+			merged := models.InternalValue{}
+			for k, v := range validationCtx.Instance {
+				merged[k] = v
+			}
+			for k, v := range intVal {
+				merged[k] = v
+			}
+			if merged["ends_on"].(string) < merged["starts_on"].(string) {
+				return serializers.NewValidationError().Add("ends_on", "must be after starts_on")
+			}
+			return nil
+		},
+	)
+	serializer := serializers.NewValidatingSerializer[mockEvent](
+		serializers.NewModelSerializer[mockEvent](),
+	).AddContextValidator(endsAfterStart)
+	qd := queries.InMemory[mockEvent]()
+	_, r := gin.CreateTestContext(httptest.NewRecorder())
+	r.POST("/events", CreateModelViewSetFunc(IDFromQueryParamIDFunc, qd, serializer))
+	r.PATCH("/events/:id", PartialUpdateModelViewSetFunc(IDFromQueryParamIDFunc, qd, serializer))
+
+	// when
+	createResp := quickReq(r, quickReqParams{"POST", "/events", strBody(`{"starts_on": "2024-05-01", "ends_on": "2024-05-03"}`)})
+	invalidResp := quickReq(r, quickReqParams{"PATCH", "/events/1", strBody(`{"ends_on": "2024-04-30"}`)})
+	validResp := quickReq(r, quickReqParams{"PATCH", "/events/1", strBody(`{"ends_on": "2024-05-10"}`)})
+
+	// then
+	assert.Equal(t, http.StatusCreated, createResp.Code)
+	assert.Equal(t, http.StatusBadRequest, invalidResp.Code)
+	assert.JSONEq(t, `{"errors": {"ends_on": ["must be after starts_on"]}}`, invalidResp.Body.String())
+	assert.Equal(t, http.StatusOK, validResp.Code)
+	assert.Equal(t, []serializers.Operation{
+		serializers.OperationCreate, serializers.OperationPartialUpdate, serializers.OperationPartialUpdate,
+	}, operations)
+}
+
+func TestPartialUpdateSkipsRequiredRulesOfFieldsNotSent(t *testing.T) {
+	// given
+	serializer := serializers.NewValidatingSerializer[mockModelWithTwoFields](
+		serializers.NewModelSerializer[mockModelWithTwoFields](),
+		serializers.NewGoPlaygroundValidator[mockModelWithTwoFields](map[string]any{"foo": "required", "bar": "required"}),
+	)
+	qd := queries.InMemory(mockModelWithTwoFields{Foo: "foo", Bar: "bar"})
+	_, r := gin.CreateTestContext(httptest.NewRecorder())
+	r.PATCH("/foos/:id", PartialUpdateModelViewSetFunc(IDFromQueryParamIDFunc, qd, serializer))
+
+	// when
+	validResp := quickReq(r, quickReqParams{"PATCH", "/foos/1", strBody(`{"bar": "baz"}`)})
+	invalidResp := quickReq(r, quickReqParams{"PATCH", "/foos/1", strBody(`{"bar": ""}`)})
+
+	// then
+	assert.Equal(t, http.StatusOK, validResp.Code, validResp.Body.String())
+	assert.JSONEq(t, `{"id": 1, "foo": "foo", "bar": "baz"}`, validResp.Body.String())
+	assert.Equal(t, http.StatusBadRequest, invalidResp.Code)
+	assert.Contains(t, invalidResp.Body.String(), `"bar"`)
+}
+
 var enrichBodyWithIDTests = []struct {
 	name           string
 	isNumeric      bool
@@ -20,7 +20,8 @@ type View struct {
 	deleteHandler func(*gin.Context)
 	patchHandler  func(*gin.Context)
 	extraRoutes   []*ViewRoute
-	authenticator authentication.Authentication
+	// authentications are tried in order, the first one that authenticates the request wins
+	authentications []authentication.Authentication
 
 	middleware []gin.HandlerFunc
 }
@@ -62,8 +63,25 @@ func (v *View) AddMiddleware(m ...gin.HandlerFunc) *View {
 	return v
 }
 
+// WithAuthentication sets the chain of authentications run before the handlers. If none of them
+// authenticates the request, the anonymous user is used.
+func (v *View) WithAuthentication(authentications ...authentication.Authentication) *View {
+	v.authentications = authentications
+	return v
+}
+
+func (v *View) authenticationMiddleware(ctx *gin.Context) {
+	if authErr := authentication.Authenticate(ctx, v.authentications); authErr != nil {
+		WriteError(ctx, authErr)
+		ctx.Abort()
+		return
+	}
+	ctx.Next()
+}
+
 func (v *View) Register(r gin.IRouter) {
-	rg := r.Group(v.path, v.middleware...)
+	middleware := append([]gin.HandlerFunc{}, v.middleware...)
+	rg := r.Group(v.path, append(middleware, v.authenticationMiddleware)...)
 	if v.getHandler != nil {
 		rg.GET("", v.getHandler)
 	}
@@ -93,9 +111,10 @@ func NewView[Model any](path string, queryDriver queries.Driver[Model]) *View {
 		putHandler:    nil,
 		deleteHandler: nil,
 		patchHandler:  nil,
-		authenticator: &authentication.AnonymousUserAuthentication{},
 		extraRoutes:   []*ViewRoute{},
 
+		authentications: []authentication.Authentication{},
+
 		middleware: queryDriver.Middleware(),
 	}
 }
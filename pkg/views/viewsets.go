@@ -2,27 +2,52 @@ package views
 
 import (
 	"fmt"
+	"net/http"
 	"path"
 	"reflect"
 	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/glothriel/grf/pkg/authentication"
+	"github.com/glothriel/grf/pkg/detectors"
 	"github.com/glothriel/grf/pkg/queries"
+	"github.com/glothriel/grf/pkg/queries/common"
 	"github.com/glothriel/grf/pkg/queries/crud"
 	"github.com/glothriel/grf/pkg/serializers"
 	"github.com/glothriel/grf/pkg/types"
 )
 
 const (
-	ActionCreate = iota
+	ActionCreate ActionID = iota
 	ActionUpdate
 	ActionDestroy
 	ActionList
 	ActionRetrieve
+	ActionPartialUpdate
+	// ActionExtra identifies actions added using ViewSet.WithExtraAction
+	ActionExtra
 )
 
 type ActionID int
 
+var actionNames = map[ActionID]string{
+	ActionCreate:        "create",
+	ActionUpdate:        "update",
+	ActionDestroy:       "destroy",
+	ActionList:          "list",
+	ActionRetrieve:      "retrieve",
+	ActionPartialUpdate: "partial_update",
+	ActionExtra:         "extra",
+}
+
+// String returns the name of the action, for example `partial_update`
+func (a ActionID) String() string {
+	if name, ok := actionNames[a]; ok {
+		return name
+	}
+	return fmt.Sprintf("action_%d", int(a))
+}
+
 type ViewSet[Model any] struct {
 	Path        string
 	IDFunc      IDFunc
@@ -34,10 +59,22 @@ type ViewSet[Model any] struct {
 	UpdateAction   *ViewSetAction[Model]
 	DestroyAction  *ViewSetAction[Model]
 
+	PartialUpdateAction *ViewSetAction[Model]
+
 	DefaultSerializer serializers.Serializer
 
 	ListCreateView            *View
 	RetrieveUpdateDestroyView *View
+
+	idParamName       string
+	registry          *Registry
+	extraActions      []ActionDescription
+	permission        Permission
+	actionPermissions map[ActionID]Permission
+	// modelSerializer is the serializer created by NewModelViewSet, replaced by WithFieldTypeMapper
+	modelSerializer serializers.Serializer
+	// fieldTypeMapper is set using WithFieldTypeMapper and passed to the QueryDriver in the request context
+	fieldTypeMapper *types.FieldTypeMapper
 }
 
 func (v *ViewSet[Model]) WithExtraAction(
@@ -49,38 +86,157 @@ func (v *ViewSet[Model]) WithExtraAction(
 	if isDetail {
 		view = v.RetrieveUpdateDestroyView
 	}
+	v.extraActions = append(v.extraActions, ActionDescription{
+		ID:         ActionExtra,
+		Name:       ActionExtra.String(),
+		Method:     action.Method,
+		Path:       path.Join(view.path, action.RelativePath),
+		IsDetail:   isDetail,
+		Serializer: serializer,
+	})
 
 	view.WithRoute(&ViewRoute{
 		Method:       action.Method,
 		RelativePath: action.RelativePath,
-		Handler:      action.Handler(v.IDFunc, v.QueryDriver, serializer),
+		Handler:      v.permissionRequired(ActionExtra, action.Handler(v.IDFunc, v.QueryDriver, serializer)),
 	})
 	return v
 }
 
+// WithPermissions sets the permission checked for all the actions, unless overridden using
+// WithActionPermissions. AllowAny is used by default.
+func (v *ViewSet[Model]) WithPermissions(permission Permission) *ViewSet[Model] {
+	v.permission = permission
+	return v
+}
+
+// WithAuthentication sets the chain of authentications for all the actions of the ViewSet
+func (v *ViewSet[Model]) WithAuthentication(authentications ...authentication.Authentication) *ViewSet[Model] {
+	v.ListCreateView.WithAuthentication(authentications...)
+	v.RetrieveUpdateDestroyView.WithAuthentication(authentications...)
+	return v
+}
+
+// WithActionPermissions overrides the permission for the given actions, ActionExtra sets it for all the
+// extra actions
+func (v *ViewSet[Model]) WithActionPermissions(permission Permission, actions ...ActionID) *ViewSet[Model] {
+	for _, action := range actions {
+		v.actionPermissions[action] = permission
+	}
+	return v
+}
+
+// permissionRequired resolves the permission when handling the request, so it can be set after
+// the actions are added
+func (v *ViewSet[Model]) permissionRequired(action ActionID, handler gin.HandlerFunc) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if v.fieldTypeMapper != nil {
+			serializers.CtxSetFieldTypeMapper(ctx, v.fieldTypeMapper)
+		}
+		PermissionRequired(action, v.actionPermission(action), handler)(ctx)
+	}
+}
+
+func (v *ViewSet[Model]) actionPermission(action ActionID) Permission {
+	if permission, ok := v.actionPermissions[action]; ok {
+		return permission
+	}
+	return v.permission
+}
+
 func (v *ViewSet[Model]) Register(r gin.IRouter) {
 	if v.ListAction != nil {
-		v.ListCreateView.Get(v.ListAction.ViewSetHandlerFactoryFunc(v.IDFunc, v.QueryDriver, v.ListAction.Serializer))
+		v.ListCreateView.Get(v.actionHandler(ActionList, v.ListAction))
 	}
 	if v.CreateAction != nil {
-		v.ListCreateView.Post(v.CreateAction.ViewSetHandlerFactoryFunc(v.IDFunc, v.QueryDriver, v.CreateAction.Serializer))
+		v.ListCreateView.Post(v.actionHandler(ActionCreate, v.CreateAction))
 	}
 	if v.RetrieveAction != nil {
-		v.RetrieveUpdateDestroyView.Get(v.RetrieveAction.ViewSetHandlerFactoryFunc(v.IDFunc, v.QueryDriver, v.RetrieveAction.Serializer))
+		v.RetrieveUpdateDestroyView.Get(v.actionHandler(ActionRetrieve, v.RetrieveAction))
 	}
 	if v.UpdateAction != nil {
-		v.RetrieveUpdateDestroyView.Put(v.UpdateAction.ViewSetHandlerFactoryFunc(v.IDFunc, v.QueryDriver, v.UpdateAction.Serializer))
+		v.RetrieveUpdateDestroyView.Put(v.actionHandler(ActionUpdate, v.UpdateAction))
+	}
+	if v.PartialUpdateAction != nil {
+		v.RetrieveUpdateDestroyView.Patch(v.actionHandler(ActionPartialUpdate, v.PartialUpdateAction))
 	}
 	if v.DestroyAction != nil {
-		v.RetrieveUpdateDestroyView.Delete(v.DestroyAction.ViewSetHandlerFactoryFunc(v.IDFunc, v.QueryDriver, v.DestroyAction.Serializer))
+		v.RetrieveUpdateDestroyView.Delete(v.actionHandler(ActionDestroy, v.DestroyAction))
 	}
 	v.ListCreateView.Register(r)
 	v.RetrieveUpdateDestroyView.Register(r)
+	if v.registry != nil {
+		v.registry.Add(v)
+	}
+}
+
+func (v *ViewSet[Model]) actionHandler(id ActionID, action *ViewSetAction[Model]) gin.HandlerFunc {
+	return v.permissionRequired(id, action.ViewSetHandlerFactoryFunc(v.IDFunc, v.QueryDriver, action.Serializer))
+}
+
+// WithRegistry sets the registry the ViewSet is added to when registered, for example to generate the OpenAPI
+// document. By default the ViewSet is not added to any registry.
+func (v *ViewSet[Model]) WithRegistry(registry *Registry) *ViewSet[Model] {
+	v.registry = registry
+	return v
+}
+
+// Describe returns a summary of the ViewSet, listing the enabled actions
+func (v *ViewSet[Model]) Describe() *ViewSetDescription {
+	var m Model
+	description := &ViewSetDescription{
+		Path:              v.ListCreateView.path,
+		DetailPath:        v.RetrieveUpdateDestroyView.path,
+		IDParam:           v.idParamName,
+		ModelName:         reflect.TypeOf(m).Name(),
+		FieldTypes:        detectors.FieldTypes[Model](),
+		FieldTags:         detectors.FieldTags[Model](),
+		DefaultSerializer: v.DefaultSerializer,
+		Actions:           []ActionDescription{},
+	}
+	if describer, ok := v.QueryDriver.Pagination().(common.EnvelopeDescriber); ok {
+		description.PaginationEnvelope = describer.Envelope()
+	}
+	for _, action := range []struct {
+		id       ActionID
+		method   string
+		isDetail bool
+		action   *ViewSetAction[Model]
+	}{
+		{ActionList, http.MethodGet, false, v.ListAction},
+		{ActionCreate, http.MethodPost, false, v.CreateAction},
+		{ActionRetrieve, http.MethodGet, true, v.RetrieveAction},
+		{ActionUpdate, http.MethodPut, true, v.UpdateAction},
+		{ActionPartialUpdate, http.MethodPatch, true, v.PartialUpdateAction},
+		{ActionDestroy, http.MethodDelete, true, v.DestroyAction},
+	} {
+		if action.action == nil {
+			continue
+		}
+		description.Actions = append(description.Actions, ActionDescription{
+			ID:         action.id,
+			Name:       action.id.String(),
+			Method:     action.method,
+			Path:       action.action.View.path,
+			IsDetail:   action.isDetail,
+			Serializer: action.action.Serializer,
+		})
+	}
+	description.Actions = append(description.Actions, v.extraActions...)
+	for i := range description.Actions {
+		view := v.ListCreateView
+		if description.Actions[i].IsDetail {
+			view = v.RetrieveUpdateDestroyView
+		}
+		description.Actions[i].Authenticated = len(view.authentications) > 0
+		description.Actions[i].Permission = v.actionPermission(description.Actions[i].ID)
+	}
+	return description
 }
 
 func (v *ViewSet[Model]) WithSerializer(serializer serializers.Serializer) *ViewSet[Model] {
 	v.DefaultSerializer = serializer
-	return v.WithListSerializer(serializer).WithRetrieveSerializer(serializer).WithUpdateSerializer(serializer).WithCreateSerializer(serializer).WithDestroySerializer(serializer)
+	return v.WithListSerializer(serializer).WithRetrieveSerializer(serializer).WithUpdateSerializer(serializer).WithPartialUpdateSerializer(serializer).WithCreateSerializer(serializer).WithDestroySerializer(serializer)
 }
 
 func (v *ViewSet[Model]) WithListSerializer(serializer serializers.Serializer) *ViewSet[Model] {
@@ -104,6 +260,13 @@ func (v *ViewSet[Model]) WithUpdateSerializer(serializer serializers.Serializer)
 	return v
 }
 
+func (v *ViewSet[Model]) WithPartialUpdateSerializer(serializer serializers.Serializer) *ViewSet[Model] {
+	if v.PartialUpdateAction != nil {
+		v.PartialUpdateAction.Serializer = serializer
+	}
+	return v
+}
+
 func (v *ViewSet[Model]) WithCreateSerializer(serializer serializers.Serializer) *ViewSet[Model] {
 	if v.CreateAction != nil {
 		v.CreateAction.Serializer = serializer
@@ -118,7 +281,28 @@ func (v *ViewSet[Model]) WithDestroySerializer(serializer serializers.Serializer
 	return v
 }
 
+// WithFieldTypeMapper makes the ViewSet convert the fields using the mapper, instead of the default one. The
+// ModelSerializer created by NewModelViewSet is replaced with one using the mapper, in all the actions still
+// using it. Serializers set using WithSerializer and similar methods are not modified, create them using
+// serializers.UsingFieldTypeMapper. The mapper is also used by the filters and the owner fields of the
+// QueryDriver, see serializers.CtxFieldTypeMapper.
 func (v *ViewSet[Model]) WithFieldTypeMapper(fieldTypeMapper *types.FieldTypeMapper) *ViewSet[Model] {
+	v.fieldTypeMapper = fieldTypeMapper
+	if v.modelSerializer == nil {
+		return v
+	}
+	replaced := v.modelSerializer
+	v.modelSerializer = serializers.NewModelSerializer[Model](serializers.UsingFieldTypeMapper(fieldTypeMapper))
+	if v.DefaultSerializer == replaced {
+		v.DefaultSerializer = v.modelSerializer
+	}
+	for _, action := range []*ViewSetAction[Model]{
+		v.ListAction, v.CreateAction, v.RetrieveAction, v.UpdateAction, v.PartialUpdateAction, v.DestroyAction,
+	} {
+		if action != nil && action.Serializer == replaced {
+			action.Serializer = v.modelSerializer
+		}
+	}
 	return v
 }
 
@@ -182,6 +366,21 @@ func (v *ViewSet[Model]) WithUpdate(handlerFactoryFunc ViewSetHandlerFactoryFunc
 	return v
 }
 
+func (v *ViewSet[Model]) WithPartialUpdate(handlerFactoryFunc ViewSetHandlerFactoryFunc[Model]) *ViewSet[Model] {
+	if v.PartialUpdateAction == nil {
+		v.PartialUpdateAction = &ViewSetAction[Model]{
+			Path:                      v.Path,
+			View:                      v.RetrieveUpdateDestroyView,
+			ViewSetHandlerFactoryFunc: handlerFactoryFunc,
+			Serializer:                v.DefaultSerializer,
+			QueryDriver:               v.QueryDriver,
+		}
+	} else {
+		v.PartialUpdateAction.ViewSetHandlerFactoryFunc = handlerFactoryFunc
+	}
+	return v
+}
+
 func (v *ViewSet[Model]) WithDestroy(handlerFactoryFunc ViewSetHandlerFactoryFunc[Model]) *ViewSet[Model] {
 	if v.DestroyAction == nil {
 		v.DestroyAction = &ViewSetAction[Model]{
@@ -206,6 +405,7 @@ func (v *ViewSet[Model]) WithActions(actions ...ActionID) *ViewSet[Model] {
 	}{
 		{ActionCreate, v.WithCreate, &v.CreateAction, CreateModelViewSetFunc[Model]},
 		{ActionUpdate, v.WithUpdate, &v.UpdateAction, UpdateModelViewSetFunc[Model]},
+		{ActionPartialUpdate, v.WithPartialUpdate, &v.PartialUpdateAction, PartialUpdateModelViewSetFunc[Model]},
 		{ActionDestroy, v.WithDestroy, &v.DestroyAction, DestroyModelViewSetFunc[Model]},
 		{ActionList, v.WithList, &v.ListAction, ListModelViewSetFunc[Model]},
 		{ActionRetrieve, v.WithRetrieve, &v.RetrieveAction, RetrieveModelViewSetFunc[Model]},
@@ -228,22 +428,34 @@ func (v *ViewSet[Model]) WithActions(actions ...ActionID) *ViewSet[Model] {
 }
 
 func (v *ViewSet[Model]) OnCreate(modFunc func(c crud.CreateQueryFunc) crud.CreateQueryFunc) *ViewSet[Model] {
-	v.QueryDriver.CRUD().WithCreate(modFunc(v.QueryDriver.CRUD().Create))
+	v.QueryDriver.CRUD().WrapCreate(modFunc)
 	return v
 }
 
 func (v *ViewSet[Model]) OnUpdate(modFunc func(u crud.UpdateQueryFunc) crud.UpdateQueryFunc) *ViewSet[Model] {
-	v.QueryDriver.CRUD().WithUpdate(modFunc(v.QueryDriver.CRUD().Update))
+	v.QueryDriver.CRUD().WrapUpdate(modFunc)
 	return v
 }
 
 func (v *ViewSet[Model]) OnDestroy(modFunc func(d crud.DestroyQueryFunc) crud.DestroyQueryFunc) *ViewSet[Model] {
-	v.QueryDriver.CRUD().WithDestroy(modFunc(v.QueryDriver.CRUD().Destroy))
+	v.QueryDriver.CRUD().WrapDestroy(modFunc)
 	return v
 }
 
+// lowercaseModelName returns the lowercased name of the model type, used in the ID param name and permission
+// codenames, for example `product`
+func lowercaseModelName[Model any]() string {
+	var m Model
+	return strings.ToLower(reflect.TypeOf(m).Name())
+}
+
 func NewModelViewSet[Model any](path string, queryDriver queries.Driver[Model]) *ViewSet[Model] {
-	return NewViewSet(path, queryDriver, serializers.NewModelSerializer[Model]()).WithActions(ActionCreate, ActionUpdate, ActionDestroy, ActionList, ActionRetrieve)
+	modelSerializer := serializers.NewModelSerializer[Model]()
+	viewSet := NewViewSet(path, queryDriver, modelSerializer).WithActions(
+		ActionCreate, ActionUpdate, ActionPartialUpdate, ActionDestroy, ActionList, ActionRetrieve,
+	)
+	viewSet.modelSerializer = modelSerializer
+	return viewSet
 }
 
 func NewViewSet[Model any](
@@ -258,15 +470,17 @@ func NewViewSet[Model any](
 	// I'm generating the ID param name based on the router path, so you can register (eg) "photos" viewset on
 	// "/products/:product_id/photos" and Retrieve action for photos would be on "/products/:product_id/photos/:photo_id".
 	// Ugly, but nothing panics and there is just no other way to go around this Gin limitation.
-	var m Model
 	// IDParamFunc shoud be interface with Name() and Value() so that you can easily get the ID from the code.
-	idParamName := strings.ToLower(fmt.Sprintf("%s_id", reflect.TypeOf(m).Name()))
+	idParamName := fmt.Sprintf("%s_id", lowercaseModelName[Model]())
 	retrieveUpdateDestroyPath := path.Join(routerPath, fmt.Sprintf(":%s", idParamName))
 
 	return &ViewSet[Model]{
 		Path:                      routerPath,
 		QueryDriver:               queryDriver,
 		IDFunc:                    IDFromPathParam(idParamName),
+		idParamName:               idParamName,
+		permission:                AllowAny{},
+		actionPermissions:         map[ActionID]Permission{},
 		DefaultSerializer:         defaultSerializer,
 		ListCreateView:            NewView(routerPath, queryDriver),
 		RetrieveUpdateDestroyView: NewView(retrieveUpdateDestroyPath, queryDriver),
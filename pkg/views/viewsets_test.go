@@ -2,14 +2,19 @@ package views
 
 import (
 	"bytes"
+	"fmt"
 	"io"
+	"math"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
 	"github.com/gin-gonic/gin"
+	"github.com/glothriel/grf/pkg/fields"
 	"github.com/glothriel/grf/pkg/queries"
+	"github.com/glothriel/grf/pkg/queries/filters"
 	"github.com/glothriel/grf/pkg/serializers"
+	"github.com/glothriel/grf/pkg/types"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -86,6 +91,15 @@ var caseUpdate viewsetTestCase = viewsetTestCase{
 	},
 }
 
+var casePartialUpdate viewsetTestCase = viewsetTestCase{
+	name: "PATCH request to single item endpoint",
+	params: quickReqParams{
+		method: "PATCH",
+		path:   "/mocks/1",
+		body:   strBody(`{"price": 3.0}`),
+	},
+}
+
 var caseDestroy viewsetTestCase = viewsetTestCase{
 	name: "DELETE request to single item endpoint",
 	params: quickReqParams{
@@ -105,6 +119,7 @@ func TestEmptyViewsetRespondsWithMethodNotFound(t *testing.T) {
 		caseRetrieve,
 		caseList,
 		caseUpdate,
+		casePartialUpdate,
 		caseDestroy,
 	} {
 		t.Run(tc.name, func(t *testing.T) {
@@ -122,6 +137,7 @@ func TestViewsetWhenOnlyListActionRegisteredAllOthersReturn404(t *testing.T) {
 		caseCreate,
 		caseRetrieve,
 		caseUpdate,
+		casePartialUpdate,
 		caseDestroy,
 	} {
 		t.Run(tt.name, func(t *testing.T) {
@@ -148,6 +164,7 @@ func checkAllActionsNoErrors(t *testing.T, v *ViewSet[anotherMockModel]) {
 		caseCreate,
 		caseRetrieve,
 		caseUpdate,
+		casePartialUpdate,
 		caseDestroy,
 	} {
 		t.Run(tt.name, func(t *testing.T) {
@@ -165,11 +182,30 @@ func TestNewModelViewset(t *testing.T) {
 func TestNewViewSetAllActions(t *testing.T) {
 	checkAllActionsNoErrors(
 		t, NewViewSet[anotherMockModel]("/mocks", queries.InMemory[anotherMockModel](), serializers.NewModelSerializer[anotherMockModel]()).WithActions(
-			ActionList, ActionCreate, ActionRetrieve, ActionUpdate, ActionDestroy,
+			ActionList, ActionCreate, ActionRetrieve, ActionUpdate, ActionPartialUpdate, ActionDestroy,
 		),
 	)
 }
 
+func TestUpdateRequiresAllWritableFields(t *testing.T) {
+	// given
+	viewset := NewModelViewSet[anotherMockModel]("/mocks", queries.InMemory[anotherMockModel](
+		anotherMockModel{Price: 1.0, Name: "Canned Beans"},
+	))
+	_, r := gin.CreateTestContext(httptest.NewRecorder())
+	viewset.Register(r)
+
+	// when
+	put := quickReq(r, quickReqParams{method: "PUT", path: "/mocks/1", body: strBody(`{"price": 2.0}`)})
+	patch := quickReq(r, quickReqParams{method: "PATCH", path: "/mocks/1", body: strBody(`{"price": 2.0}`)})
+
+	// then
+	assert.Equal(t, 400, put.Code)
+	assert.Equal(t, `{"errors":{"name":["Field `+"`name`"+` is required"]}}`, put.Body.String())
+	assert.Equal(t, 200, patch.Code)
+	assert.Equal(t, `{"id":1,"name":"Canned Beans","price":2}`, patch.Body.String())
+}
+
 func TestCustomSerializer(t *testing.T) {
 	// given
 	viewset := NewModelViewSet[anotherMockModel]("/mocks", queries.InMemory[anotherMockModel](
@@ -368,3 +404,187 @@ func TestOverridingActionsWithCustomView(t *testing.T) {
 		})
 	}
 }
+
+func TestViewSetRegisterAddsItToRegistry(t *testing.T) {
+	// given
+	registry := NewRegistry()
+	viewset := NewModelViewSet[anotherMockModel]("/mocks", queries.InMemory[anotherMockModel]()).WithActions(
+		ActionList, ActionRetrieve,
+	).WithExtraAction(
+		NewExtraAction[anotherMockModel]("POST", "/publish", CreateModelViewSetFunc[anotherMockModel]),
+		nameOnlySerializer,
+		true,
+	).WithRegistry(registry)
+	_, r := gin.CreateTestContext(httptest.NewRecorder())
+
+	// when
+	viewset.Register(r)
+
+	// then
+	descriptions := registry.ViewSets()
+	assert.Len(t, descriptions, 1)
+	assert.Equal(t, "/mocks", descriptions[0].Path)
+	assert.Equal(t, "/mocks/:anothermockmodel_id", descriptions[0].DetailPath)
+	assert.Equal(t, "anothermockmodel_id", descriptions[0].IDParam)
+	assert.Equal(t, "anotherMockModel", descriptions[0].ModelName)
+	assert.Equal(t, map[string]string{"id": "uint", "price": "float64", "name": "string"}, descriptions[0].FieldTypes)
+	assert.Len(t, descriptions[0].Actions, 3)
+	assert.Equal(t, ActionDescription{
+		ID: ActionList, Name: "list", Method: "GET", Path: "/mocks", Serializer: viewset.ListAction.Serializer,
+		Permission: AllowAny{},
+	}, descriptions[0].Actions[0])
+	assert.Equal(t, "retrieve", descriptions[0].Actions[1].Name)
+	assert.True(t, descriptions[0].Actions[1].IsDetail)
+	assert.Equal(t, ActionDescription{
+		ID:         ActionExtra,
+		Name:       "extra",
+		Method:     "POST",
+		Path:       "/mocks/:anothermockmodel_id/publish",
+		IsDetail:   true,
+		Serializer: nameOnlySerializer,
+		Permission: AllowAny{},
+	}, descriptions[0].Actions[2])
+}
+
+func TestViewSetIsAddedToRegistryOnce(t *testing.T) {
+	// given
+	registry := NewRegistry()
+	unregistered := NewModelViewSet[anotherMockModel]("/v1/mocks", queries.InMemory[anotherMockModel]())
+	viewset := NewModelViewSet[anotherMockModel]("/v2/mocks", queries.InMemory[anotherMockModel]()).WithRegistry(registry)
+	_, r1 := gin.CreateTestContext(httptest.NewRecorder())
+	_, r2 := gin.CreateTestContext(httptest.NewRecorder())
+
+	// when
+	unregistered.Register(r1)
+	viewset.Register(r1)
+	viewset.Register(r2)
+
+	// then
+	descriptions := registry.ViewSets()
+	assert.Len(t, descriptions, 1)
+	assert.Equal(t, "/v2/mocks", descriptions[0].Path)
+}
+
+type Product struct {
+	ID uint `json:"id"`
+}
+
+func TestRegistryPermissionCodenames(t *testing.T) {
+	// given
+	registry := NewRegistry()
+	_, r := gin.CreateTestContext(httptest.NewRecorder())
+	NewModelViewSet[anotherMockModel]("/mocks", queries.InMemory[anotherMockModel]()).WithRegistry(registry).Register(r)
+	NewModelViewSet[Product]("/products", queries.InMemory[Product]()).WithRegistry(registry).Register(r)
+	NewModelViewSet[Product]("/v2/products", queries.InMemory[Product]()).WithRegistry(registry).Register(r)
+
+	// when
+	codenames := registry.PermissionCodenames()
+
+	// then
+	assert.Equal(t, []string{
+		"view_anothermockmodel", "add_anothermockmodel", "change_anothermockmodel", "delete_anothermockmodel",
+		"view_product", "add_product", "change_product", "delete_product",
+	}, codenames)
+}
+
+func TestListWithInvalidFilterReturnsBadRequest(t *testing.T) {
+	// given
+	viewset := NewModelViewSet[anotherMockModel]("/mocks", queries.InMemory[anotherMockModel](
+		anotherMockModel{Price: 1.0, Name: "Canned Beans"},
+		anotherMockModel{Price: 3.0, Name: "Canned Peas"},
+	).WithFilterSet(
+		filters.NewFilterSet[anotherMockModel]().WithField("price", filters.Gte),
+	))
+	_, r := gin.CreateTestContext(httptest.NewRecorder())
+	viewset.Register(r)
+
+	// when
+	valid := quickReq(r, quickReqParams{method: "GET", path: "/mocks?price__gte=2", body: noBody})
+	invalid := quickReq(r, quickReqParams{method: "GET", path: "/mocks?price__gte=cheap", body: noBody})
+
+	// then
+	assert.Equal(t, 200, valid.Code)
+	assert.Equal(t, `[{"id":2,"name":"Canned Peas","price":3}]`, valid.Body.String())
+	assert.Equal(t, 400, invalid.Code)
+	assert.Contains(t, invalid.Body.String(), `"price__gte"`)
+}
+
+func TestListWithUnknownOrderingFieldReturnsBadRequest(t *testing.T) {
+	// given
+	viewset := NewModelViewSet[anotherMockModel]("/mocks", queries.InMemory[anotherMockModel](
+		anotherMockModel{Price: 1.0, Name: "Canned Beans"},
+		anotherMockModel{Price: 3.0, Name: "Canned Peas"},
+	).WithOrdering(
+		filters.NewOrdering[anotherMockModel]("price"),
+	))
+	_, r := gin.CreateTestContext(httptest.NewRecorder())
+	viewset.Register(r)
+
+	// when
+	valid := quickReq(r, quickReqParams{method: "GET", path: "/mocks?ordering=-price", body: noBody})
+	invalid := quickReq(r, quickReqParams{method: "GET", path: "/mocks?ordering=name", body: noBody})
+
+	// then
+	assert.Equal(t, 200, valid.Code)
+	assert.Equal(t, `[{"id":2,"name":"Canned Peas","price":3},{"id":1,"name":"Canned Beans","price":1}]`, valid.Body.String())
+	assert.Equal(t, 400, invalid.Code)
+	assert.Equal(t, `{"errors":{"ordering":["Ordering by `+"`name`"+` is not allowed"]}}`, invalid.Body.String())
+}
+
+func TestViewSetsWithDifferentFieldTypeMappers(t *testing.T) {
+	// given
+	centsMapper := types.NewFieldTypeMapper()
+	centsMapper.Register("float64", types.FieldType{
+		InternalToResponse: func(v any) (any, error) {
+			return int(math.Round(v.(float64) * 100)), nil
+		},
+		RequestToInternal: func(v any) (any, error) {
+			cents, ok := types.AsFloat64(v)
+			if !ok {
+				return nil, fmt.Errorf("Expected a number of cents")
+			}
+			return cents / 100, nil
+		},
+	})
+	driver := queries.InMemory[anotherMockModel](anotherMockModel{Price: 1.5, Name: "Canned Beans"}).WithFilterSet(
+		filters.NewFilterSet[anotherMockModel]().WithField("price"),
+	)
+	_, r := gin.CreateTestContext(httptest.NewRecorder())
+	NewModelViewSet[anotherMockModel]("/v1/mocks", driver).Register(r)
+	NewModelViewSet[anotherMockModel]("/v2/mocks", driver).WithFieldTypeMapper(
+		centsMapper,
+	).Register(r)
+	NewModelViewSet[anotherMockModel]("/v3/mocks", driver).WithCreateSerializer(
+		serializers.NewModelSerializer[anotherMockModel](serializers.UsingFieldTypeMapper(centsMapper)).WithField(
+			"name", func(oldField fields.Field) {
+				oldField.WithInternalValueFunc(func(map[string]any, string, *gin.Context) (any, error) {
+					return "Server-side name", nil
+				})
+			},
+		),
+	).WithFieldTypeMapper(
+		centsMapper,
+	).Register(r)
+
+	// when
+	v1 := quickReq(r, quickReqParams{method: "GET", path: "/v1/mocks/1", body: noBody})
+	v2 := quickReq(r, quickReqParams{method: "GET", path: "/v2/mocks/1", body: noBody})
+	filteredV1 := quickReq(r, quickReqParams{method: "GET", path: "/v1/mocks?price=1.5", body: noBody})
+	filteredV2 := quickReq(r, quickReqParams{method: "GET", path: "/v2/mocks?price=150", body: noBody})
+	created := quickReq(r, quickReqParams{
+		method: "POST", path: "/v2/mocks", body: strBody(`{"name": "Canned Peas", "price": 250}`),
+	})
+	createdInV1 := quickReq(r, quickReqParams{method: "GET", path: "/v1/mocks/2", body: noBody})
+	createdInV3 := quickReq(r, quickReqParams{
+		method: "POST", path: "/v3/mocks", body: strBody(`{"name": "Client-side name", "price": 100}`),
+	})
+
+	// then
+	assert.Equal(t, `{"id":1,"name":"Canned Beans","price":1.5}`, v1.Body.String())
+	assert.Equal(t, `{"id":1,"name":"Canned Beans","price":150}`, v2.Body.String())
+	assert.Equal(t, `[{"id":1,"name":"Canned Beans","price":1.5}]`, filteredV1.Body.String())
+	assert.Equal(t, `[{"id":1,"name":"Canned Beans","price":150}]`, filteredV2.Body.String())
+	assert.Equal(t, 201, created.Code, created.Body.String())
+	assert.Equal(t, `{"id":2,"name":"Canned Peas","price":2.5}`, createdInV1.Body.String())
+	assert.Equal(t, `{"id":3,"name":"Server-side name","price":100}`, createdInV3.Body.String())
+}